@@ -0,0 +1,9 @@
+package main
+
+import "booking.com/internal/cli"
+
+// bookmylab is the single binary of the project, run "bookmylab serve" for the
+// api and "bookmylab worker" for a standalone worker
+func main() {
+	cli.Execute()
+}
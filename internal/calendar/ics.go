@@ -0,0 +1,177 @@
+// Package calendar writes RFC 5545 iCalendar documents, the visit invites
+// mailed to both sides of a visit and the per-user subscribable feeds
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Methods of RFC 5546, a feed publishes while invites request and cancel
+const (
+	MethodPublish = "PUBLISH"
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+)
+
+// Event statuses
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+const (
+	ContentType = "text/calendar; charset=utf-8"
+	prodID      = "-//BookMyLab//Visits//EN"
+	timeLayout  = "20060102T150405Z"
+	// lineLimit is the octet limit of a content line before it is folded
+	lineLimit = 75
+)
+
+// Person is an organizer or attendee, reached by email
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Event is a VEVENT, UID stays the same across the updates of an event and
+// Sequence grows with every update so clients keep the latest one
+type Event struct {
+	UID         string
+	Sequence    int32
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+	Organizer   *Person
+	Attendees   []Person
+}
+
+// Calendar is a VCALENDAR, Name and RefreshInterval are only meant for feeds
+type Calendar struct {
+	Method          string
+	Name            string
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// MediaType is the content type of the calendar including its method, mail
+// clients need the method to show an invite
+func (c *Calendar) MediaType() string {
+	if c.Method == "" {
+		return ContentType
+	}
+	return ContentType + "; method=" + c.Method
+}
+
+// Bytes renders the calendar with CRLF line endings and folded lines
+func (c *Calendar) Bytes() []byte {
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + prodID)
+	w.line("CALSCALE:GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD:" + c.Method)
+	}
+	if c.Name != "" {
+		w.line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		interval := duration(c.RefreshInterval)
+		w.line("REFRESH-INTERVAL;VALUE=DURATION:" + interval)
+		w.line("X-PUBLISHED-TTL:" + interval)
+	}
+	for _, event := range c.Events {
+		w.event(event)
+	}
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) event(e Event) {
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + e.UID)
+	w.line(fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+	w.line("DTSTAMP:" + formatTime(e.Stamp))
+	w.line("DTSTART:" + formatTime(e.Start))
+	w.line("DTEND:" + formatTime(e.End))
+	w.line("SUMMARY:" + escapeText(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION:" + escapeText(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION:" + escapeText(e.Location))
+	}
+	if e.Status != "" {
+		w.line("STATUS:" + e.Status)
+	}
+	if e.Organizer != nil {
+		w.line("ORGANIZER" + commonName(e.Organizer.Name) + ":mailto:" + e.Organizer.Email)
+	}
+	for _, attendee := range e.Attendees {
+		w.line("ATTENDEE" + commonName(attendee.Name) + ";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=FALSE:mailto:" + attendee.Email)
+	}
+	w.line("END:VEVENT")
+}
+
+// line writes a content line folded at 75 octets without splitting a utf-8 sequence
+func (w *writer) line(s string) {
+	limit := lineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		// the leading space of a continuation line counts towards its limit
+		limit = lineLimit - 1
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// escapeText escapes a TEXT value, see RFC 5545 section 3.3.11
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// commonName is the CN parameter, quoted values can't hold a double quote
+func commonName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '"' || r < ' ' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		return ""
+	}
+	return `;CN="` + name + `"`
+}
+
+// duration formats d as an RFC 5545 DURATION with minute precision
+func duration(d time.Duration) string {
+	minutes := int64(d.Round(time.Minute) / time.Minute)
+	if minutes%60 == 0 {
+		return fmt.Sprintf("PT%dH", minutes/60)
+	}
+	return fmt.Sprintf("PT%dM", minutes)
+}
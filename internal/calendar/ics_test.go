@@ -0,0 +1,46 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarBytes(t *testing.T) {
+	start := time.Date(2025, time.March, 2, 10, 30, 0, 0, time.FixedZone("IST", 5*3600+1800))
+	cal := &Calendar{Method: MethodRequest, Events: []Event{{
+		UID:         VisitUID(7),
+		Sequence:    2,
+		Stamp:       start,
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Summary:     "Visit: 2BHK, sea view; Juhu",
+		Description: strings.Repeat("देखने का समय ", 10) + "\nline two",
+		Status:      StatusConfirmed,
+		Organizer:   &Person{Name: `Jane "JD" Doe`, Email: "jane@example.com"},
+		Attendees:   []Person{{Name: "John", Email: "john@example.com"}},
+	}}}
+	out := string(cal.Bytes())
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n", "METHOD:REQUEST\r\n", "UID:visit-7@bookmylab.com\r\n", "SEQUENCE:2\r\n",
+		"DTSTART:20250302T050000Z\r\n", "DTEND:20250302T060000Z\r\n",
+		`SUMMARY:Visit: 2BHK\, sea view\; Juhu` + "\r\n",
+		`ORGANIZER;CN="Jane JD Doe":mailto:jane@example.com` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar is missing %q:\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > lineLimit {
+			t.Errorf("line of %d octets is not folded: %q", len(line), line)
+		}
+	}
+	// unfolding restores the escaped description
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, `DESCRIPTION:`+strings.Repeat("देखने का समय ", 10)+`\nline two`) {
+		t.Errorf("description does not survive folding:\n%s", unfolded)
+	}
+}
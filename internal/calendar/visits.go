@@ -0,0 +1,76 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/pkg/constants"
+)
+
+// Visit is a visit with the rows its calendar event is built from, Start is
+// the proposed time while the visit is rescheduled
+type Visit struct {
+	ID       int64
+	Sequence int32
+	Status   string
+	Start    time.Time
+	Duration time.Duration
+	Property *model.Property
+	Partner  *model.User
+	Buyer    *model.User
+}
+
+// VisitUID is the UID of every invite and feed entry of a visit
+func VisitUID(id int64) string {
+	return fmt.Sprintf("visit-%d@bookmylab.com", id)
+}
+
+// VisitEvent builds the event of a visit, the partner organizes it and the
+// buyer attends
+func VisitEvent(visit Visit, stamp time.Time) Event {
+	event := Event{
+		UID:      VisitUID(visit.ID),
+		Sequence: visit.Sequence,
+		Stamp:    stamp,
+		Start:    visit.Start,
+		End:      visit.Start.Add(visit.Duration),
+		Summary:  "Visit: " + visit.Property.Title,
+		Location: joinNonEmpty(", ", visit.Property.Address, visit.Property.City, visit.Property.State),
+		Status:   visitStatus(visit.Status),
+		Organizer: &Person{
+			Name:  fullName(visit.Partner),
+			Email: visit.Partner.Email,
+		},
+		Attendees: []Person{{Name: fullName(visit.Buyer), Email: visit.Buyer.Email}},
+	}
+	event.Description = fmt.Sprintf("Property visit booked on BookMyLab.\nBuyer: %s\nPartner: %s\nStatus: %s",
+		fullName(visit.Buyer), fullName(visit.Partner), visit.Status)
+	return event
+}
+
+func visitStatus(status string) string {
+	switch status {
+	case constants.Accepted, constants.Completed:
+		return StatusConfirmed
+	case constants.Cancelled, constants.Rejected:
+		return StatusCancelled
+	default:
+		return StatusTentative
+	}
+}
+
+func fullName(user *model.User) string {
+	return joinNonEmpty(" ", user.FirstName, user.LastName)
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"booking.com/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// redacted is the flag of config print
+var redacted bool
+
+// --------------------------------------------------
+// configCmd: Inspect the configuration
+// --------------------------------------------------
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration the other commands would run with",
+	Long: `Inspects the configuration merged from the defaults, the config file, the
+env file, the environment and the flags.
+Usage examples:
+  bookmylab config check
+  bookmylab config print --redacted
+  bookmylab config print --config deploy/prod.yaml`,
+}
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Args:  cobra.NoArgs,
+	Short: "Report the settings that are missing or invalid",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := cfg.HttpServer.ValidateFiles(); err != nil {
+			return err
+		}
+		fmt.Println("✅ Configuration is valid")
+		return nil
+	},
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Args:  cobra.NoArgs,
+	Short: "Print the merged configuration as env file lines",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if !redacted {
+			fmt.Fprintln(os.Stderr, "warning: secrets are printed in clear text")
+		}
+		return config.Print(cmd.OutOrStdout(), cfg, redacted)
+	},
+}
+
+func init() {
+	configPrintCmd.Flags().BoolVar(&redacted, "redacted", true, "replace the secrets, --redacted=false prints them")
+	configCmd.AddCommand(configCheckCmd, configPrintCmd)
+	rootCmd.AddCommand(configCmd)
+}
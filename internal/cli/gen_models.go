@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gorm.io/gen"
+)
+
+// --------------------------------------------------
+// genDbModelsCmd: Generate DB models using GORM Gen
+// --------------------------------------------------
+var genDbModelsCmd = &cobra.Command{
+	Use:   "gen-db-models",
+	Short: "Generate GORM models from PostgreSQL schema",
+	Long: `Automatically generates model structs from your PostgreSQL schema
+and stores them inside internal/db/postgresql/dao.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		db, err := connect(cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Println("🧱 Generating DB models...")
+
+		g := gen.NewGenerator(gen.Config{
+			OutPath:           "internal/db/postgresql/dao",
+			Mode:              gen.WithoutContext | gen.WithDefaultQuery,
+			FieldWithIndexTag: true,
+			FieldWithTypeTag:  true,
+			// Uncomment these for more customization:
+			// FieldNullable:     true,
+			// FieldCoverable:    true,
+		})
+
+		g.UseDB(db.DB)
+		allTables := g.GenerateAllTable()
+		g.ApplyBasic(allTables...)
+		g.Execute()
+
+		fmt.Println("✅ Model generation completed successfully!")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(genDbModelsCmd)
+}
@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/migrations"
+	"booking.com/internal/db/migrator"
+	"booking.com/internal/db/postgresql/dao"
+
+	"github.com/spf13/cobra"
+)
+
+// flags of the migrate commands
+var (
+	migrationsDir string
+	dryRun        bool
+	downAll       bool
+)
+
+// --------------------------------------------------
+// migrateCmd: Manage database migrations
+// --------------------------------------------------
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage database migrations",
+	Long: `Manages database migrations using golang-migrate.
+Usage examples:
+  bookmylab migrate status
+  bookmylab migrate create add_reviews
+  bookmylab migrate up
+  bookmylab migrate up 1
+  bookmylab migrate down 1
+  bookmylab migrate down --all
+  bookmylab migrate goto 5 --dry-run
+  bookmylab migrate force 5`,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Args:  cobra.NoArgs,
+	Short: "Show the applied version, the dirty flag and the pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(cmd, func(r *migrator.Migrator) error {
+			status, err := r.Status()
+			if err != nil {
+				return err
+			}
+			version := "none"
+			if status.Version != migrator.NilVersion {
+				version = strconv.Itoa(status.Version)
+			}
+			fmt.Printf("version: %s\ndirty:   %t\n", version, status.Dirty)
+			if len(status.Pending) == 0 {
+				fmt.Println("pending: none")
+				return nil
+			}
+			fmt.Println("pending:")
+			for _, step := range status.Pending {
+				fmt.Println("  " + step.Path)
+			}
+			return nil
+		})
+	},
+}
+
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Create an empty up and down migration named after the current time",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		up, down, err := migrator.Create(cmp.Or(migrationsDir, migrator.DefaultDir), args[0], time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Created %s\n✅ Created %s\n", up, down)
+		return nil
+	},
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up [N]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Apply the next N migrations, all pending ones without N",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(cmd, func(r *migrator.Migrator) error {
+			if len(args) == 0 {
+				return apply(r)(r.PlanTo(latest(r)))
+			}
+			n, err := parseCount(args[0])
+			if err != nil {
+				return err
+			}
+			return apply(r)(r.PlanSteps(n))
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down N",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Roll back the last N migrations, --all rolls back every migration",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if downAll == (len(args) == 1) {
+			return errors.New("down needs either N or --all")
+		}
+		n := 0
+		if !downAll {
+			var err error
+			if n, err = parseCount(args[0]); err != nil {
+				return err
+			}
+		}
+		return withMigrator(cmd, func(r *migrator.Migrator) error {
+			if downAll {
+				return apply(r)(r.PlanTo(migrator.NilVersion))
+			}
+			return apply(r)(r.PlanSteps(-n))
+		})
+	},
+}
+
+var migrateGotoCmd = &cobra.Command{
+	Use:   "goto V",
+	Args:  cobra.ExactArgs(1),
+	Short: "Migrate up or down to version V",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := parseVersion(args[0])
+		if err != nil {
+			return err
+		}
+		return withMigrator(cmd, func(r *migrator.Migrator) error {
+			return apply(r)(r.PlanTo(version))
+		})
+	},
+}
+
+var migrateForceCmd = &cobra.Command{
+	Use:   "force V",
+	Args:  cobra.ExactArgs(1),
+	Short: "Set the version and clear the dirty flag without running any migration",
+	Long: `Sets the version and clears the dirty flag without running any migration,
+meant for a database repaired by hand after a failed migration.
+Use "bookmylab migrate force -- -1" to mark that no migration is applied.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < migrator.NilVersion {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		return withMigrator(cmd, func(r *migrator.Migrator) error {
+			if dryRun {
+				fmt.Printf("-- would force version %d\n", version)
+				return nil
+			}
+			if err := r.Force(version); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Forced version %d\n", version)
+			return nil
+		})
+	},
+}
+
+// apply runs the planned steps, with --dry-run it prints their SQL instead
+func apply(r *migrator.Migrator) func([]migrator.Step, error) error {
+	return func(steps []migrator.Step, err error) error {
+		if err != nil {
+			return err
+		}
+		if len(steps) == 0 {
+			fmt.Println("✅ No change, the database is up to date")
+			return nil
+		}
+		if dryRun {
+			for _, step := range steps {
+				sql, err := fs.ReadFile(r.FS, step.Path)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("-- %s\n%s\n", step.Path, sql)
+			}
+			return nil
+		}
+		fmt.Println("🚀 Running migrations...")
+		if err := r.Run(steps); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		for _, step := range steps {
+			fmt.Printf("✅ %s\n", step.Path)
+		}
+		return nil
+	}
+}
+
+// withMigrator connects to the database and runs fn with a migrator over --path,
+// the migrations built into the binary without it
+func withMigrator(cmd *cobra.Command, fn func(r *migrator.Migrator) error) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	fsys := fs.FS(migrations.FS)
+	if migrationsDir != "" {
+		fsys = os.DirFS(migrationsDir)
+	}
+	r, err := openMigrator(cfg, fsys)
+	if err != nil {
+		return err
+	}
+	return errors.Join(fn(r), r.Close())
+}
+
+// openMigrator binds the migrations of fsys to a connection of its own, closing
+// the migrator closes that connection
+func openMigrator(cfg *config.AppConfig, fsys fs.FS) (*migrator.Migrator, error) {
+	sqlDB, err := dao.OpenPrimary(cfg.PostgresqlDb)
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+	r, err := migrator.New(sqlDB, fsys)
+	if err != nil {
+		return nil, errors.Join(err, sqlDB.Close())
+	}
+	return r, nil
+}
+
+// autoMigrate applies the embedded migrations when MIGRATIONS_AUTO is set. It
+// is a single Up, which reads the version and applies the pending migrations
+// under the database lock, so replicas starting together apply each one once
+func autoMigrate(cfg *config.AppConfig) error {
+	if !cfg.Migrations.Auto {
+		return nil
+	}
+	r, err := openMigrator(cfg, migrations.FS)
+	if err != nil {
+		return err
+	}
+	err = r.Up()
+	if err == nil {
+		var version int
+		if version, _, err = r.Version(); err == nil {
+			log.Printf("database migrated to version %d", version)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("auto migrate: %w", err)
+	}
+	return errors.Join(err, r.Close())
+}
+
+// latest is the version of the newest migration file
+func latest(r *migrator.Migrator) int {
+	if len(r.Migrations) == 0 {
+		return migrator.NilVersion
+	}
+	return int(r.Migrations[len(r.Migrations)-1].Version)
+}
+
+func parseCount(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid count %q, expected a positive number", arg)
+	}
+	return n, nil
+}
+
+func parseVersion(arg string) (int, error) {
+	version, err := strconv.Atoi(arg)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid version %q", arg)
+	}
+	return version, nil
+}
+
+func init() {
+	migrateCmd.PersistentFlags().StringVar(&migrationsDir, "path", "", "directory of the migration files, the embedded migrations when empty and "+migrator.DefaultDir+" for create")
+	migrateCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the SQL that would run without changing the database")
+	migrateDownCmd.Flags().BoolVar(&downAll, "all", false, "roll back every migration")
+	migrateCmd.AddCommand(migrateStatusCmd, migrateCreateCmd, migrateUpCmd, migrateDownCmd, migrateGotoCmd, migrateForceCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
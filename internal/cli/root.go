@@ -0,0 +1,98 @@
+// Package cli is the bookmylab command, it serves the api, runs the worker
+// and holds the database and user tooling in one binary
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// configFile and envFile are the file layers below the environment
+var (
+	configFile string
+	envFile    string
+)
+
+// envFlags maps the flags that override an environment variable to its name
+var envFlags = map[string]string{}
+
+// --------------------------------------------------
+// rootCmd: Base command
+// --------------------------------------------------
+var rootCmd = &cobra.Command{
+	Use:   "bookmylab",
+	Short: "BookMyLab api server, worker and tooling",
+	Long: `BookMyLab serves the api and runs the background worker, and helps developers
+run migrations, seed demo data, manage users and generate database models.
+Configuration comes from the defaults, then the --config yaml or toml file,
+then the env file, then the environment and last the flags. A setting ending
+in _FILE reads the value from that file, like POSTGRESQL_DB_PASSWORD_FILE.`,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "yaml or toml config file, defaults to CONFIG_FILE")
+	rootCmd.PersistentFlags().StringVar(&envFile, "env-file", config.DefaultEnvFile, "env file read before the environment")
+	flags := rootCmd.PersistentFlags()
+	bindEnv(flags, "db-host", "POSTGRESQL_DB_HOST", "database host")
+	bindEnv(flags, "db-port", "POSTGRESQL_DB_PORT", "database port")
+	bindEnv(flags, "db-name", "POSTGRESQL_DB_NAME", "database name")
+	bindEnv(flags, "db-user", "POSTGRESQL_DB_USER_NAME", "database user")
+	bindEnv(flags, "db-schema", "POSTGRESQL_DB_SCHEMA", "database schema")
+	bindEnv(flags, "db-ssl-mode", "POSTGRESQL_DB_SSL_MODE", "database sslmode")
+}
+
+// Execute runs the command line, errors exit with status 1
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// bindEnv registers a string flag that overrides the environment variable env
+func bindEnv(flags *pflag.FlagSet, name, env, usage string) {
+	flags.String(name, "", usage+", overrides "+env)
+	envFlags[name] = env
+}
+
+// bindEnvBool registers a bool flag that overrides the environment variable env
+func bindEnvBool(flags *pflag.FlagSet, name, env, usage string) {
+	flags.Bool(name, false, usage+", overrides "+env)
+	envFlags[name] = env
+}
+
+// loadConfig loads the configuration with the flags of cmd that were set
+func loadConfig(cmd *cobra.Command) (*config.AppConfig, error) {
+	if cmd.Flags().Changed("env-file") {
+		if _, err := os.Stat(envFile); err != nil {
+			return nil, fmt.Errorf("env file: %w", err)
+		}
+	}
+	overrides := map[string]string{}
+	for name, env := range envFlags {
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+			overrides[env] = flag.Value.String()
+		}
+	}
+	cfg, err := config.Load(config.Sources{File: configFile, EnvFile: envFile, Overrides: overrides})
+	if err != nil {
+		return nil, fmt.Errorf("load app configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// connect connects to the database, only the commands that need the
+// database connect
+func connect(cfg *config.AppConfig) (*dao.Cluster, error) {
+	cluster, err := dao.Connect(cfg.PostgresqlDb)
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+	return cluster, nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+// --------------------------------------------------
+// serveCmd: Run the api server
+// --------------------------------------------------
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Args:  cobra.NoArgs,
+	Short: "Run the api server, with the worker inside it unless JOBS_IN_PROCESS=false",
+	Long: `Runs the https api server. The outbox dispatcher, the job runner and the
+schedules run inside it unless JOBS_IN_PROCESS=false, deploy the worker
+command then. With --auto-migrate the pending migrations are applied first.
+Usage examples:
+  bookmylab serve
+  bookmylab serve --addr :8443 --mode release --auto-migrate`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := cfg.HttpServer.ValidateFiles(); err != nil {
+			return err
+		}
+		if err := autoMigrate(cfg); err != nil {
+			return err
+		}
+		db, err := connect(cfg)
+		if err != nil {
+			return err
+		}
+		dao.SetDefault(db.DB)
+		return server.StartHttpTlsServer(cfg, db)
+	},
+}
+
+func init() {
+	flags := serveCmd.Flags()
+	bindEnv(flags, "addr", "HTTP_SERVER_ADDRESS", "address the api listens on")
+	bindEnv(flags, "mode", "HTTP_SERVER_MODE", "gin mode, debug, release or test")
+	bindEnvBool(flags, "jobs-in-process", "JOBS_IN_PROCESS", "run the worker inside the api server")
+	bindEnvBool(flags, "auto-migrate", "MIGRATIONS_AUTO", "apply the pending migrations before starting")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/repo"
+	"booking.com/internal/svcs"
+	"booking.com/internal/utils"
+	"booking.com/internal/validation"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cobra"
+)
+
+// flags of the user commands, password is read from stdin when it is not set
+var (
+	adminReq dto.CreateUser
+	password string
+)
+
+// --------------------------------------------------
+// userCmd: Manage users through the same services as the API
+// --------------------------------------------------
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Create admins and manage roles, passwords and activation of users",
+	Long: `Manages users through the user and auth services, so passwords are salted
+and hashed the same way as in the API. A user is given by username, email or phone.
+Without --password the password is read from the first line of stdin.
+Usage examples:
+  bookmylab user create-admin --first-name Ada --last-name Admin --email ada@example.com --phone +919876543210
+  bookmylab user set-role ada_admin_123 partner
+  echo 'N3w#Passw0rd' | bookmylab user reset-password ada@example.com
+  bookmylab user deactivate +919876543210
+  bookmylab user activate ada_admin_123`,
+}
+
+var userCreateAdminCmd = &cobra.Command{
+	Use:   "create-admin",
+	Args:  cobra.NoArgs,
+	Short: "Create an admin, the way to make the first admin of a deployment",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pw, err := readPassword()
+		if err != nil {
+			return err
+		}
+		adminReq.Password = pw
+		validation.Register()
+		if err := binding.Validator.ValidateStruct(&adminReq); err != nil {
+			return validationError(err)
+		}
+		return withUserSvcs(cmd, func(auth *svcs.AuthSvc, users *svcs.UserSvc) error {
+			admin, err := auth.CreateAdmin(&adminReq, users)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ Created admin %s (%s)\n", admin.Username, admin.Email)
+			return nil
+		})
+	},
+}
+
+var userSetRoleCmd = &cobra.Command{
+	Use:   "set-role <user> <role>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Change the role of a user to user, partner or admin",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withUserSvcs(cmd, func(auth *svcs.AuthSvc, users *svcs.UserSvc) error {
+			user, err := findUser(users, args[0])
+			if err != nil {
+				return err
+			}
+			if err := users.SetRole(user.Username, 0, args[1]); err != nil {
+				return err
+			}
+			fmt.Printf("✅ %s is now %s\n", user.Username, args[1])
+			return nil
+		})
+	},
+}
+
+var userResetPasswordCmd = &cobra.Command{
+	Use:   "reset-password <user>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Set a new password, every session of the user is signed out",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pw, err := readPassword()
+		if err != nil {
+			return err
+		}
+		return withUserSvcs(cmd, func(auth *svcs.AuthSvc, users *svcs.UserSvc) error {
+			user, err := findUser(users, args[0])
+			if err != nil {
+				return err
+			}
+			if err := auth.ResetPassword(user.Username, pw, users); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Reset the password of %s\n", user.Username)
+			return nil
+		})
+	},
+}
+
+var userDeactivateCmd = &cobra.Command{
+	Use:   "deactivate <user>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Deactivate a user and end their sessions, only the activate command lifts it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withUserSvcs(cmd, func(auth *svcs.AuthSvc, users *svcs.UserSvc) error {
+			user, err := findUser(users, args[0])
+			if err != nil {
+				return err
+			}
+			if err := users.Deactivate(user.Username); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Deactivated %s\n", user.Username)
+			return nil
+		})
+	},
+}
+
+var userActivateCmd = &cobra.Command{
+	Use:   "activate <user>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Activate a deleted or deactivated user again",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withUserSvcs(cmd, func(auth *svcs.AuthSvc, users *svcs.UserSvc) error {
+			user, err := findUser(users, args[0])
+			if err != nil {
+				return err
+			}
+			if !user.Deleted {
+				return utils.ErrUserAlreadyActivated
+			}
+			if err := users.Reactivate(user.Username); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Activated %s\n", user.Username)
+			return nil
+		})
+	},
+}
+
+// withUserSvcs connects to the database and runs fn with the user services
+func withUserSvcs(cmd *cobra.Command, fn func(auth *svcs.AuthSvc, users *svcs.UserSvc) error) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	db, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	return fn(svcs.NewAuthSvc(cfg), svcs.NewUserSvc(cfg, repo.NewGormRepos(dao.Use(db.DB))))
+}
+
+// findUser looks a user up by username, then by email or phone, deactivated
+// users included
+func findUser(users *svcs.UserSvc, ident string) (*model.User, error) {
+	user, err := users.GetUserByUserName(ident, false)
+	if errors.Is(err, utils.ErrUserNotFound) {
+		user, err = users.GetUserWithEmailOrPhone(ident, ident, false)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ident, err)
+	}
+	return user, nil
+}
+
+// readPassword returns --password or the first line of stdin
+func readPassword() (string, error) {
+	if password != "" {
+		return password, nil
+	}
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("no password, pass --password or write it to stdin")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// validationError lists the failed rules of a request like the API reports them
+func validationError(err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+	msgs := make([]string, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		msgs = append(msgs, fe.Field()+" "+validation.Message(fe))
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+func init() {
+	userCreateAdminCmd.Flags().StringVar(&adminReq.FirstName, "first-name", "", "first name of the admin")
+	userCreateAdminCmd.Flags().StringVar(&adminReq.LastName, "last-name", "", "last name of the admin")
+	userCreateAdminCmd.Flags().StringVar(&adminReq.Email, "email", "", "email of the admin")
+	userCreateAdminCmd.Flags().StringVar(&adminReq.Phone, "phone", "", "E.164 phone of the admin, e.g. +919876543210")
+	userCreateAdminCmd.Flags().StringVar(&adminReq.Address, "address", "", "address of the admin")
+	for _, cmd := range []*cobra.Command{userCreateAdminCmd, userResetPasswordCmd} {
+		cmd.Flags().StringVar(&password, "password", "", "password, read from stdin when not set")
+	}
+	userCmd.AddCommand(userCreateAdminCmd, userSetRoleCmd, userResetPasswordCmd, userDeactivateCmd, userActivateCmd)
+	rootCmd.AddCommand(userCmd)
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/realtime"
+	"booking.com/internal/repo"
+	"booking.com/internal/worker"
+
+	"github.com/spf13/cobra"
+)
+
+// --------------------------------------------------
+// workerCmd: Run the worker without the api
+// --------------------------------------------------
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Args:  cobra.NoArgs,
+	Short: "Run the outbox dispatcher, the job runner and the schedules without the api",
+	Long: `Runs the outbox dispatcher, the job runner and the schedules without the api,
+deploy it with JOBS_IN_PROCESS=false on the api servers. With --auto-migrate
+the pending migrations are applied first.
+Usage examples:
+  bookmylab worker
+  bookmylab worker --concurrency 8`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := autoMigrate(cfg); err != nil {
+			return err
+		}
+		db, err := connect(cfg)
+		if err != nil {
+			return err
+		}
+		dao.SetDefault(db.DB)
+
+		pubsub, err := realtime.NewPubSub(cfg.Realtime, db)
+		if err != nil {
+			return fmt.Errorf("create realtime pubsub: %w", err)
+		}
+		if cfg.Realtime.PubSub != realtime.ProviderPostgres {
+			log.Println("warning: realtime messages published by this worker only reach streams of this process, set REALTIME_PUB_SUB=postgres")
+		}
+		w, err := worker.New(cfg, repo.NewGormRepos(dao.Q), pubsub)
+		if err != nil {
+			return fmt.Errorf("create worker: %w", err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		log.Println("worker started")
+		w.Run(ctx)
+		log.Println("worker stopped")
+		return nil
+	},
+}
+
+func init() {
+	flags := workerCmd.Flags()
+	bindEnv(flags, "concurrency", "JOBS_CONCURRENCY", "number of jobs run at once")
+	bindEnvBool(flags, "auto-migrate", "MIGRATIONS_AUTO", "apply the pending migrations before starting")
+	rootCmd.AddCommand(workerCmd)
+}
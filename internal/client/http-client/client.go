@@ -1,9 +1,13 @@
 package httpclient
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"booking.com/internal/utils"
 	"booking.com/pkg/constants"
@@ -35,6 +39,23 @@ func NewHttpTlsClient(caCertPath string) (*CustomHttpClient, error) {
 	}, nil
 }
 
+// NewHttpClient returns a client trusting the system roots, a request gives up after timeout
+func NewHttpClient(timeout time.Duration) *CustomHttpClient {
+	return &CustomHttpClient{
+		Client:  &http.Client{Timeout: timeout},
+		Scheama: constants.Https,
+	}
+}
+
+// RawResponse is a response that was not decoded, Body holds at most maxRawBody bytes
+type RawResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+const maxRawBody = 64 << 10
+
 func (c *CustomHttpClient) Get(path string, queryParams map[string]string, response any) error {
 	return c.do(http.MethodGet, path, queryParams, nil, response)
 }
@@ -70,3 +91,25 @@ func (c *CustomHttpClient) do(method, path string, queryParams map[string]string
 	}
 	return nil
 }
+
+// PostRaw sends body as is with header, any status is returned without error
+// so the caller decides what a failure is
+func (c *CustomHttpClient) PostRaw(ctx context.Context, path string, header http.Header, body []byte) (*RawResponse, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	httpRsp, err := c.Client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed %s %s: %w", http.MethodPost, request.URL.Redacted(), err)
+	}
+	defer httpRsp.Body.Close()
+	rspBody, err := io.ReadAll(io.LimitReader(httpRsp.Body, maxRawBody))
+	if err != nil {
+		return nil, err
+	}
+	return &RawResponse{StatusCode: httpRsp.StatusCode, Header: httpRsp.Header, Body: rspBody}, nil
+}
@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"booking.com/pkg/constants"
+)
+
+// ErrNonPublicAddress is returned for hosts that resolve to loopback, private,
+// shared, link-local or unspecified addresses
+var ErrNonPublicAddress = errors.New("address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, it is not
+// routable on the internet though netip doesn't count it as private
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewOutboundHttpClient returns a client for urls users control, it never follows
+// redirects and unless allowPrivate only connects to public addresses. The check
+// runs on the dialed address so a host re-resolving to a private one is refused too
+func NewOutboundHttpClient(timeout time.Duration, allowPrivate bool) *CustomHttpClient {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = dialPublicOnly
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be the dialed address and hide the one the url resolves to
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &CustomHttpClient{
+		Client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Scheama: constants.Https,
+	}
+}
+
+// CheckPublicHost resolves host and fails with ErrNonPublicAddress if any of
+// its addresses isn't public
+func CheckPublicHost(ctx context.Context, host string) error {
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return err
+		}
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return ErrNonPublicAddress
+		}
+	}
+	return nil
+}
+
+// IsPublicAddr reports whether addr is neither loopback, private, shared,
+// link-local nor unspecified
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && !addr.IsLoopback() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr) &&
+		!addr.IsUnspecified() && !addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() && !addr.IsInterfaceLocalMulticast()
+}
+
+func dialPublicOnly(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return ErrNonPublicAddress
+	}
+	return nil
+}
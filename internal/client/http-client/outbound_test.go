@@ -0,0 +1,37 @@
+package httpclient
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "100.63.255.255", want: true},
+		{addr: "100.128.0.0", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "10.0.0.5"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.10"},
+		{addr: "100.64.0.1"},
+		{addr: "100.127.255.254"},
+		{addr: "::ffff:100.64.0.1"},
+		{addr: "169.254.169.254"},
+		{addr: "0.0.0.0"},
+		{addr: "::1"},
+		{addr: "fe80::1"},
+		{addr: "fd00::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := IsPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("IsPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
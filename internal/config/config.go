@@ -1,9 +1,9 @@
 package config
 
 import (
-	"log"
+	"os"
+	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -11,6 +11,21 @@ type AppConfig struct {
 	Jwt          Jwt        `split_words:"true" required:"true"`
 	HttpServer   Server     `split_words:"true" required:"true"`
 	PostgresqlDb PostgreSQL `split_words:"true" required:"true"`
+	Mail         Mail
+	BlobStore    BlobStore `split_words:"true"`
+	Outbox       Outbox
+	Jobs         Jobs
+	Realtime     Realtime
+	Webhooks     Webhooks
+	Calendar     Calendar
+	Visits       Visits
+	Migrations   Migrations
+	Cors         Cors
+	Security     Security
+	Cookies      Cookies
+	Csrf         Csrf
+	RateLimit    RateLimit `split_words:"true"`
+	Idempotency  Idempotency
 }
 
 type PostgreSQL struct {
@@ -18,8 +33,17 @@ type PostgreSQL struct {
 	Port     string `split_words:"true" required:"true"`
 	Name     string `split_words:"true" required:"true"`
 	UserName string `split_words:"true" required:"true"`
-	Password string `split_words:"true" required:"true"`
+	Password string `split_words:"true" required:"true" secret:"true"`
 	Schema   string `split_words:"true" required:"true"`
+	// Replicas is a comma separated list of host:port read replicas sharing the primary credentials
+	Replicas         []string      `split_words:"true"`
+	SslMode          string        `split_words:"true" default:"disable"`
+	SslRootCert      string        `split_words:"true"`
+	MaxOpenConns     int           `split_words:"true" default:"25"`
+	MaxIdleConns     int           `split_words:"true" default:"5"`
+	ConnMaxLifetime  time.Duration `split_words:"true" default:"30m"`
+	ConnMaxIdleTime  time.Duration `split_words:"true" default:"5m"`
+	StatementTimeout time.Duration `split_words:"true" default:"30s"`
 }
 
 type Server struct {
@@ -28,6 +52,149 @@ type Server struct {
 	KeyPath    string `split_words:"true" required:"true"`
 	CaCertPath string `split_words:"true" required:"true"`
 	Mode       string `split_words:"true" default:"release"`
+	// Tls serves https with CertPath and KeyPath, leave it off when a proxy terminates TLS
+	Tls bool `default:"false"`
+	// DrainDelay keeps the listener open after /readyz starts failing so load balancers stop routing to us
+	DrainDelay      time.Duration `split_words:"true" default:"5s"`
+	ShutdownTimeout time.Duration `split_words:"true" default:"15s"`
+	ProbeTimeout    time.Duration `split_words:"true" default:"2s"`
+	// TrustedProxies are the ips or cidrs whose X-Forwarded-For gives the client ip,
+	// none by default so the client ip is the peer address
+	TrustedProxies []string `split_words:"true"`
+}
+
+type Mail struct {
+	Provider     string `default:"local"` // ses, smtp or local
+	From         string `default:"BookMyLab <no-reply@bookmylab.com>"`
+	SesRegion    string `split_words:"true" default:"ap-south-1"`
+	SmtpAddr     string `split_words:"true"`
+	SmtpUsername string `split_words:"true"`
+	SmtpPassword string `split_words:"true" secret:"true"`
+	// LocalDir is where the local provider writes .eml files, messages are only logged when it is empty
+	LocalDir string `split_words:"true"`
+}
+
+type Outbox struct {
+	PollInterval time.Duration `split_words:"true" default:"1s"`
+	BatchSize    int           `split_words:"true" default:"50"`
+	// Lease hides a claimed event from other dispatchers while it is delivered
+	Lease       time.Duration `default:"1m"`
+	MaxAttempts int32         `split_words:"true" default:"10"`
+	BaseBackoff time.Duration `split_words:"true" default:"2s"`
+	MaxBackoff  time.Duration `split_words:"true" default:"10m"`
+}
+
+type Jobs struct {
+	// InProcess runs the workers inside the api server, disable it when a standalone worker is deployed
+	InProcess    bool          `split_words:"true" default:"true"`
+	Concurrency  int           `default:"4"`
+	PollInterval time.Duration `split_words:"true" default:"1s"`
+	// Lease is how long a claimed job may run before another worker picks it up again
+	Lease       time.Duration `default:"5m"`
+	BaseBackoff time.Duration `split_words:"true" default:"10s"`
+	MaxBackoff  time.Duration `split_words:"true" default:"1h"`
+	// Retention is how long finished jobs, delivered events and webhook deliveries are kept
+	Retention time.Duration `default:"168h"`
+}
+
+type Realtime struct {
+	// PubSub is local or postgres, a standalone worker only reaches the api servers through postgres
+	PubSub    string        `split_words:"true" default:"local"`
+	Channel   string        `default:"realtime"`
+	Heartbeat time.Duration `default:"25s"`
+	// Buffer is how many messages a stream may fall behind before it is dropped
+	Buffer int `default:"64"`
+	// History is how many recent messages per user are kept for Last-Event-ID resume
+	History int `default:"100"`
+	// HistoryTTL is how long the history of a user without open streams is kept
+	HistoryTTL time.Duration `split_words:"true" default:"1h"`
+}
+
+type Webhooks struct {
+	// Timeout bounds a single delivery, a slow endpoint counts as a failed attempt
+	Timeout     time.Duration `default:"10s"`
+	MaxAttempts int32         `split_words:"true" default:"8"`
+	// AllowInsecure accepts http urls, only meant for local development
+	AllowInsecure bool `split_words:"true" default:"false"`
+	// AllowPrivate accepts urls of loopback and private addresses, only meant for local development
+	AllowPrivate bool `split_words:"true" default:"false"`
+}
+
+type Calendar struct {
+	// FeedBaseURL is the public address of the api the feed urls are built on
+	FeedBaseURL   string        `split_words:"true" default:"https://localhost:8080"`
+	VisitDuration time.Duration `split_words:"true" default:"1h"`
+	// FeedRefresh is how often calendar apps are asked to reload a feed
+	FeedRefresh time.Duration `split_words:"true" default:"1h"`
+}
+
+type Visits struct {
+	// ReminderOffsets are how long before an accepted visit both sides are reminded
+	ReminderOffsets []time.Duration `split_words:"true" default:"24h,1h"`
+	// FollowUpDelay is how long after the visit starts the partner is asked to mark it completed or no-show
+	FollowUpDelay time.Duration `split_words:"true" default:"2h"`
+	// NoShowLimit is how many no-shows restrict a buyer from booking, 0 never restricts
+	NoShowLimit       int32         `split_words:"true" default:"2"`
+	NoShowRestriction time.Duration `split_words:"true" default:"720h"`
+}
+
+type Cors struct {
+	// AllowedOrigins are the browser origins allowed to call the api, a *. in
+	// front of the host allows every subdomain like https://*.bookmylab.com
+	AllowedOrigins   []string      `split_words:"true" default:"http://localhost:8080"`
+	AllowedMethods   []string      `split_words:"true" default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	AllowedHeaders   []string      `split_words:"true" default:"Authorization,Content-Type,Accept,Accept-Language,Last-Event-ID,X-CSRF-Token,Idempotency-Key,If-Match,If-None-Match"`
+	ExposedHeaders   []string      `split_words:"true" default:"Retry-After,Idempotent-Replayed,ETag"`
+	AllowCredentials bool          `split_words:"true" default:"true"`
+	MaxAge           time.Duration `split_words:"true" default:"12h"`
+}
+
+type Security struct {
+	// HstsMaxAge is sent as Strict-Transport-Security when TLS is on, 0 disables it
+	HstsMaxAge            time.Duration `split_words:"true" default:"4320h"`
+	HstsIncludeSubdomains bool          `split_words:"true" default:"true"`
+	// BehindTlsProxy sends HSTS though the server listens on http behind a proxy terminating TLS
+	BehindTlsProxy bool   `split_words:"true" default:"false"`
+	FrameOptions   string `split_words:"true" default:"DENY"`
+	ReferrerPolicy string `split_words:"true" default:"strict-origin-when-cross-origin"`
+	// ContentSecurityPolicy covers the json api, the Swagger UI page sends its own policy
+	ContentSecurityPolicy string `split_words:"true" default:"default-src 'none'; frame-ancestors 'none'"`
+}
+
+type Csrf struct {
+	// Enabled requires the csrf token header on the endpoints the refresh token cookie authenticates
+	Enabled bool `default:"true"`
+}
+
+type Cookies struct {
+	// Secure keeps the refresh token off plain http, only turn it off for local development
+	Secure bool `default:"true"`
+	// SameSite is strict, lax or none, none needs Secure
+	SameSite string `split_words:"true" default:"strict"`
+	Domain   string
+}
+
+type RateLimit struct {
+	// Requests is how many requests a client ip may make per Window, 0 disables the limit
+	Requests int           `default:"0"`
+	Window   time.Duration `default:"1m"`
+	// Burst is how many of them may come at once, it defaults to Requests
+	Burst int `default:"0"`
+}
+
+type Idempotency struct {
+	// TTL is how long the response of a request with an Idempotency-Key is replayed
+	TTL time.Duration `default:"24h"`
+}
+
+type Migrations struct {
+	// Auto applies the pending migrations when the server or the worker starts
+	Auto bool `default:"false"`
+}
+
+type BlobStore struct {
+	// Endpoint is the base URL of the bucket serving property photos
+	Endpoint string
 }
 
 type Jwt struct {
@@ -35,14 +202,40 @@ type Jwt struct {
 	RefreshTokenExpiry int64 `split_words:"true" default:"60"`
 }
 
+// DefaultEnvFile is the env file read when none is given, relative to the repository root
+const DefaultEnvFile = "internal/config/.env"
+
+// Sources are the layers of the configuration from lowest to highest, the
+// defaults of the struct tags come below all of them
+type Sources struct {
+	// File is a yaml or toml file whose sections follow AppConfig, CONFIG_FILE names it when empty
+	File string
+	// EnvFile is a dotenv file, the environment wins over it
+	EnvFile string
+	// Overrides are keyed by variable name and win over everything else
+	Overrides map[string]string
+}
+
 func LoadAppConfig() (*AppConfig, error) {
-	if err := godotenv.Load("internal/config/.env"); err != nil {
-		log.Printf("No .env file found: %v", err)
+	return Load(Sources{EnvFile: DefaultEnvFile})
+}
+
+// Load merges the sources into the environment, resolves the *_FILE secrets
+// and processes and validates the result
+func Load(src Sources) (*AppConfig, error) {
+	if src.File == "" {
+		src.File = os.Getenv("CONFIG_FILE")
+	}
+	if err := apply(src); err != nil {
+		return nil, err
 	}
 	var appCfg AppConfig
 	err := envconfig.Process("", &appCfg)
 	if err != nil {
 		return nil, err
 	}
+	if err := appCfg.Validate(); err != nil {
+		return nil, err
+	}
 	return &appCfg, nil
 }
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// required are the settings without a default
+var required = map[string]string{
+	"HTTP_SERVER_ADDRESS":      ":8080",
+	"HTTP_SERVER_CERT_PATH":    "server.crt",
+	"HTTP_SERVER_KEY_PATH":     "server.key",
+	"HTTP_SERVER_CA_CERT_PATH": "ca.crt",
+	"POSTGRESQL_DB_HOST":       "localhost",
+	"POSTGRESQL_DB_PORT":       "5432",
+	"POSTGRESQL_DB_NAME":       "bookmylabdb",
+	"POSTGRESQL_DB_USER_NAME":  "bookmylab",
+	"POSTGRESQL_DB_PASSWORD":   "admin123",
+	"POSTGRESQL_DB_SCHEMA":     "public",
+}
+
+// cleanEnv clears every setting for the test and restores them afterwards,
+// Load writes the merged sources into the process environment
+func cleanEnv(t *testing.T) {
+	t.Helper()
+	keys := []string{"CONFIG_FILE"}
+	for _, setting := range Settings(&AppConfig{}) {
+		keys = append(keys, setting.Key, setting.Key+fileSuffix)
+	}
+	for _, key := range keys {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	cleanEnv(t)
+	overrides := map[string]string{"HTTP_SERVER_MODE": "test"}
+	for key, value := range required {
+		overrides[key] = value
+	}
+	delete(overrides, "POSTGRESQL_DB_HOST")
+	delete(overrides, "POSTGRESQL_DB_NAME")
+	file := writeFile(t, "bookmylab.yaml", `
+http_server:
+  mode: release
+postgresql_db:
+  host: from-file
+  name: from-file
+  replicas: [replica1:5432, replica2:5432]
+jobs:
+  concurrency: 9
+cors:
+  allowed_origins:
+    - https://app.example.com
+`)
+	envFile := writeFile(t, ".env", "export POSTGRESQL_DB_HOST=\"from-env-file\"\nexport JOBS_CONCURRENCY=7\n")
+	os.Setenv("JOBS_CONCURRENCY", "5")
+
+	cfg, err := Load(Sources{File: file, EnvFile: envFile, Overrides: overrides})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PostgresqlDb.Name != "from-file" || len(cfg.PostgresqlDb.Replicas) != 2 {
+		t.Errorf("got name %q replicas %v, want the config file values", cfg.PostgresqlDb.Name, cfg.PostgresqlDb.Replicas)
+	}
+	if cfg.PostgresqlDb.Host != "from-env-file" {
+		t.Errorf("got host %q, want the env file to win over the config file", cfg.PostgresqlDb.Host)
+	}
+	if cfg.Jobs.Concurrency != 5 {
+		t.Errorf("got concurrency %d, want the environment to win over the env file", cfg.Jobs.Concurrency)
+	}
+	if cfg.HttpServer.Mode != "test" {
+		t.Errorf("got mode %q, want the override to win over the config file", cfg.HttpServer.Mode)
+	}
+	if !slices.Equal(cfg.Cors.AllowedOrigins, []string{"https://app.example.com"}) {
+		t.Errorf("got origins %v", cfg.Cors.AllowedOrigins)
+	}
+	if cfg.Outbox.BatchSize != 50 {
+		t.Errorf("got batch size %d, want the default", cfg.Outbox.BatchSize)
+	}
+}
+
+func TestLoadTomlAndSecretFiles(t *testing.T) {
+	cleanEnv(t)
+	secret := writeFile(t, "db_password", "s3cret\n")
+	smtpSecret := writeFile(t, "smtp_password", "smtp-s3cret")
+	file := writeFile(t, "bookmylab.toml", `
+[mail]
+provider = "smtp"
+smtp_addr = "mail:1025"
+smtp_password_file = "`+smtpSecret+`"
+`)
+	overrides := map[string]string{}
+	for key, value := range required {
+		overrides[key] = value
+	}
+	delete(overrides, "POSTGRESQL_DB_PASSWORD")
+	os.Setenv("POSTGRESQL_DB_PASSWORD_FILE", secret)
+
+	cfg, err := Load(Sources{File: file, Overrides: overrides})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PostgresqlDb.Password != "s3cret" {
+		t.Errorf("got password %q, want the secret file without the newline", cfg.PostgresqlDb.Password)
+	}
+	if cfg.Mail.SmtpPassword != "smtp-s3cret" || cfg.Mail.SmtpAddr != "mail:1025" {
+		t.Errorf("got mail %+v, want the toml values", cfg.Mail)
+	}
+
+	os.Setenv("POSTGRESQL_DB_PASSWORD", "clear")
+	if _, err := Load(Sources{Overrides: overrides}); err == nil || !strings.Contains(err.Error(), "not both") {
+		t.Errorf("got %v, want an error for a value and a secret file in the same layer", err)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		set     map[string]string
+		wantErr string
+	}{
+		{name: "unknown setting", file: "c.yaml", content: "http_server:\n  adress: :8080\n", wantErr: "unknown settings HTTP_SERVER_ADRESS"},
+		{name: "unsupported format", file: "c.json", content: "{}", wantErr: "unsupported format"},
+		{name: "missing secret file", set: map[string]string{"MAIL_SMTP_PASSWORD_FILE": "/nonexistent/secret"}, wantErr: "MAIL_SMTP_PASSWORD_FILE"},
+		{name: "refresh shorter than access", set: map[string]string{"JWT_ACCESS_TOKEN_EXPIRY": "30", "JWT_REFRESH_TOKEN_EXPIRY": "20"}, wantErr: "JWT_REFRESH_TOKEN_EXPIRY"},
+		{name: "smtp without address", set: map[string]string{"MAIL_PROVIDER": "smtp"}, wantErr: "MAIL_SMTP_ADDR"},
+		{name: "origin with a path", set: map[string]string{"CORS_ALLOWED_ORIGINS": "https://app.example.com/login"}, wantErr: "CORS_ALLOWED_ORIGINS"},
+		{name: "proxy that isn't an ip", set: map[string]string{"HTTP_SERVER_TRUSTED_PROXIES": "lb.internal"}, wantErr: "HTTP_SERVER_TRUSTED_PROXIES"},
+		{name: "backoff above max", set: map[string]string{"JOBS_BASE_BACKOFF": "2h"}, wantErr: "JOBS_BASE_BACKOFF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanEnv(t)
+			src := Sources{Overrides: map[string]string{}}
+			for key, value := range required {
+				src.Overrides[key] = value
+			}
+			for key, value := range tt.set {
+				os.Setenv(key, value)
+			}
+			if tt.file != "" {
+				src.File = writeFile(t, tt.file, tt.content)
+			}
+			_, err := Load(src)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSettingsKeys keeps the keys of the config file and of config print in
+// line with the variables envconfig reads
+func TestSettingsKeys(t *testing.T) {
+	var usage bytes.Buffer
+	if err := envconfig.Usagef("", &AppConfig{}, &usage, "{{range .}}{{.Key}}\n{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Fields(usage.String())
+	var got []string
+	for _, setting := range Settings(&AppConfig{}) {
+		got = append(got, setting.Key)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got keys %v, want %v", got, want)
+	}
+}
+
+func TestPrintRedacted(t *testing.T) {
+	cfg := &AppConfig{
+		PostgresqlDb: PostgreSQL{Host: "db", Password: "admin123"},
+		Visits:       Visits{ReminderOffsets: []time.Duration{24 * time.Hour, time.Hour}},
+	}
+	var out bytes.Buffer
+	if err := Print(&out, cfg, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`POSTGRESQL_DB_HOST="db"`,
+		`POSTGRESQL_DB_PASSWORD="` + Redacted + `"`,
+		`MAIL_SMTP_PASSWORD=""`,
+		`VISITS_REMINDER_OFFSETS="24h0m0s,1h0m0s"`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("missing %s in\n%s", line, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "admin123") {
+		t.Error("the redacted output leaks the password")
+	}
+}
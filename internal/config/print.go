@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the value of a secret setting when printing
+const Redacted = "********"
+
+// Print writes cfg as env file lines, secrets that are set are replaced with
+// Redacted when redacted is true
+func Print(w io.Writer, cfg *AppConfig, redacted bool) error {
+	for _, setting := range Settings(cfg) {
+		value := format(setting.Value)
+		if redacted && setting.Secret && value != "" {
+			value = Redacted
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", setting.Key, strconv.Quote(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// format writes a value the way envconfig parses it back
+func format(v reflect.Value) string {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = format(v.Index(i))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// fileSuffix names a variable holding the path of a file with the value, the
+// way docker and kubernetes mount secrets
+const fileSuffix = "_FILE"
+
+// Setting is a configuration value with the variable it is read from
+type Setting struct {
+	Key    string
+	Value  reflect.Value
+	Secret bool
+}
+
+// Settings lists the values of cfg in field order, keyed the way envconfig
+// names them
+func Settings(cfg *AppConfig) []Setting {
+	return settings("", reflect.ValueOf(cfg).Elem())
+}
+
+var (
+	gatherWords  = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	splitAcronym = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+func settings(prefix string, v reflect.Value) []Setting {
+	var out []Setting
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get("ignored") == "true" {
+			continue
+		}
+		key := field.Name
+		if name := field.Tag.Get("envconfig"); name != "" {
+			key = name
+		} else if field.Tag.Get("split_words") == "true" {
+			var words []string
+			for _, word := range gatherWords.FindAllString(field.Name, -1) {
+				if m := splitAcronym.FindStringSubmatch(word); len(m) == 3 {
+					words = append(words, m[1], m[2])
+				} else {
+					words = append(words, word)
+				}
+			}
+			key = strings.Join(words, "_")
+		}
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		key = strings.ToUpper(key)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			out = append(out, settings(key, v.Field(i))...)
+			continue
+		}
+		out = append(out, Setting{Key: key, Value: v.Field(i), Secret: field.Tag.Get("secret") == "true"})
+	}
+	return out
+}
+
+// apply writes the merged sources into the environment envconfig reads
+func apply(src Sources) error {
+	known := map[string]bool{}
+	for _, setting := range Settings(&AppConfig{}) {
+		known[setting.Key] = true
+	}
+	var layers []map[string]string
+	if src.File != "" {
+		values, err := readFile(src.File, known)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, values)
+	}
+	if src.EnvFile != "" {
+		values, err := godotenv.Read(src.EnvFile)
+		if err != nil {
+			log.Printf("No .env file found: %v", err)
+		}
+		layers = append(layers, values)
+	}
+	environ := map[string]string{}
+	for key := range known {
+		for _, name := range []string{key, key + fileSuffix} {
+			if value, ok := os.LookupEnv(name); ok {
+				environ[name] = value
+			}
+		}
+	}
+	layers = append(layers, environ, src.Overrides)
+
+	merged := map[string]string{}
+	for _, layer := range layers {
+		for key, value := range layer {
+			base, isFile := strings.CutSuffix(key, fileSuffix)
+			if isFile && known[base] {
+				if layer[base] != "" && value != "" {
+					return fmt.Errorf("set either %s or %s, not both", base, key)
+				}
+				delete(merged, base)
+			} else {
+				delete(merged, key+fileSuffix)
+			}
+			merged[key] = value
+		}
+	}
+	for key, path := range merged {
+		base, isFile := strings.CutSuffix(key, fileSuffix)
+		if !isFile || !known[base] || path == "" {
+			continue
+		}
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		merged[base] = strings.TrimRight(string(secret), "\r\n")
+	}
+	for key, value := range merged {
+		if current, ok := os.LookupEnv(key); ok && current == value {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFile flattens a yaml or toml file into variables, a key of a section is
+// joined to the section name so http_server.address is HTTP_SERVER_ADDRESS
+func readFile(path string, known map[string]bool) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	var tree map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q, use yaml or toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	values := map[string]string{}
+	if err := flatten("", tree, values); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	var unknown []string
+	for key := range values {
+		if !known[key] && !known[strings.TrimSuffix(key, fileSuffix)] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return nil, fmt.Errorf("config file %s: unknown settings %s", path, strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
+
+func flatten(prefix string, tree map[string]any, values map[string]string) error {
+	for name, value := range tree {
+		key := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			if err := flatten(key, value, values); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				if _, nested := item.(map[string]any); nested {
+					return fmt.Errorf("%s: lists hold plain values only", key)
+				}
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
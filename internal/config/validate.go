@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bounds of the token expiries, in minutes
+const (
+	maxAccessTokenExpiry  = 24 * 60
+	maxRefreshTokenExpiry = 90 * 24 * 60
+)
+
+// Validate checks the values envconfig can't, every problem is reported at once
+func (c *AppConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Jwt.AccessTokenExpiry > 0 && c.Jwt.AccessTokenExpiry <= maxAccessTokenExpiry,
+		"JWT_ACCESS_TOKEN_EXPIRY must be between 1 and %d minutes", maxAccessTokenExpiry)
+	check(c.Jwt.RefreshTokenExpiry > c.Jwt.AccessTokenExpiry && c.Jwt.RefreshTokenExpiry <= maxRefreshTokenExpiry,
+		"JWT_REFRESH_TOKEN_EXPIRY must be longer than JWT_ACCESS_TOKEN_EXPIRY and at most %d minutes", maxRefreshTokenExpiry)
+
+	check(slices.Contains([]string{"debug", "release", "test"}, c.HttpServer.Mode), "HTTP_SERVER_MODE must be debug, release or test")
+	check(c.HttpServer.ShutdownTimeout > 0, "HTTP_SERVER_SHUTDOWN_TIMEOUT must be positive")
+	check(c.HttpServer.ProbeTimeout > 0, "HTTP_SERVER_PROBE_TIMEOUT must be positive")
+	for _, proxy := range c.HttpServer.TrustedProxies {
+		check(isIPOrPrefix(proxy), "HTTP_SERVER_TRUSTED_PROXIES: %q is not an ip or cidr", proxy)
+	}
+
+	port, err := strconv.Atoi(c.PostgresqlDb.Port)
+	check(err == nil && port > 0 && port < 1<<16, "POSTGRESQL_DB_PORT must be a port number")
+	check(slices.Contains([]string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}, c.PostgresqlDb.SslMode),
+		"POSTGRESQL_DB_SSL_MODE must be one of disable, allow, prefer, require, verify-ca, verify-full")
+	check(c.PostgresqlDb.MaxOpenConns > 0 && c.PostgresqlDb.MaxIdleConns <= c.PostgresqlDb.MaxOpenConns,
+		"POSTGRESQL_DB_MAX_OPEN_CONNS must be positive and at least POSTGRESQL_DB_MAX_IDLE_CONNS")
+
+	check(slices.Contains([]string{"ses", "smtp", "local"}, c.Mail.Provider), "MAIL_PROVIDER must be ses, smtp or local")
+	check(c.Mail.Provider != "smtp" || c.Mail.SmtpAddr != "", "MAIL_SMTP_ADDR is required with the smtp provider")
+	_, err = mail.ParseAddress(c.Mail.From)
+	check(err == nil, "MAIL_FROM must be an email address")
+	check(c.BlobStore.Endpoint == "" || isHTTPURL(c.BlobStore.Endpoint), "BLOB_STORE_ENDPOINT must be an http or https url")
+
+	check(c.Outbox.PollInterval > 0 && c.Outbox.BatchSize > 0 && c.Outbox.MaxAttempts > 0,
+		"OUTBOX_POLL_INTERVAL, OUTBOX_BATCH_SIZE and OUTBOX_MAX_ATTEMPTS must be positive")
+	check(backoff(c.Outbox.BaseBackoff, c.Outbox.MaxBackoff), "OUTBOX_BASE_BACKOFF must be positive and at most OUTBOX_MAX_BACKOFF")
+	check(c.Jobs.PollInterval > 0 && c.Jobs.Concurrency > 0 && c.Jobs.Lease > 0,
+		"JOBS_POLL_INTERVAL, JOBS_CONCURRENCY and JOBS_LEASE must be positive")
+	check(backoff(c.Jobs.BaseBackoff, c.Jobs.MaxBackoff), "JOBS_BASE_BACKOFF must be positive and at most JOBS_MAX_BACKOFF")
+
+	check(slices.Contains([]string{"local", "postgres"}, c.Realtime.PubSub), "REALTIME_PUB_SUB must be local or postgres")
+	check(c.Realtime.Heartbeat > 0 && c.Realtime.Buffer > 0 && c.Realtime.HistoryTTL > 0, "REALTIME_HEARTBEAT, REALTIME_BUFFER and REALTIME_HISTORY_TTL must be positive")
+	check(c.Webhooks.Timeout > 0 && c.Webhooks.MaxAttempts > 0, "WEBHOOKS_TIMEOUT and WEBHOOKS_MAX_ATTEMPTS must be positive")
+	check(isHTTPURL(c.Calendar.FeedBaseURL), "CALENDAR_FEED_BASE_URL must be an http or https url")
+	check(c.Calendar.VisitDuration > 0, "CALENDAR_VISIT_DURATION must be positive")
+
+	for _, offset := range c.Visits.ReminderOffsets {
+		check(offset > 0, "VISITS_REMINDER_OFFSETS must all be positive, got %s", offset)
+	}
+	check(c.Visits.NoShowLimit >= 0, "VISITS_NO_SHOW_LIMIT must not be negative")
+
+	for _, origin := range c.Cors.AllowedOrigins {
+		check(origin == "*" || isOrigin(strings.Replace(origin, "://*.", "://", 1)),
+			"CORS_ALLOWED_ORIGINS: %q is not an origin like https://app.example.com or https://*.example.com", origin)
+	}
+	check(!c.Cors.AllowCredentials || !slices.Contains(c.Cors.AllowedOrigins, "*"),
+		"CORS_ALLOWED_ORIGINS can't be * with CORS_ALLOW_CREDENTIALS, browsers reject it")
+	check(c.Cors.MaxAge >= 0, "CORS_MAX_AGE must not be negative")
+	check(c.Security.HstsMaxAge >= 0, "SECURITY_HSTS_MAX_AGE must not be negative")
+	check(slices.Contains([]string{"", "DENY", "SAMEORIGIN"}, c.Security.FrameOptions), "SECURITY_FRAME_OPTIONS must be DENY or SAMEORIGIN")
+	check(slices.Contains([]string{"strict", "lax", "none"}, c.Cookies.SameSite), "COOKIES_SAME_SITE must be strict, lax or none")
+	check(c.Cookies.SameSite != "none" || c.Cookies.Secure, "COOKIES_SAME_SITE none needs COOKIES_SECURE")
+	check(c.RateLimit.Requests >= 0 && c.RateLimit.Burst >= 0, "RATE_LIMIT_REQUESTS and RATE_LIMIT_BURST must not be negative")
+	check(c.RateLimit.Requests == 0 || c.RateLimit.Window > 0, "RATE_LIMIT_WINDOW must be positive")
+	check(c.Idempotency.TTL > 0, "IDEMPOTENCY_TTL must be positive")
+	return errors.Join(errs...)
+}
+
+// ValidateFiles checks that the certificates can be read when TLS is on, only
+// the serve command needs them
+func (s Server) ValidateFiles() error {
+	if !s.Tls {
+		return nil
+	}
+	var errs []error
+	for _, file := range [][2]string{
+		{"HTTP_SERVER_CERT_PATH", s.CertPath},
+		{"HTTP_SERVER_KEY_PATH", s.KeyPath},
+		{"HTTP_SERVER_CA_CERT_PATH", s.CaCertPath},
+	} {
+		if _, err := os.Stat(file[1]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file[0], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func backoff(base, max time.Duration) bool {
+	return base > 0 && base <= max
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isIPOrPrefix(raw string) bool {
+	if _, err := netip.ParseAddr(raw); err == nil {
+		return true
+	}
+	_, err := netip.ParsePrefix(raw)
+	return err == nil
+}
+
+// isOrigin accepts a scheme and host without a path, the form browsers send
+func isOrigin(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme != "" && u.Host != "" && (u.Path == "" || u.Path == "/") && u.RawQuery == ""
+}
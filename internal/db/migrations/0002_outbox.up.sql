@@ -0,0 +1,25 @@
+-- ==========================================================
+-- OUTBOX TABLE: domain events written with the state change
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    event_type        VARCHAR(100) NOT NULL,
+    aggregate_type    VARCHAR(50) NOT NULL,
+    aggregate_id      VARCHAR(100) NOT NULL,
+    payload           JSONB NOT NULL,
+    status            VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts          INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error        TEXT,
+    delivered_at      TIMESTAMP NULL,
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT outbox_status_check CHECK (status IN ('pending', 'delivered', 'dead'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (next_attempt_at) WHERE status = 'pending';
+
+CREATE TRIGGER outbox_update_timestamp
+BEFORE UPDATE ON outbox
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
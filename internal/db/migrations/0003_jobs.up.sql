@@ -0,0 +1,27 @@
+-- ==========================================================
+-- JOBS TABLE: background job queue claimed with SKIP LOCKED
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    kind              VARCHAR(100) NOT NULL,
+    payload           JSONB NOT NULL DEFAULT '{}',
+    unique_key        VARCHAR(200) NULL,
+    status            VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts          INTEGER NOT NULL DEFAULT 0,
+    max_attempts      INTEGER NOT NULL DEFAULT 5,
+    run_at            TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error        TEXT,
+    finished_at       TIMESTAMP NULL,
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT jobs_unique_key_key UNIQUE (unique_key),
+    CONSTRAINT jobs_status_check CHECK (status IN ('pending', 'running', 'succeeded', 'dead', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs (run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_jobs_kind_status ON jobs (kind, status);
+
+CREATE TRIGGER jobs_update_timestamp
+BEFORE UPDATE ON jobs
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
@@ -0,0 +1,41 @@
+-- ==========================================================
+-- NOTIFICATIONS TABLE: in-app inbox of every user
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    username          VARCHAR(50) NOT NULL,
+    type              VARCHAR(50) NOT NULL,
+    title             VARCHAR(200) NOT NULL,
+    body              TEXT NOT NULL,
+    data              JSONB NOT NULL DEFAULT '{}',
+    event_id          BIGINT NULL,  -- outbox event the notification was created from
+    read_at           TIMESTAMP NULL,
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_notifications_event UNIQUE (username, type, event_id),
+    CONSTRAINT fk_notification_user FOREIGN KEY (username)
+        REFERENCES users(username) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (username, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (username) WHERE read_at IS NULL;
+
+
+-- ==========================================================
+-- NOTIFICATION PREFERENCES TABLE: channels per user and type
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    username          VARCHAR(50) NOT NULL,
+    type              VARCHAR(50) NOT NULL,
+    inbox             BOOLEAN NOT NULL DEFAULT true,
+    email             BOOLEAN NOT NULL DEFAULT true,
+    sms               BOOLEAN NOT NULL DEFAULT false,
+    updated_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (username, type),
+    CONSTRAINT fk_notification_preference_user FOREIGN KEY (username)
+        REFERENCES users(username) ON DELETE CASCADE
+);
+
+CREATE TRIGGER notification_preferences_update_timestamp
+BEFORE UPDATE ON notification_preferences
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
@@ -0,0 +1,44 @@
+-- ==========================================================
+-- WEBHOOKS TABLE: partner endpoints receiving their events
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS webhooks (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    username          VARCHAR(50) NOT NULL,
+    url               VARCHAR(2048) NOT NULL,
+    secret            VARCHAR(100) NOT NULL,  -- HMAC-SHA256 key of the signatures
+    event_types       JSONB NOT NULL DEFAULT '[]',  -- empty receives every event
+    active            BOOLEAN NOT NULL DEFAULT true,
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_webhook_user FOREIGN KEY (username)
+        REFERENCES users(username) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_user ON webhooks (username);
+
+CREATE TRIGGER webhooks_update_timestamp
+BEFORE UPDATE ON webhooks
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+
+-- ==========================================================
+-- WEBHOOK DELIVERIES TABLE: log of every delivery attempt
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    webhook_id        BIGINT NOT NULL,
+    event_id          BIGINT NOT NULL,  -- outbox event, 0 for test events
+    event_type        VARCHAR(100) NOT NULL,
+    attempt           INTEGER NOT NULL,
+    status_code       INTEGER NOT NULL,  -- 0 when no response was received
+    success           BOOLEAN NOT NULL,
+    error             TEXT NOT NULL,
+    duration_ms       INTEGER NOT NULL,
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_webhook_delivery_webhook FOREIGN KEY (webhook_id)
+        REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created ON webhook_deliveries (created_at);
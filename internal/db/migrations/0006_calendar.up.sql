@@ -0,0 +1,15 @@
+-- sequence of the calendar invites of a visit, bumped on every status change
+ALTER TABLE visits ADD COLUMN IF NOT EXISTS sequence INTEGER NOT NULL DEFAULT 0;
+
+
+-- ==========================================================
+-- CALENDAR FEEDS TABLE: private feed token of every user
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    username          VARCHAR(50) PRIMARY KEY,
+    token_hash        VARCHAR(64) NOT NULL,  -- sha256 of the token, the token itself is only shown once
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_calendar_feeds_token UNIQUE (token_hash),
+    CONSTRAINT fk_calendar_feed_user FOREIGN KEY (username)
+        REFERENCES users(username) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS booking_restricted_until;
+ALTER TABLE users DROP COLUMN IF EXISTS no_show_count;
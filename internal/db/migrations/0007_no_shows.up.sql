@@ -0,0 +1,3 @@
+-- no-shows of a buyer, repeat offenders can't book visits until booking_restricted_until
+ALTER TABLE users ADD COLUMN IF NOT EXISTS no_show_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS booking_restricted_until TIMESTAMP;
@@ -0,0 +1,18 @@
+-- ==========================================================
+-- IDEMPOTENCY KEYS TABLE: responses of retried POST requests
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    username          VARCHAR(50) NOT NULL,
+    idempotency_key   VARCHAR(255) NOT NULL,
+    fingerprint       VARCHAR(64) NOT NULL,  -- sha256 of the method, path and body
+    status_code       INTEGER NOT NULL DEFAULT 0,  -- 0 while the first request runs
+    content_type      VARCHAR(100) NOT NULL DEFAULT '',
+    response          BYTEA,
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at        TIMESTAMP NOT NULL,
+    PRIMARY KEY (username, idempotency_key),
+    CONSTRAINT fk_idempotency_key_user FOREIGN KEY (username)
+        REFERENCES users(username) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys (expires_at);
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS visits_bump_version ON visits;
+DROP TRIGGER IF EXISTS properties_bump_version ON properties;
+DROP TRIGGER IF EXISTS users_bump_version ON users;
+ALTER TABLE visits DROP COLUMN IF EXISTS version;
+ALTER TABLE properties DROP COLUMN IF EXISTS version;
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+DROP FUNCTION IF EXISTS bump_version();
@@ -0,0 +1,34 @@
+-- ==========================================================
+-- FUNCTION: Bump "version" when a row changes, the ETag of the api
+-- ==========================================================
+-- updated_at and the refresh token change without the client editing
+-- anything, they don't count as a change
+CREATE OR REPLACE FUNCTION bump_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF to_jsonb(NEW) - 'version' - 'updated_at' - 'refresh_token'
+        IS DISTINCT FROM to_jsonb(OLD) - 'version' - 'updated_at' - 'refresh_token' THEN
+        NEW.version = OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE properties ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE visits ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TRIGGER users_bump_version
+BEFORE UPDATE ON users
+FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER properties_bump_version
+BEFORE UPDATE ON properties
+FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER visits_bump_version
+BEFORE UPDATE ON visits
+FOR EACH ROW
+EXECUTE FUNCTION bump_version();
@@ -0,0 +1,2 @@
+-- the locale mails to the user are rendered in, empty falls back to the default
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_ratings_property_buyer;
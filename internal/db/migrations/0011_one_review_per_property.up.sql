@@ -0,0 +1,2 @@
+-- a buyer reviews a property once, deleted reviews don't count
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ratings_property_buyer ON ratings (property_id, buyer_username) WHERE NOT deleted;
@@ -0,0 +1,2 @@
+-- users deactivated by an admin can only be activated again by an admin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_by_admin BOOLEAN NOT NULL DEFAULT false;
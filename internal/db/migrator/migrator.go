@@ -0,0 +1,298 @@
+// Package migrator manages the golang-migrate migrations of the database, it
+// plans every change from the migration files so a dry run can print the SQL
+// that a real run would apply
+package migrator
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// DefaultDir is where new migration files are created, relative to the
+// repository root. The binary runs the copies embedded by package migrations
+const DefaultDir = "internal/db/migrations"
+
+// NilVersion is the version of a database no migration was applied to
+const NilVersion = -1
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Migration is a version with its up and down files
+type Migration struct {
+	Version    uint
+	Identifier string
+	Up         string
+	Down       string
+}
+
+// Step is a migration file a change runs, in order, Path is relative to the
+// migrations fs
+type Step struct {
+	Version   uint
+	Direction source.Direction
+	Path      string
+}
+
+// Status is the state of the database against the migration files
+type Status struct {
+	// Version is NilVersion until a migration was applied
+	Version int
+	Dirty   bool
+	Pending []Step
+}
+
+// Load reads the migrations at the root of fsys sorted by version, every
+// version needs an up and a down file
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[uint]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		parsed, err := source.DefaultParse(entry.Name())
+		if err != nil {
+			continue
+		}
+		migration, ok := byVersion[parsed.Version]
+		if !ok {
+			migration = &Migration{Version: parsed.Version, Identifier: parsed.Identifier}
+			byVersion[parsed.Version] = migration
+		}
+		path := entry.Name()
+		switch {
+		case parsed.Direction == source.Up && migration.Up == "":
+			migration.Up = path
+		case parsed.Direction == source.Down && migration.Down == "":
+			migration.Down = path
+		default:
+			return nil, fmt.Errorf("duplicate %s migration for version %d", parsed.Direction, parsed.Version)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Identifier)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+// PlanTo lists the steps moving the database from current to target, either
+// of them may be NilVersion
+func PlanTo(migrations []Migration, current, target int) ([]Step, error) {
+	if target != NilVersion && index(migrations, target) < 0 {
+		return nil, fmt.Errorf("no migration with version %d", target)
+	}
+	if current != NilVersion && index(migrations, current) < 0 {
+		return nil, fmt.Errorf("database version %d has no migration file", current)
+	}
+	var steps []Step
+	if target >= current {
+		for _, migration := range migrations {
+			if v := int(migration.Version); v > current && v <= target {
+				steps = append(steps, Step{Version: migration.Version, Direction: source.Up, Path: migration.Up})
+			}
+		}
+		return steps, nil
+	}
+	for _, migration := range slices.Backward(migrations) {
+		if v := int(migration.Version); v > target && v <= current {
+			steps = append(steps, Step{Version: migration.Version, Direction: source.Down, Path: migration.Down})
+		}
+	}
+	return steps, nil
+}
+
+// PlanSteps lists the steps applying n migrations from current, a negative n
+// rolls back. It fails when fewer than n migrations are left
+func PlanSteps(migrations []Migration, current, n int) ([]Step, error) {
+	at := -1
+	if current != NilVersion {
+		if at = index(migrations, current); at < 0 {
+			return nil, fmt.Errorf("database version %d has no migration file", current)
+		}
+	}
+	switch to := at + n; {
+	case to >= len(migrations):
+		return nil, fmt.Errorf("only %d migrations left to apply", len(migrations)-1-at)
+	case to < -1:
+		return nil, fmt.Errorf("only %d migrations left to roll back", at+1)
+	case to == -1:
+		return PlanTo(migrations, current, NilVersion)
+	default:
+		return PlanTo(migrations, current, int(migrations[to].Version))
+	}
+}
+
+// Create writes an empty up and down pair named after the time, the returned
+// paths are the up and the down file
+func Create(dir, name string, now time.Time) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !namePattern.MatchString(name) {
+		return "", "", fmt.Errorf("invalid migration name %q, use lowercase letters, digits, - and _", name)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+	base := filepath.Join(dir, now.UTC().Format("20060102150405")+"_"+name)
+	up, down := base+".up.sql", base+".down.sql"
+	for _, path := range []string{up, down} {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return "", "", err
+		}
+		if err := file.Close(); err != nil {
+			return "", "", err
+		}
+	}
+	return up, down, nil
+}
+
+func index(migrations []Migration, version int) int {
+	return slices.IndexFunc(migrations, func(m Migration) bool { return int(m.Version) == version })
+}
+
+// Migrator runs the migrations of an fs against a database
+type Migrator struct {
+	FS         fs.FS
+	Migrations []Migration
+	m          *migrate.Migrate
+}
+
+// New loads the migrations of fsys and binds them to db
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("open migrations: %w", err)
+	}
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("create migrate driver: %w", err), src.Close())
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("create migrate instance: %w", err)
+	}
+	return &Migrator{FS: fsys, Migrations: migrations, m: m}, nil
+}
+
+// Version returns the applied version, NilVersion when none was applied
+func (r *Migrator) Version() (int, bool, error) {
+	version, dirty, err := r.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return NilVersion, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return int(version), dirty, nil
+}
+
+// Status reports the applied version and the migrations newer than it
+func (r *Migrator) Status() (*Status, error) {
+	version, dirty, err := r.Version()
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Version: version, Dirty: dirty}
+	for _, migration := range r.Migrations {
+		if int(migration.Version) > version {
+			status.Pending = append(status.Pending, Step{Version: migration.Version, Direction: source.Up, Path: migration.Up})
+		}
+	}
+	return status, nil
+}
+
+// PlanTo lists the steps moving the database to target
+func (r *Migrator) PlanTo(target int) ([]Step, error) {
+	version, err := r.clean()
+	if err != nil {
+		return nil, err
+	}
+	return PlanTo(r.Migrations, version, target)
+}
+
+// PlanSteps lists the steps applying n migrations, a negative n rolls back
+func (r *Migrator) PlanSteps(n int) ([]Step, error) {
+	version, err := r.clean()
+	if err != nil {
+		return nil, err
+	}
+	return PlanSteps(r.Migrations, version, n)
+}
+
+// Run applies the planned steps one at a time so a failure leaves the
+// database dirty at the failing version
+func (r *Migrator) Run(steps []Step) error {
+	for _, step := range steps {
+		n := 1
+		if step.Direction == source.Down {
+			n = -1
+		}
+		if err := r.m.Steps(n); err != nil {
+			return fmt.Errorf("%s: %w", step.Path, err)
+		}
+	}
+	return nil
+}
+
+// Up applies every pending migration in one golang-migrate run, the database
+// lock is held from reading the version to the last migration so concurrent
+// callers apply each migration once
+func (r *Migrator) Up() error {
+	err := r.m.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// Force sets the version without running anything, it clears the dirty flag
+// after a failed migration was repaired by hand
+func (r *Migrator) Force(version int) error {
+	if version != NilVersion && index(r.Migrations, version) < 0 {
+		return fmt.Errorf("no migration with version %d", version)
+	}
+	return r.m.Force(version)
+}
+
+func (r *Migrator) Close() error {
+	sourceErr, dbErr := r.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// clean returns the applied version, a dirty database needs a force first
+func (r *Migrator) clean() (int, error) {
+	version, dirty, err := r.Version()
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("database is dirty at version %d, repair it and run force", version)
+	}
+	return version, nil
+}
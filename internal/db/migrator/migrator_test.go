@@ -0,0 +1,94 @@
+package migrator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	dbmigrations "booking.com/internal/db/migrations"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestLoadRepositoryMigrations(t *testing.T) {
+	migrations, err := Load(dbmigrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Fatalf("got %d migrations starting at %+v", len(migrations), migrations)
+	}
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			t.Errorf("migrations not sorted: %d after %d", migrations[i].Version, migrations[i-1].Version)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Up: "1.up", Down: "1.down"},
+		{Version: 2, Up: "2.up", Down: "2.down"},
+		{Version: 5, Up: "5.up", Down: "5.down"},
+	}
+	paths := func(steps []Step) []string {
+		var out []string
+		for _, step := range steps {
+			out = append(out, step.Path)
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		plan    func() ([]Step, error)
+		want    []string
+		wantErr bool
+	}{
+		{name: "up all from nothing", plan: func() ([]Step, error) { return PlanTo(migrations, NilVersion, 5) }, want: []string{"1.up", "2.up", "5.up"}},
+		{name: "goto down", plan: func() ([]Step, error) { return PlanTo(migrations, 5, 1) }, want: []string{"5.down", "2.down"}},
+		{name: "down everything", plan: func() ([]Step, error) { return PlanTo(migrations, 2, NilVersion) }, want: []string{"2.down", "1.down"}},
+		{name: "goto current", plan: func() ([]Step, error) { return PlanTo(migrations, 2, 2) }},
+		{name: "goto unknown version", plan: func() ([]Step, error) { return PlanTo(migrations, 2, 3) }, wantErr: true},
+		{name: "up 1", plan: func() ([]Step, error) { return PlanSteps(migrations, 1, 1) }, want: []string{"2.up"}},
+		{name: "down 2", plan: func() ([]Step, error) { return PlanSteps(migrations, 5, -2) }, want: []string{"5.down", "2.down"}},
+		{name: "down past nothing", plan: func() ([]Step, error) { return PlanSteps(migrations, 1, -2) }, wantErr: true},
+		{name: "up past the last", plan: func() ([]Step, error) { return PlanSteps(migrations, 2, 2) }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := tt.plan()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := paths(steps); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, time.March, 2, 10, 30, 5, 0, time.UTC)
+	up, down, err := Create(dir, "Add_Reviews", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(up) != "20250302103005_add_reviews.up.sql" || filepath.Base(down) != "20250302103005_add_reviews.down.sql" {
+		t.Errorf("got %s and %s", up, down)
+	}
+	if _, _, err := Create(dir, "add_reviews", now); !os.IsExist(err) {
+		t.Errorf("creating the pair again: got %v, want an exists error", err)
+	}
+	if _, _, err := Create(dir, "../escape", now); err == nil {
+		t.Error("a name with a path separator must be rejected")
+	}
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil || len(migrations) != 1 || migrations[0].Identifier != "add_reviews" {
+		t.Fatalf("Load() = %+v, %v", migrations, err)
+	}
+	if steps, _ := PlanTo(migrations, NilVersion, int(migrations[0].Version)); len(steps) != 1 || steps[0].Direction != source.Up {
+		t.Errorf("got steps %+v", steps)
+	}
+}
@@ -1,48 +1,188 @@
 package dao
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"net"
+	"strings"
+	"time"
 
 	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/model"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	RolePrimary = "primary"
+	RoleReplica = "replica"
 )
 
 type DBS struct {
-	Host     string
-	Port     string
-	Name     string
-	User     string
-	Password string
-	Schema   string
+	Host             string
+	Port             string
+	Name             string
+	User             string
+	Password         string
+	Schema           string
+	SslMode          string
+	SslRootCert      string
+	StatementTimeout time.Duration
 }
 
-func Connect(cfg config.PostgreSQL) (*gorm.DB, error) {
+// Node is a single postgres server of the cluster with its own connection pool
+type Node struct {
+	Name string
+	Role string
+	Pool *sql.DB
+}
+
+// NodeStatus is the result of a health check of one node
+type NodeStatus struct {
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Up        bool   `json:"up"`
+	LatencyMs int64  `json:"latency_ms"`
+	OpenConns int    `json:"open_conns"`
+	InUse     int    `json:"in_use"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Cluster is the gorm handle of the primary, reads are routed to the replicas
+// by dbresolver unless a query asks for WriteDB()
+type Cluster struct {
+	DB    *gorm.DB
+	Nodes []Node
+}
+
+func Connect(cfg config.PostgreSQL) (*Cluster, error) {
 	d := Init(cfg)
-	db, err := gorm.Open(postgres.Open(d.connectionString()), &gorm.Config{
+	primary, err := openPool(d, cfg)
+	if err != nil {
+		log.Println("error in Open ", err)
+		return nil, err
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: primary}), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
 		log.Println("error in Open ", err)
 		return nil, err
 	}
+	cluster := &Cluster{DB: db, Nodes: []Node{{Name: net.JoinHostPort(d.Host, d.Port), Role: RolePrimary, Pool: primary}}}
+
+	if len(cfg.Replicas) == 0 {
+		return cluster, nil
+	}
+	replicas := make([]gorm.Dialector, 0, len(cfg.Replicas))
+	for _, addr := range cfg.Replicas {
+		rd := d
+		if rd.Host, rd.Port, err = net.SplitHostPort(strings.TrimSpace(addr)); err != nil {
+			return nil, fmt.Errorf("invalid replica address %q: %w", addr, err)
+		}
+		pool, err := openPool(rd, cfg)
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, postgres.New(postgres.Config{Conn: pool}))
+		cluster.Nodes = append(cluster.Nodes, Node{Name: net.JoinHostPort(rd.Host, rd.Port), Role: RoleReplica, Pool: pool})
+	}
+	if err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	})); err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
 
-	return db, nil
+// Check pings every node, a node is up when it answers within the context deadline
+func (c *Cluster) Check(ctx context.Context) []NodeStatus {
+	statuses := make([]NodeStatus, 0, len(c.Nodes))
+	for _, node := range c.Nodes {
+		start := time.Now()
+		err := node.Pool.PingContext(ctx)
+		stats := node.Pool.Stats()
+		status := NodeStatus{
+			Name:      node.Name,
+			Role:      node.Role,
+			Up:        err == nil,
+			LatencyMs: time.Since(start).Milliseconds(),
+			OpenConns: stats.OpenConnections,
+			InUse:     stats.InUse,
+		}
+		if err != nil {
+			status.Error = err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// OpenPrimary opens a pool on the primary alone, for tools like the migrator
+// that close the connection they are given
+func OpenPrimary(cfg config.PostgreSQL) (*sql.DB, error) {
+	return openPool(Init(cfg), cfg)
+}
+
+func openPool(d DBS, cfg config.PostgreSQL) (*sql.DB, error) {
+	pool, err := sql.Open("pgx", d.connectionString())
+	if err != nil {
+		return nil, err
+	}
+	pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	pool.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return pool, nil
 }
 
 func (d DBS) connectionString() string {
-	return fmt.Sprintf("host=%v port=%v dbname=%v user=%v search_path=%s password=%v sslmode=disable", d.Host, d.Port, d.Name, d.User, d.Schema, d.Password)
+	sslMode := d.SslMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	dsn := fmt.Sprintf("host=%v port=%v dbname=%v user=%v search_path=%s password=%v sslmode=%s",
+		d.Host, d.Port, d.Name, d.User, d.Schema, quoteDSN(d.Password), sslMode)
+	if d.SslRootCert != "" {
+		dsn += " sslrootcert=" + quoteDSN(d.SslRootCert)
+	}
+	if d.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" options='-c statement_timeout=%d'", d.StatementTimeout.Milliseconds())
+	}
+	return dsn
+}
+
+// quoteDSN quotes a keyword/value connection string value when it needs it
+func quoteDSN(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
 }
 
 func Init(cfg config.PostgreSQL) DBS {
 	return DBS{
-		Host:     cfg.Host,
-		Port:     cfg.Port,
-		Name:     cfg.Name,
-		User:     cfg.UserName,
-		Password: cfg.Password,
-		Schema:   cfg.Schema,
+		Host:             cfg.Host,
+		Port:             cfg.Port,
+		Name:             cfg.Name,
+		User:             cfg.UserName,
+		Password:         cfg.Password,
+		Schema:           cfg.Schema,
+		SslMode:          cfg.SslMode,
+		SslRootCert:      cfg.SslRootCert,
+		StatementTimeout: cfg.StatementTimeout,
 	}
 }
+
+// MigrationVersion reads the golang-migrate bookkeeping row from the primary
+func (c *Cluster) MigrationVersion(ctx context.Context) (*model.SchemaMigration, error) {
+	sm := Use(c.DB).SchemaMigration
+	version, err := sm.WithContext(ctx).WriteDB().First()
+	return version, TranslateError(err)
+}
@@ -0,0 +1,86 @@
+package dao
+
+import (
+	"errors"
+	"strings"
+
+	customerrors "booking.com/pkg/custom_errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+	pgNotNullViolation    = "23502"
+)
+
+var (
+	ErrNotFound = customerrors.NotFound(customerrors.CodeNotFound, "record not found")
+	ErrConflict = customerrors.Conflict("duplicate_record", "record already exists")
+	ErrInvalid  = customerrors.Validation("constraint_violation", "record violates a database constraint")
+)
+
+// TranslateError converts gorm and postgres errors into typed application errors.
+// Errors that are already typed or unknown are returned unchanged.
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound.Wrap(err)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrConflict.Wrap(err)
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	field := constraintField(pgErr)
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		appErr := ErrConflict.Wrap(err)
+		if field != "" {
+			appErr = appErr.WithMsg(field + " already exists").
+				WithDetails(customerrors.FieldError{Field: field, Message: "already exists"})
+		}
+		return appErr
+	case pgForeignKeyViolation:
+		return ErrInvalid.WithMsg("referenced record does not exist").Wrap(err)
+	case pgCheckViolation, pgNotNullViolation:
+		appErr := ErrInvalid.Wrap(err)
+		if field != "" {
+			appErr = appErr.WithDetails(customerrors.FieldError{Field: field, Message: "invalid value"})
+		}
+		return appErr
+	}
+	return err
+}
+
+// NotFoundAs translates err and replaces a generic not found error with the given one
+func NotFoundAs(err error, notFound *customerrors.AppError) error {
+	err = TranslateError(err)
+	if errors.Is(err, ErrNotFound) {
+		return notFound.Wrap(err)
+	}
+	return err
+}
+
+// constraintField derives the offending column from the postgres error,
+// e.g. users_email_key -> email
+func constraintField(pgErr *pgconn.PgError) string {
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+	name := pgErr.ConstraintName
+	if pgErr.TableName != "" {
+		name = strings.TrimPrefix(name, pgErr.TableName+"_")
+	}
+	for _, suffix := range []string{"_key", "_check", "_fkey"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return name
+}
@@ -16,64 +16,104 @@ import (
 )
 
 var (
-	Q               = new(Query)
-	Favorite        *favorite
-	Property        *property
-	PropertyPhoto   *propertyPhoto
-	Rating          *rating
-	SchemaMigration *schemaMigration
-	User            *user
-	Visit           *visit
+	Q                      = new(Query)
+	CalendarFeed           *calendarFeed
+	Favorite               *favorite
+	IdempotencyKey         *idempotencyKey
+	Job                    *job
+	Notification           *notification
+	NotificationPreference *notificationPreference
+	Outbox                 *outbox
+	Property               *property
+	PropertyPhoto          *propertyPhoto
+	Rating                 *rating
+	SchemaMigration        *schemaMigration
+	User                   *user
+	Visit                  *visit
+	Webhook                *webhook
+	WebhookDelivery        *webhookDelivery
 )
 
 func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 	*Q = *Use(db, opts...)
+	CalendarFeed = &Q.CalendarFeed
 	Favorite = &Q.Favorite
+	IdempotencyKey = &Q.IdempotencyKey
+	Job = &Q.Job
+	Notification = &Q.Notification
+	NotificationPreference = &Q.NotificationPreference
+	Outbox = &Q.Outbox
 	Property = &Q.Property
 	PropertyPhoto = &Q.PropertyPhoto
 	Rating = &Q.Rating
 	SchemaMigration = &Q.SchemaMigration
 	User = &Q.User
 	Visit = &Q.Visit
+	Webhook = &Q.Webhook
+	WebhookDelivery = &Q.WebhookDelivery
 }
 
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 	return &Query{
-		db:              db,
-		Favorite:        newFavorite(db, opts...),
-		Property:        newProperty(db, opts...),
-		PropertyPhoto:   newPropertyPhoto(db, opts...),
-		Rating:          newRating(db, opts...),
-		SchemaMigration: newSchemaMigration(db, opts...),
-		User:            newUser(db, opts...),
-		Visit:           newVisit(db, opts...),
+		db:                     db,
+		CalendarFeed:           newCalendarFeed(db, opts...),
+		Favorite:               newFavorite(db, opts...),
+		IdempotencyKey:         newIdempotencyKey(db, opts...),
+		Job:                    newJob(db, opts...),
+		Notification:           newNotification(db, opts...),
+		NotificationPreference: newNotificationPreference(db, opts...),
+		Outbox:                 newOutbox(db, opts...),
+		Property:               newProperty(db, opts...),
+		PropertyPhoto:          newPropertyPhoto(db, opts...),
+		Rating:                 newRating(db, opts...),
+		SchemaMigration:        newSchemaMigration(db, opts...),
+		User:                   newUser(db, opts...),
+		Visit:                  newVisit(db, opts...),
+		Webhook:                newWebhook(db, opts...),
+		WebhookDelivery:        newWebhookDelivery(db, opts...),
 	}
 }
 
 type Query struct {
 	db *gorm.DB
 
-	Favorite        favorite
-	Property        property
-	PropertyPhoto   propertyPhoto
-	Rating          rating
-	SchemaMigration schemaMigration
-	User            user
-	Visit           visit
+	CalendarFeed           calendarFeed
+	Favorite               favorite
+	IdempotencyKey         idempotencyKey
+	Job                    job
+	Notification           notification
+	NotificationPreference notificationPreference
+	Outbox                 outbox
+	Property               property
+	PropertyPhoto          propertyPhoto
+	Rating                 rating
+	SchemaMigration        schemaMigration
+	User                   user
+	Visit                  visit
+	Webhook                webhook
+	WebhookDelivery        webhookDelivery
 }
 
 func (q *Query) Available() bool { return q.db != nil }
 
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
-		db:              db,
-		Favorite:        q.Favorite.clone(db),
-		Property:        q.Property.clone(db),
-		PropertyPhoto:   q.PropertyPhoto.clone(db),
-		Rating:          q.Rating.clone(db),
-		SchemaMigration: q.SchemaMigration.clone(db),
-		User:            q.User.clone(db),
-		Visit:           q.Visit.clone(db),
+		db:                     db,
+		CalendarFeed:           q.CalendarFeed.clone(db),
+		Favorite:               q.Favorite.clone(db),
+		IdempotencyKey:         q.IdempotencyKey.clone(db),
+		Job:                    q.Job.clone(db),
+		Notification:           q.Notification.clone(db),
+		NotificationPreference: q.NotificationPreference.clone(db),
+		Outbox:                 q.Outbox.clone(db),
+		Property:               q.Property.clone(db),
+		PropertyPhoto:          q.PropertyPhoto.clone(db),
+		Rating:                 q.Rating.clone(db),
+		SchemaMigration:        q.SchemaMigration.clone(db),
+		User:                   q.User.clone(db),
+		Visit:                  q.Visit.clone(db),
+		Webhook:                q.Webhook.clone(db),
+		WebhookDelivery:        q.WebhookDelivery.clone(db),
 	}
 }
 
@@ -87,36 +127,60 @@ func (q *Query) WriteDB() *Query {
 
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
-		db:              db,
-		Favorite:        q.Favorite.replaceDB(db),
-		Property:        q.Property.replaceDB(db),
-		PropertyPhoto:   q.PropertyPhoto.replaceDB(db),
-		Rating:          q.Rating.replaceDB(db),
-		SchemaMigration: q.SchemaMigration.replaceDB(db),
-		User:            q.User.replaceDB(db),
-		Visit:           q.Visit.replaceDB(db),
+		db:                     db,
+		CalendarFeed:           q.CalendarFeed.replaceDB(db),
+		Favorite:               q.Favorite.replaceDB(db),
+		IdempotencyKey:         q.IdempotencyKey.replaceDB(db),
+		Job:                    q.Job.replaceDB(db),
+		Notification:           q.Notification.replaceDB(db),
+		NotificationPreference: q.NotificationPreference.replaceDB(db),
+		Outbox:                 q.Outbox.replaceDB(db),
+		Property:               q.Property.replaceDB(db),
+		PropertyPhoto:          q.PropertyPhoto.replaceDB(db),
+		Rating:                 q.Rating.replaceDB(db),
+		SchemaMigration:        q.SchemaMigration.replaceDB(db),
+		User:                   q.User.replaceDB(db),
+		Visit:                  q.Visit.replaceDB(db),
+		Webhook:                q.Webhook.replaceDB(db),
+		WebhookDelivery:        q.WebhookDelivery.replaceDB(db),
 	}
 }
 
 type queryCtx struct {
-	Favorite        *favoriteDo
-	Property        *propertyDo
-	PropertyPhoto   *propertyPhotoDo
-	Rating          *ratingDo
-	SchemaMigration *schemaMigrationDo
-	User            *userDo
-	Visit           *visitDo
+	CalendarFeed           *calendarFeedDo
+	Favorite               *favoriteDo
+	IdempotencyKey         *idempotencyKeyDo
+	Job                    *jobDo
+	Notification           *notificationDo
+	NotificationPreference *notificationPreferenceDo
+	Outbox                 *outboxDo
+	Property               *propertyDo
+	PropertyPhoto          *propertyPhotoDo
+	Rating                 *ratingDo
+	SchemaMigration        *schemaMigrationDo
+	User                   *userDo
+	Visit                  *visitDo
+	Webhook                *webhookDo
+	WebhookDelivery        *webhookDeliveryDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
-		Favorite:        q.Favorite.WithContext(ctx),
-		Property:        q.Property.WithContext(ctx),
-		PropertyPhoto:   q.PropertyPhoto.WithContext(ctx),
-		Rating:          q.Rating.WithContext(ctx),
-		SchemaMigration: q.SchemaMigration.WithContext(ctx),
-		User:            q.User.WithContext(ctx),
-		Visit:           q.Visit.WithContext(ctx),
+		CalendarFeed:           q.CalendarFeed.WithContext(ctx),
+		Favorite:               q.Favorite.WithContext(ctx),
+		IdempotencyKey:         q.IdempotencyKey.WithContext(ctx),
+		Job:                    q.Job.WithContext(ctx),
+		Notification:           q.Notification.WithContext(ctx),
+		NotificationPreference: q.NotificationPreference.WithContext(ctx),
+		Outbox:                 q.Outbox.WithContext(ctx),
+		Property:               q.Property.WithContext(ctx),
+		PropertyPhoto:          q.PropertyPhoto.WithContext(ctx),
+		Rating:                 q.Rating.WithContext(ctx),
+		SchemaMigration:        q.SchemaMigration.WithContext(ctx),
+		User:                   q.User.WithContext(ctx),
+		Visit:                  q.Visit.WithContext(ctx),
+		Webhook:                q.Webhook.WithContext(ctx),
+		WebhookDelivery:        q.WebhookDelivery.WithContext(ctx),
 	}
 }
 
@@ -43,6 +43,7 @@ func newProperty(db *gorm.DB, opts ...gen.DOOption) property {
 	_property.Deleted = field.NewBool(tableName, "deleted")
 	_property.CreatedAt = field.NewTime(tableName, "created_at")
 	_property.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_property.Version = field.NewInt32(tableName, "version")
 
 	_property.fillFieldMap()
 
@@ -69,6 +70,7 @@ type property struct {
 	Deleted         field.Bool
 	CreatedAt       field.Time
 	UpdatedAt       field.Time
+	Version         field.Int32
 
 	fieldMap map[string]field.Expr
 }
@@ -101,6 +103,7 @@ func (p *property) updateTableName(table string) *property {
 	p.Deleted = field.NewBool(table, "deleted")
 	p.CreatedAt = field.NewTime(table, "created_at")
 	p.UpdatedAt = field.NewTime(table, "updated_at")
+	p.Version = field.NewInt32(table, "version")
 
 	p.fillFieldMap()
 
@@ -117,7 +120,7 @@ func (p *property) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (p *property) fillFieldMap() {
-	p.fieldMap = make(map[string]field.Expr, 16)
+	p.fieldMap = make(map[string]field.Expr, 17)
 	p.fieldMap["id"] = p.ID
 	p.fieldMap["partner_username"] = p.PartnerUsername
 	p.fieldMap["title"] = p.Title
@@ -134,6 +137,7 @@ func (p *property) fillFieldMap() {
 	p.fieldMap["deleted"] = p.Deleted
 	p.fieldMap["created_at"] = p.CreatedAt
 	p.fieldMap["updated_at"] = p.UpdatedAt
+	p.fieldMap["version"] = p.Version
 }
 
 func (p property) clone(db *gorm.DB) property {
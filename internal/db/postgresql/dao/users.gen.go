@@ -44,6 +44,11 @@ func newUser(db *gorm.DB, opts ...gen.DOOption) user {
 	_user.Deleted = field.NewBool(tableName, "deleted")
 	_user.CreatedAt = field.NewTime(tableName, "created_at")
 	_user.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_user.NoShowCount = field.NewInt32(tableName, "no_show_count")
+	_user.BookingRestrictedUntil = field.NewTime(tableName, "booking_restricted_until")
+	_user.Version = field.NewInt32(tableName, "version")
+	_user.Locale = field.NewString(tableName, "locale")
+	_user.DeactivatedByAdmin = field.NewBool(tableName, "deactivated_by_admin")
 
 	_user.fillFieldMap()
 
@@ -53,24 +58,29 @@ func newUser(db *gorm.DB, opts ...gen.DOOption) user {
 type user struct {
 	userDo
 
-	ALL             field.Asterisk
-	Username        field.String
-	FirstName       field.String
-	LastName        field.String
-	Email           field.String
-	Phone           field.String
-	PasswordHash    field.String
-	Salt            field.String
-	ProfilePicURL   field.String
-	Address         field.String
-	Role            field.String
-	IsEmailVerified field.Bool
-	IsPhoneVerified field.Bool
-	RefreshToken    field.String
-	Rating          field.Float64
-	Deleted         field.Bool
-	CreatedAt       field.Time
-	UpdatedAt       field.Time
+	ALL                    field.Asterisk
+	Username               field.String
+	FirstName              field.String
+	LastName               field.String
+	Email                  field.String
+	Phone                  field.String
+	PasswordHash           field.String
+	Salt                   field.String
+	ProfilePicURL          field.String
+	Address                field.String
+	Role                   field.String
+	IsEmailVerified        field.Bool
+	IsPhoneVerified        field.Bool
+	RefreshToken           field.String
+	Rating                 field.Float64
+	Deleted                field.Bool
+	CreatedAt              field.Time
+	UpdatedAt              field.Time
+	NoShowCount            field.Int32
+	BookingRestrictedUntil field.Time
+	Version                field.Int32
+	Locale                 field.String
+	DeactivatedByAdmin     field.Bool
 
 	fieldMap map[string]field.Expr
 }
@@ -104,6 +114,11 @@ func (u *user) updateTableName(table string) *user {
 	u.Deleted = field.NewBool(table, "deleted")
 	u.CreatedAt = field.NewTime(table, "created_at")
 	u.UpdatedAt = field.NewTime(table, "updated_at")
+	u.NoShowCount = field.NewInt32(table, "no_show_count")
+	u.BookingRestrictedUntil = field.NewTime(table, "booking_restricted_until")
+	u.Version = field.NewInt32(table, "version")
+	u.Locale = field.NewString(table, "locale")
+	u.DeactivatedByAdmin = field.NewBool(table, "deactivated_by_admin")
 
 	u.fillFieldMap()
 
@@ -120,7 +135,7 @@ func (u *user) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (u *user) fillFieldMap() {
-	u.fieldMap = make(map[string]field.Expr, 17)
+	u.fieldMap = make(map[string]field.Expr, 22)
 	u.fieldMap["username"] = u.Username
 	u.fieldMap["first_name"] = u.FirstName
 	u.fieldMap["last_name"] = u.LastName
@@ -138,6 +153,11 @@ func (u *user) fillFieldMap() {
 	u.fieldMap["deleted"] = u.Deleted
 	u.fieldMap["created_at"] = u.CreatedAt
 	u.fieldMap["updated_at"] = u.UpdatedAt
+	u.fieldMap["no_show_count"] = u.NoShowCount
+	u.fieldMap["booking_restricted_until"] = u.BookingRestrictedUntil
+	u.fieldMap["version"] = u.Version
+	u.fieldMap["locale"] = u.Locale
+	u.fieldMap["deactivated_by_admin"] = u.DeactivatedByAdmin
 }
 
 func (u user) clone(db *gorm.DB) user {
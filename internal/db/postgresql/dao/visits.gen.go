@@ -38,6 +38,8 @@ func newVisit(db *gorm.DB, opts ...gen.DOOption) visit {
 	_visit.Deleted = field.NewBool(tableName, "deleted")
 	_visit.CreatedAt = field.NewTime(tableName, "created_at")
 	_visit.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_visit.Sequence = field.NewInt32(tableName, "sequence")
+	_visit.Version = field.NewInt32(tableName, "version")
 
 	_visit.fillFieldMap()
 
@@ -59,6 +61,8 @@ type visit struct {
 	Deleted        field.Bool
 	CreatedAt      field.Time
 	UpdatedAt      field.Time
+	Sequence       field.Int32
+	Version        field.Int32
 
 	fieldMap map[string]field.Expr
 }
@@ -86,6 +90,8 @@ func (v *visit) updateTableName(table string) *visit {
 	v.Deleted = field.NewBool(table, "deleted")
 	v.CreatedAt = field.NewTime(table, "created_at")
 	v.UpdatedAt = field.NewTime(table, "updated_at")
+	v.Sequence = field.NewInt32(table, "sequence")
+	v.Version = field.NewInt32(table, "version")
 
 	v.fillFieldMap()
 
@@ -102,7 +108,7 @@ func (v *visit) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (v *visit) fillFieldMap() {
-	v.fieldMap = make(map[string]field.Expr, 11)
+	v.fieldMap = make(map[string]field.Expr, 13)
 	v.fieldMap["id"] = v.ID
 	v.fieldMap["property_id"] = v.PropertyID
 	v.fieldMap["buyer_username"] = v.BuyerUsername
@@ -114,6 +120,8 @@ func (v *visit) fillFieldMap() {
 	v.fieldMap["deleted"] = v.Deleted
 	v.fieldMap["created_at"] = v.CreatedAt
 	v.fieldMap["updated_at"] = v.UpdatedAt
+	v.fieldMap["sequence"] = v.Sequence
+	v.fieldMap["version"] = v.Version
 }
 
 func (v visit) clone(db *gorm.DB) visit {
@@ -12,23 +12,28 @@ const TableNameUser = "users"
 
 // User mapped from table <users>
 type User struct {
-	Username        string    `gorm:"column:username;type:character varying(50);primaryKey" json:"username"`
-	FirstName       string    `gorm:"column:first_name;type:character varying(100);not null" json:"first_name"`
-	LastName        string    `gorm:"column:last_name;type:character varying(100);not null" json:"last_name"`
-	Email           string    `gorm:"column:email;type:character varying(100);not null" json:"email"`
-	Phone           string    `gorm:"column:phone;type:character varying(20)" json:"phone"`
-	PasswordHash    string    `gorm:"column:password_hash;type:character varying(255);not null" json:"password_hash"`
-	Salt            string    `gorm:"column:salt;type:character varying(100);not null" json:"salt"`
-	ProfilePicURL   string    `gorm:"column:profile_pic_url;type:text" json:"profile_pic_url"`
-	Address         string    `gorm:"column:address;type:character varying(255)" json:"address"`
-	Role            string    `gorm:"column:role;type:character varying(100);not null" json:"role"`
-	IsEmailVerified bool      `gorm:"column:is_email_verified;type:boolean" json:"is_email_verified"`
-	IsPhoneVerified bool      `gorm:"column:is_phone_verified;type:boolean" json:"is_phone_verified"`
-	RefreshToken    string    `gorm:"column:refresh_token;type:character varying(255)" json:"refresh_token"`
-	Rating          float64   `gorm:"column:rating;type:numeric(3,2)" json:"rating"`
-	Deleted         bool      `gorm:"column:deleted;type:boolean" json:"deleted"`
-	CreatedAt       time.Time `gorm:"column:created_at;type:timestamp without time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt       time.Time `gorm:"column:updated_at;type:timestamp without time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Username               string    `gorm:"column:username;type:character varying(50);primaryKey" json:"username"`
+	FirstName              string    `gorm:"column:first_name;type:character varying(100);not null" json:"first_name"`
+	LastName               string    `gorm:"column:last_name;type:character varying(100);not null" json:"last_name"`
+	Email                  string    `gorm:"column:email;type:character varying(100);not null" json:"email"`
+	Phone                  string    `gorm:"column:phone;type:character varying(20)" json:"phone"`
+	PasswordHash           string    `gorm:"column:password_hash;type:character varying(255);not null" json:"password_hash"`
+	Salt                   string    `gorm:"column:salt;type:character varying(100);not null" json:"salt"`
+	ProfilePicURL          string    `gorm:"column:profile_pic_url;type:text" json:"profile_pic_url"`
+	Address                string    `gorm:"column:address;type:character varying(255)" json:"address"`
+	Role                   string    `gorm:"column:role;type:character varying(100);not null" json:"role"`
+	IsEmailVerified        bool      `gorm:"column:is_email_verified;type:boolean" json:"is_email_verified"`
+	IsPhoneVerified        bool      `gorm:"column:is_phone_verified;type:boolean" json:"is_phone_verified"`
+	RefreshToken           string    `gorm:"column:refresh_token;type:character varying(255)" json:"refresh_token"`
+	Rating                 float64   `gorm:"column:rating;type:numeric(3,2)" json:"rating"`
+	Deleted                bool      `gorm:"column:deleted;type:boolean" json:"deleted"`
+	CreatedAt              time.Time `gorm:"column:created_at;type:timestamp without time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt              time.Time `gorm:"column:updated_at;type:timestamp without time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	NoShowCount            int32     `gorm:"column:no_show_count;type:integer;not null" json:"no_show_count"`
+	BookingRestrictedUntil time.Time `gorm:"column:booking_restricted_until;type:timestamp without time zone" json:"booking_restricted_until"`
+	Version                int32     `gorm:"column:version;type:integer;not null;default:1" json:"version"`
+	Locale                 string    `gorm:"column:locale;type:character varying(35);not null" json:"locale"`
+	DeactivatedByAdmin     bool      `gorm:"column:deactivated_by_admin;type:boolean;not null" json:"deactivated_by_admin"`
 }
 
 // TableName User's table name
@@ -23,6 +23,8 @@ type Visit struct {
 	Deleted        bool      `gorm:"column:deleted;type:boolean" json:"deleted"`
 	CreatedAt      time.Time `gorm:"column:created_at;type:timestamp without time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt      time.Time `gorm:"column:updated_at;type:timestamp without time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Sequence       int32     `gorm:"column:sequence;type:integer;not null" json:"sequence"`
+	Version        int32     `gorm:"column:version;type:integer;not null;default:1" json:"version"`
 }
 
 // TableName Visit's table name
@@ -0,0 +1,366 @@
+// Package seed fills a database with demo users, listings, visits and ratings.
+// The data is generated from a seed first so the same seed and sizes always
+// produce the same users, emails and listings, then inserted in one transaction
+package seed
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+
+	"github.com/google/uuid"
+)
+
+// Passwords of the seeded users, every user of a role shares it
+const (
+	AdminPassword   = "Admin#123"
+	PartnerPassword = "Partner#123"
+	BuyerPassword   = "Buyer#123"
+)
+
+// EmailDomain is the domain of every seeded email, it never receives mail
+const EmailDomain = "bookmylab.test"
+
+// VisitStatuses are cycled through so every status is seeded
+var VisitStatuses = []string{
+	constants.Pending, constants.Accepted, constants.Rejected, constants.Rescheduled,
+	constants.Completed, constants.Cancelled, constants.NoShow,
+}
+
+// Options are the seed and the sizes of the generated data
+type Options struct {
+	Seed       uint64
+	Admins     int
+	Partners   int
+	Buyers     int
+	Properties int
+	Visits     int
+}
+
+// Validate checks the sizes can hold every kind of row
+func (o Options) Validate() error {
+	switch {
+	case o.Admins < 0 || o.Partners < 1 || o.Buyers < 1:
+		return errors.New("seed needs at least one partner and one buyer")
+	case o.Properties < 1:
+		return errors.New("seed needs at least one property")
+	case o.Visits < 0:
+		return errors.New("visits can't be negative")
+	}
+	return nil
+}
+
+// Credential is the login of a seeded user
+type Credential struct {
+	Role     string
+	Username string
+	Email    string
+	Password string
+}
+
+// child is a row referencing a property by its index in Dataset.Properties,
+// the id is only known once the property is inserted
+type child[T any] struct {
+	property int
+	row      *T
+}
+
+// Dataset is the generated data, ready to insert
+type Dataset struct {
+	Users       []*model.User
+	Credentials []Credential
+	Properties  []*model.Property
+	photos      []child[model.PropertyPhoto]
+	favorites   []child[model.Favorite]
+	visits      []child[model.Visit]
+	ratings     []child[model.Rating]
+}
+
+// Summary counts the inserted rows
+type Summary struct {
+	Users, Properties, Photos, Favorites, Visits, Ratings int
+}
+
+type city struct {
+	name, state string
+	localities  []string
+}
+
+var (
+	cities = []city{
+		{"Mumbai", "Maharashtra", []string{"Bandra West", "Andheri East", "Powai", "Juhu", "Lower Parel"}},
+		{"Pune", "Maharashtra", []string{"Koregaon Park", "Baner", "Kothrud", "Viman Nagar", "Hinjewadi"}},
+		{"Bengaluru", "Karnataka", []string{"Indiranagar", "Koramangala", "Whitefield", "HSR Layout", "Jayanagar"}},
+		{"Hyderabad", "Telangana", []string{"Banjara Hills", "Gachibowli", "Jubilee Hills", "Madhapur", "Kondapur"}},
+		{"Chennai", "Tamil Nadu", []string{"Adyar", "Anna Nagar", "Velachery", "T. Nagar", "Besant Nagar"}},
+		{"New Delhi", "Delhi", []string{"Vasant Kunj", "Saket", "Dwarka", "Greater Kailash", "Hauz Khas"}},
+		{"Kolkata", "West Bengal", []string{"Salt Lake", "Ballygunge", "New Town", "Alipore", "Park Street"}},
+		{"Ahmedabad", "Gujarat", []string{"Satellite", "Bodakdev", "Prahlad Nagar", "Navrangpura", "Thaltej"}},
+		{"Jaipur", "Rajasthan", []string{"Malviya Nagar", "C-Scheme", "Vaishali Nagar", "Mansarovar", "Raja Park"}},
+		{"Kochi", "Kerala", []string{"Kakkanad", "Edappally", "Panampilly Nagar", "Fort Kochi", "Vyttila"}},
+	}
+	firstNames = []string{"Aarav", "Ananya", "Vihaan", "Diya", "Arjun", "Isha", "Kabir", "Meera", "Rohan", "Saanvi",
+		"Aditya", "Priya", "Karan", "Neha", "Rahul", "Pooja", "Siddharth", "Kavya", "Nikhil", "Riya"}
+	lastNames = []string{"Sharma", "Verma", "Iyer", "Nair", "Reddy", "Patel", "Gupta", "Mehta", "Kapoor", "Menon",
+		"Joshi", "Das", "Rao", "Singh", "Bose", "Pillai", "Desai", "Chopra", "Kulkarni", "Banerjee"}
+	features = []string{"a modular kitchen", "a private balcony", "covered parking", "24x7 security", "a rooftop garden",
+		"a clubhouse and pool", "power backup", "a park view", "east facing windows", "a gym in the society"}
+	reviews = []string{"", "Very responsive partner, the visit was on time.", "Good property, the photos match.",
+		"The partner answered every question.", "Visit started late but the flat was worth it.",
+		"Not as described, the listing needs an update.", "Smooth experience from booking to visit."}
+	propertyTypes = []string{constants.Apartment, constants.House, constants.Condo}
+)
+
+// Generate builds the dataset for opts, visit times are relative to now
+func Generate(opts Options, now time.Time) (*Dataset, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	var seed [32]byte
+	binary.LittleEndian.PutUint64(seed[:], opts.Seed)
+	source := rand.NewChaCha8(seed)
+	g := &generator{rng: rand.New(source), source: source, now: now.UTC(), ds: &Dataset{}, usernames: map[string]bool{}}
+
+	g.users(constants.AdminRole, "admin", AdminPassword, opts.Admins)
+	partners := g.users(constants.PartnerRole, "partner", PartnerPassword, opts.Partners)
+	buyers := g.users(constants.UserRole, "buyer", BuyerPassword, opts.Buyers)
+	for i := range opts.Properties {
+		g.property(partners[i%len(partners)])
+	}
+	for _, buyer := range buyers {
+		g.favorites(buyer)
+	}
+	for i := range opts.Visits {
+		g.visit(buyers[g.rng.IntN(len(buyers))], VisitStatuses[i%len(VisitStatuses)])
+	}
+	return g.ds, nil
+}
+
+type generator struct {
+	rng       *rand.Rand
+	source    *rand.ChaCha8
+	now       time.Time
+	ds        *Dataset
+	usernames map[string]bool
+}
+
+// users adds n users of role, emails are <prefix><n>@bookmylab.test so the
+// logins are known without reading the output
+func (g *generator) users(role, prefix, password string, n int) []*model.User {
+	users := make([]*model.User, 0, n)
+	for i := range n {
+		first, last := pick(g.rng, firstNames), pick(g.rng, lastNames)
+		// the same pattern the username trigger generates
+		username := fmt.Sprintf("%s_%s_%03x", short(first), short(last), g.rng.IntN(0x1000))
+		for g.usernames[username] {
+			username = fmt.Sprintf("%s_%s_%03x", short(first), short(last), g.rng.IntN(0x1000))
+		}
+		g.usernames[username] = true
+		salt, _ := uuid.NewRandomFromReader(g.source)
+		user := &model.User{
+			Username:        username,
+			FirstName:       first,
+			LastName:        last,
+			Email:           fmt.Sprintf("%s%d@%s", prefix, i+1, EmailDomain),
+			Phone:           fmt.Sprintf("+91%d%09d", 7+len(g.ds.Users)%3, len(g.ds.Users)+1),
+			Salt:            salt.String(),
+			Address:         pick(g.rng, cities).name + ", India",
+			Role:            role,
+			IsEmailVerified: true,
+			IsPhoneVerified: true,
+		}
+		g.ds.Users = append(g.ds.Users, user)
+		g.ds.Credentials = append(g.ds.Credentials, Credential{Role: role, Username: user.Username, Email: user.Email, Password: password})
+		users = append(users, user)
+	}
+	return users
+}
+
+func (g *generator) property(partner *model.User) {
+	c := pick(g.rng, cities)
+	locality := pick(g.rng, c.localities)
+	propertyType := pick(g.rng, propertyTypes)
+	bedrooms := int32(1 + g.rng.IntN(4))
+	if propertyType == constants.House {
+		bedrooms++
+	}
+	area := float64(450*bedrooms) + float64(g.rng.IntN(20))*25
+	index := len(g.ds.Properties)
+	g.ds.Properties = append(g.ds.Properties, &model.Property{
+		PartnerUsername: partner.Username,
+		Title:           fmt.Sprintf("%d BHK %s in %s", bedrooms, propertyType, locality),
+		Description:     fmt.Sprintf("Spacious %d BHK %s in %s, %s with %s and %s.", bedrooms, propertyType, locality, c.name, pick(g.rng, features), pick(g.rng, features)),
+		PropertyType:    propertyType,
+		Bedrooms:        bedrooms,
+		Bathrooms:       max(1, bedrooms-int32(g.rng.IntN(2))),
+		AreaSqft:        area,
+		// 4k to 20k a square foot, rounded to the thousand
+		Price:   math.Round(area*float64(4000+g.rng.IntN(16000))/1000) * 1000,
+		City:    c.name,
+		State:   c.state,
+		Address: fmt.Sprintf("%d, %s, %s", 1+g.rng.IntN(300), locality, c.name),
+		Status:  constants.Listed,
+	})
+	for i := range 1 + g.rng.IntN(4) {
+		g.ds.photos = append(g.ds.photos, child[model.PropertyPhoto]{property: index, row: &model.PropertyPhoto{
+			ImageURL:  fmt.Sprintf("https://picsum.photos/seed/bookmylab-%d-%d/1200/800", index+1, i+1),
+			IsPrimary: i == 0,
+		}})
+	}
+}
+
+// favorites saves up to three distinct listings for the buyer
+func (g *generator) favorites(buyer *model.User) {
+	saved := map[int]bool{}
+	for range g.rng.IntN(min(4, len(g.ds.Properties)+1)) {
+		index := g.rng.IntN(len(g.ds.Properties))
+		if saved[index] {
+			continue
+		}
+		saved[index] = true
+		g.ds.favorites = append(g.ds.favorites, child[model.Favorite]{property: index, row: &model.Favorite{UserUsername: buyer.Username}})
+	}
+}
+
+// visit adds a visit in status, visits that took place are in the past and a
+// completed visit is rated by its buyer
+func (g *generator) visit(buyer *model.User, status string) {
+	index := g.rng.IntN(len(g.ds.Properties))
+	property := g.ds.Properties[index]
+	hour := time.Duration(10+g.rng.IntN(8)) * time.Hour
+	day := g.now.Truncate(24 * time.Hour)
+	visit := &model.Visit{
+		BuyerUsername: buyer.Username,
+		Status:        status,
+		BuyerNote:     pick(g.rng, []string{"", "Can we see the parking too?", "Coming with family.", "Please share the exact location."}),
+	}
+	switch status {
+	case constants.Completed, constants.NoShow:
+		visit.ScheduledTime = day.Add(-time.Duration(1+g.rng.IntN(30))*24*time.Hour + hour)
+	default:
+		visit.ScheduledTime = day.Add(time.Duration(1+g.rng.IntN(21))*24*time.Hour + hour)
+	}
+	switch status {
+	case constants.Pending:
+	case constants.Rescheduled:
+		visit.RescheduleTime = visit.ScheduledTime.Add(time.Duration(1+g.rng.IntN(3)) * 24 * time.Hour)
+		visit.PartnerNote = "That slot is taken, does the new time work?"
+		visit.Sequence = 1
+	case constants.Rejected:
+		visit.PartnerNote = "The property is under maintenance that week."
+		visit.Sequence = 1
+	case constants.NoShow:
+		buyer.NoShowCount++
+		visit.Sequence = 2
+	case constants.Completed:
+		visit.Sequence = 2
+		g.ds.ratings = append(g.ds.ratings, child[model.Rating]{property: index, row: &model.Rating{
+			BuyerUsername:   buyer.Username,
+			PartnerUsername: property.PartnerUsername,
+			Rating:          int32(1 + g.rng.IntN(5)),
+			ReviewText:      pick(g.rng, reviews),
+		}})
+	default:
+		visit.Sequence = 1
+	}
+	g.ds.visits = append(g.ds.visits, child[model.Visit]{property: index, row: visit})
+}
+
+const batchSize = 100
+
+// Insert writes the dataset in one transaction, passwords are hashed the way
+// registration hashes them. It refuses to run twice on the same database
+func Insert(ctx context.Context, q *dao.Query, ds *Dataset) (*Summary, error) {
+	passwords := map[string]string{}
+	for _, cred := range ds.Credentials {
+		passwords[cred.Username] = cred.Password
+	}
+	for _, user := range ds.Users {
+		hash, err := utils.HashPassword(passwords[user.Username] + user.Salt)
+		if err != nil {
+			return nil, err
+		}
+		user.PasswordHash = hash
+	}
+	if len(ds.Users) > 0 {
+		usr := q.User
+		count, err := usr.WithContext(ctx).Where(usr.Email.Eq(ds.Users[0].Email)).Count()
+		if err != nil {
+			return nil, dao.TranslateError(err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("the database already holds seeded users, %s exists", ds.Users[0].Email)
+		}
+	}
+	err := q.Transaction(func(tx *dao.Query) error {
+		if err := tx.User.WithContext(ctx).CreateInBatches(ds.Users, batchSize); err != nil {
+			return err
+		}
+		if err := tx.Property.WithContext(ctx).CreateInBatches(ds.Properties, batchSize); err != nil {
+			return err
+		}
+		if photos := rows(ds.Properties, ds.photos, func(p *model.PropertyPhoto, id int64) { p.PropertyID = id }); len(photos) > 0 {
+			if err := tx.PropertyPhoto.WithContext(ctx).CreateInBatches(photos, batchSize); err != nil {
+				return err
+			}
+		}
+		if favorites := rows(ds.Properties, ds.favorites, func(f *model.Favorite, id int64) { f.PropertyID = id }); len(favorites) > 0 {
+			if err := tx.Favorite.WithContext(ctx).CreateInBatches(favorites, batchSize); err != nil {
+				return err
+			}
+		}
+		if visits := rows(ds.Properties, ds.visits, func(v *model.Visit, id int64) { v.PropertyID = id }); len(visits) > 0 {
+			if err := tx.Visit.WithContext(ctx).CreateInBatches(visits, batchSize); err != nil {
+				return err
+			}
+		}
+		// one insert per rating so the trigger averages the partner rating as the api would
+		for _, rating := range rows(ds.Properties, ds.ratings, func(r *model.Rating, id int64) { r.PropertyID = id }) {
+			if err := tx.Rating.WithContext(ctx).Create(rating); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, dao.TranslateError(err)
+	}
+	return &Summary{
+		Users:      len(ds.Users),
+		Properties: len(ds.Properties),
+		Photos:     len(ds.photos),
+		Favorites:  len(ds.favorites),
+		Visits:     len(ds.visits),
+		Ratings:    len(ds.ratings),
+	}, nil
+}
+
+// rows sets the property id of every child row
+func rows[T any](properties []*model.Property, children []child[T], setID func(*T, int64)) []*T {
+	out := make([]*T, 0, len(children))
+	for _, c := range children {
+		setID(c.row, properties[c.property].ID)
+		out = append(out, c.row)
+	}
+	return out
+}
+
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.IntN(len(values))]
+}
+
+// short is the lowercase first four letters of a name, as the username trigger takes them
+func short(name string) string {
+	name = strings.ToLower(name)
+	return name[:min(4, len(name))]
+}
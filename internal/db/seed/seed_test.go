@@ -0,0 +1,68 @@
+package seed
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"booking.com/pkg/constants"
+)
+
+func TestGenerate(t *testing.T) {
+	opts := Options{Seed: 42, Admins: 1, Partners: 3, Buyers: 6, Properties: 12, Visits: 21}
+	now := time.Date(2025, time.March, 2, 10, 30, 0, 0, time.UTC)
+	ds, err := Generate(opts, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Users) != 10 || len(ds.Properties) != 12 || len(ds.visits) != 21 {
+		t.Fatalf("got %d users, %d properties, %d visits", len(ds.Users), len(ds.Properties), len(ds.visits))
+	}
+	again, _ := Generate(opts, now)
+	if !reflect.DeepEqual(ds, again) {
+		t.Error("the same seed must generate the same data")
+	}
+	other, _ := Generate(Options{Seed: 7, Admins: 1, Partners: 3, Buyers: 6, Properties: 12, Visits: 21}, now)
+	if reflect.DeepEqual(ds.Users, other.Users) {
+		t.Error("another seed must generate other users")
+	}
+
+	usernames := map[string]bool{}
+	for _, user := range ds.Users {
+		if usernames[user.Username] {
+			t.Errorf("duplicate username %s", user.Username)
+		}
+		usernames[user.Username] = true
+	}
+	if ds.Credentials[0].Email != "admin1@"+EmailDomain || ds.Credentials[1].Email != "partner1@"+EmailDomain {
+		t.Errorf("unexpected logins %+v", ds.Credentials[:2])
+	}
+
+	statuses := map[string]int{}
+	for _, visit := range ds.visits {
+		statuses[visit.row.Status]++
+		past := visit.row.ScheduledTime.Before(now)
+		if ended := visit.row.Status == constants.Completed || visit.row.Status == constants.NoShow; past != ended {
+			t.Errorf("%s visit scheduled at %v", visit.row.Status, visit.row.ScheduledTime)
+		}
+	}
+	for _, status := range VisitStatuses {
+		if statuses[status] != 3 {
+			t.Errorf("got %d %s visits, want 3", statuses[status], status)
+		}
+	}
+	if len(ds.ratings) != statuses[constants.Completed] {
+		t.Errorf("got %d ratings, want one per completed visit", len(ds.ratings))
+	}
+	for _, rating := range ds.ratings {
+		if rating.row.PartnerUsername != ds.Properties[rating.property].PartnerUsername || rating.row.Rating < 1 || rating.row.Rating > 5 {
+			t.Errorf("invalid rating %+v", rating.row)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if _, err := Generate(Options{Partners: 1, Buyers: 1}, time.Now()); err == nil {
+		t.Error("a seed without properties must be rejected")
+	}
+}
@@ -3,3 +3,18 @@ package dto
 type TokenReq struct {
 	AccessToken string `json:"access_token" binding:"required"`
 }
+
+type TokenRsp struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// CsrfToken goes in the X-CSRF-Token header of refresh and logout, it is also set as the csrf_token cookie
+	CsrfToken string `json:"csrf_token,omitempty"`
+	// RefreshToken is only returned to native clients, which get no cookies
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// RefreshReq is how native clients send their refresh token to refresh and
+// logout, the Authorization header works too
+type RefreshReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
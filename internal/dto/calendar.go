@@ -0,0 +1,13 @@
+package dto
+
+// CalendarFeedRsp holds the private feed urls, they are only shown when the
+// feed is created so a lost url is replaced by creating the feed again
+type CalendarFeedRsp struct {
+	URL       string `json:"url"`
+	WebcalURL string `json:"webcal_url"`
+}
+
+type GetCalendarFeed struct {
+	// Token may end in .ics, some calendar apps only subscribe to such urls
+	Token string `uri:"token" binding:"required,max=100"`
+}
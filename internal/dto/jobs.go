@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+)
+
+type JobFilterReq struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending running succeeded dead cancelled"`
+	Kind   string `form:"kind" binding:"max=100"`
+	PageReq
+}
+
+type GetJob struct {
+	ID int64 `uri:"id" binding:"required,gt=0"`
+}
+
+type JobRsp struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	UniqueKey   string          `json:"unique_key,omitempty"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	LastError   string          `json:"last_error,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func NewJobRsp(job *model.Job) *JobRsp {
+	rsp := &JobRsp{
+		ID:          job.ID,
+		Kind:        job.Kind,
+		Payload:     json.RawMessage(job.Payload),
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+	if job.UniqueKey != nil {
+		rsp.UniqueKey = *job.UniqueKey
+	}
+	if !job.FinishedAt.IsZero() {
+		rsp.FinishedAt = &job.FinishedAt
+	}
+	return rsp
+}
+
+func NewJobRspList(jobs []*model.Job) []*JobRsp {
+	rsps := make([]*JobRsp, 0, len(jobs))
+	for _, job := range jobs {
+		rsps = append(rsps, NewJobRsp(job))
+	}
+	return rsps
+}
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+)
+
+type NotificationFilterReq struct {
+	Type       string `form:"type" binding:"omitempty,oneof=visit_requested visit_accepted visit_rejected visit_rescheduled visit_cancelled visit_completed visit_no_show visit_reminder visit_follow_up review_received price_drop"`
+	UnreadOnly bool   `form:"unread_only"`
+	PageReq
+}
+
+type GetNotification struct {
+	ID int64 `uri:"id" binding:"required,gt=0"`
+}
+
+type NotificationRsp struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Data      json.RawMessage `json:"data"`
+	Read      bool            `json:"read"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type UnreadCountRsp struct {
+	Unread int64 `json:"unread"`
+}
+
+type MarkAllReadRsp struct {
+	Updated int64 `json:"updated"`
+}
+
+// NotificationPreference sets the channels a notification type is delivered on
+type NotificationPreference struct {
+	Type  string `json:"type" binding:"required,oneof=visit_requested visit_accepted visit_rejected visit_rescheduled visit_cancelled visit_completed visit_no_show visit_reminder visit_follow_up review_received price_drop"`
+	Inbox bool   `json:"inbox"`
+	Email bool   `json:"email"`
+	Sms   bool   `json:"sms"`
+}
+
+// UpdatePreferencesReq sets the preferences of the listed types, a type can
+// only be listed once
+type UpdatePreferencesReq struct {
+	Preferences []NotificationPreference `json:"preferences" binding:"required,min=1,unique=Type,dive"`
+}
+
+func NewNotificationRsp(notification *model.Notification) *NotificationRsp {
+	rsp := &NotificationRsp{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Data:      json.RawMessage(notification.Data),
+		CreatedAt: notification.CreatedAt,
+	}
+	if !notification.ReadAt.IsZero() {
+		rsp.Read = true
+		rsp.ReadAt = &notification.ReadAt
+	}
+	return rsp
+}
+
+func NewNotificationRspList(notifications []*model.Notification) []*NotificationRsp {
+	rsps := make([]*NotificationRsp, 0, len(notifications))
+	for _, notification := range notifications {
+		rsps = append(rsps, NewNotificationRsp(notification))
+	}
+	return rsps
+}
+
+func NewPreferenceRspList(prefs []*model.NotificationPreference) []NotificationPreference {
+	rsps := make([]NotificationPreference, 0, len(prefs))
+	for _, pref := range prefs {
+		rsps = append(rsps, NotificationPreference{Type: pref.Type, Inbox: pref.Inbox, Email: pref.Email, Sms: pref.Sms})
+	}
+	return rsps
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// PatchField is a member of a JSON Merge Patch (RFC 7396). A member left out
+// is not present, null is present and clears the value. A value of the wrong
+// type is kept as invalid for the validator, which knows the member name
+type PatchField[T any] struct {
+	present bool
+	null    bool
+	invalid bool
+	value   T
+}
+
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	f.present, f.null, f.invalid, f.value = true, bytes.Equal(data, []byte("null")), false, zero
+	if !f.null && json.Unmarshal(data, &f.value) != nil {
+		f.invalid, f.value = true, zero
+	}
+	return nil
+}
+
+func (f PatchField[T]) Present() bool {
+	return f.present
+}
+
+func (f PatchField[T]) IsNull() bool {
+	return f.null
+}
+
+func (f PatchField[T]) Valid() bool {
+	return !f.invalid
+}
+
+func (f PatchField[T]) Get() any {
+	return f.value
+}
+
+// apply copies a present member into dst, null writes the zero value, and
+// records the column it belongs to
+func (f PatchField[T]) apply(dst *T, column string, columns *[]string) {
+	if !f.present {
+		return
+	}
+	*dst = f.value
+	*columns = append(*columns, column)
+}
@@ -1,43 +1,130 @@
 package dto
 
+import (
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+)
+
 type AddPropertyReq struct {
-	Title        string  `gorm:"column:title;type:character varying(200);not null" json:"title"`
-	Description  string  `gorm:"column:description;type:text" json:"description"`
-	PropertyType string  `gorm:"column:property_type;type:property_type_enum;not null" json:"property_type"`
-	Bedrooms     int32   `gorm:"column:bedrooms;type:integer" json:"bedrooms"`
-	Bathrooms    int32   `gorm:"column:bathrooms;type:integer" json:"bathrooms"`
-	AreaSqft     float64 `gorm:"column:area_sqft;type:numeric(10,2)" json:"area_sqft"`
-	Price        float64 `gorm:"column:price;type:numeric(12,2)" json:"price"`
-	City         string  `gorm:"column:city;type:character varying(100)" json:"city"`
-	State        string  `gorm:"column:state;type:character varying(100)" json:"state"`
+	Title        string  `gorm:"column:title;type:character varying(200);not null" json:"title" binding:"required,max=200"`
+	Description  string  `gorm:"column:description;type:text" json:"description" binding:"max=5000"`
+	PropertyType string  `gorm:"column:property_type;type:property_type_enum;not null" json:"property_type" binding:"required,property_type"`
+	Bedrooms     int32   `gorm:"column:bedrooms;type:integer" json:"bedrooms" binding:"min=0,max=100"`
+	Bathrooms    int32   `gorm:"column:bathrooms;type:integer" json:"bathrooms" binding:"min=0,max=100"`
+	AreaSqft     float64 `gorm:"column:area_sqft;type:numeric(10,2)" json:"area_sqft" binding:"money"`
+	Price        float64 `gorm:"column:price;type:numeric(12,2)" json:"price" binding:"required,money"`
+	City         string  `gorm:"column:city;type:character varying(100)" json:"city" binding:"max=100"`
+	State        string  `gorm:"column:state;type:character varying(100)" json:"state" binding:"max=100"`
 	Address      string  `gorm:"column:address;type:text" json:"address"`
 }
 type UpdatePropertyReq struct {
-	ID           int64   `gorm:"column:id;type:bigint;primaryKey;autoIncrement:true" json:"id"`
-	Title        string  `gorm:"column:title;type:character varying(200);not null" json:"title"`
-	Description  string  `gorm:"column:description;type:text" json:"description"`
-	PropertyType string  `gorm:"column:property_type;type:property_type_enum;not null" json:"property_type"`
-	Bedrooms     int32   `gorm:"column:bedrooms;type:integer" json:"bedrooms"`
-	Bathrooms    int32   `gorm:"column:bathrooms;type:integer" json:"bathrooms"`
-	AreaSqft     float64 `gorm:"column:area_sqft;type:numeric(10,2)" json:"area_sqft"`
-	Price        float64 `gorm:"column:price;type:numeric(12,2)" json:"price"`
-	City         string  `gorm:"column:city;type:character varying(100)" json:"city"`
-	State        string  `gorm:"column:state;type:character varying(100)" json:"state"`
+	ID           int64   `gorm:"column:id;type:bigint;primaryKey;autoIncrement:true" json:"id" binding:"required,gt=0"`
+	Title        string  `gorm:"column:title;type:character varying(200);not null" json:"title" binding:"max=200"`
+	Description  string  `gorm:"column:description;type:text" json:"description" binding:"max=5000"`
+	PropertyType string  `gorm:"column:property_type;type:property_type_enum;not null" json:"property_type" binding:"omitempty,property_type"`
+	Bedrooms     int32   `gorm:"column:bedrooms;type:integer" json:"bedrooms" binding:"min=0,max=100"`
+	Bathrooms    int32   `gorm:"column:bathrooms;type:integer" json:"bathrooms" binding:"min=0,max=100"`
+	AreaSqft     float64 `gorm:"column:area_sqft;type:numeric(10,2)" json:"area_sqft" binding:"money"`
+	Price        float64 `gorm:"column:price;type:numeric(12,2)" json:"price" binding:"money"`
+	City         string  `gorm:"column:city;type:character varying(100)" json:"city" binding:"max=100"`
+	State        string  `gorm:"column:state;type:character varying(100)" json:"state" binding:"max=100"`
 	Address      string  `gorm:"column:address;type:text" json:"address"`
 }
 
+// PatchPropertyReq is a JSON Merge Patch of a property, members left out keep
+// their value and null clears the ones that may be empty
+type PatchPropertyReq struct {
+	Title        PatchField[string]  `json:"title" patch:"required,max=200"`
+	Description  PatchField[string]  `json:"description" patch:"max=5000"`
+	PropertyType PatchField[string]  `json:"property_type" patch:"required,property_type"`
+	Bedrooms     PatchField[int32]   `json:"bedrooms" patch:"min=0,max=100"`
+	Bathrooms    PatchField[int32]   `json:"bathrooms" patch:"min=0,max=100"`
+	AreaSqft     PatchField[float64] `json:"area_sqft" patch:"money"`
+	Price        PatchField[float64] `json:"price" patch:"required,money"`
+	City         PatchField[string]  `json:"city" patch:"max=100"`
+	State        PatchField[string]  `json:"state" patch:"max=100"`
+	Address      PatchField[string]  `json:"address"`
+}
+
+// Apply writes the present members into property and returns their columns
+func (r PatchPropertyReq) Apply(property *model.Property) []string {
+	var columns []string
+	r.Title.apply(&property.Title, "title", &columns)
+	r.Description.apply(&property.Description, "description", &columns)
+	r.PropertyType.apply(&property.PropertyType, "property_type", &columns)
+	r.Bedrooms.apply(&property.Bedrooms, "bedrooms", &columns)
+	r.Bathrooms.apply(&property.Bathrooms, "bathrooms", &columns)
+	r.AreaSqft.apply(&property.AreaSqft, "area_sqft", &columns)
+	r.Price.apply(&property.Price, "price", &columns)
+	r.City.apply(&property.City, "city", &columns)
+	r.State.apply(&property.State, "state", &columns)
+	r.Address.apply(&property.Address, "address", &columns)
+	return columns
+}
+
 type GetProperty struct {
-	ID int64 `uri:"id" binding:"required"`
+	ID int64 `uri:"id" binding:"required,gt=0"`
 }
 
 type PropertFilterReq struct {
 	Id          int64   `form:"id"`
 	Title       string  `form:"property_name"`
 	PartnerName string  `form:"partner_name"`
-	From_Price  float64 `form:"from_price"`
-	To_Price    float64 `form:"to_price"`
+	From_Price  float64 `form:"from_price" binding:"money"`
+	To_Price    float64 `form:"to_price" binding:"money"`
 	City        string  `form:"city"`
 	State       string  `form:"state"`
-	Status      string  `form:"status"`
+	Status      string  `form:"status" binding:"omitempty,oneof=listed unlisted booked"`
 	ExcludeSelf bool    `form:"exclude_self"`
+	PageReq
+}
+
+type PropertyRsp struct {
+	ID              int64     `json:"id"`
+	PartnerUsername string    `json:"partner_username"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	PropertyType    string    `json:"property_type"`
+	Bedrooms        int32     `json:"bedrooms"`
+	Bathrooms       int32     `json:"bathrooms"`
+	AreaSqft        float64   `json:"area_sqft"`
+	Price           float64   `json:"price"`
+	City            string    `json:"city"`
+	State           string    `json:"state"`
+	Address         string    `json:"address"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	// Version is the ETag of the property, send it back in If-Match to update it
+	Version int32 `json:"version"`
+}
+
+func NewPropertyRsp(property *model.Property) *PropertyRsp {
+	return &PropertyRsp{
+		ID:              property.ID,
+		PartnerUsername: property.PartnerUsername,
+		Title:           property.Title,
+		Description:     property.Description,
+		PropertyType:    property.PropertyType,
+		Bedrooms:        property.Bedrooms,
+		Bathrooms:       property.Bathrooms,
+		AreaSqft:        property.AreaSqft,
+		Price:           property.Price,
+		City:            property.City,
+		State:           property.State,
+		Address:         property.Address,
+		Status:          property.Status,
+		CreatedAt:       property.CreatedAt,
+		UpdatedAt:       property.UpdatedAt,
+		Version:         property.Version,
+	}
+}
+
+func NewPropertyRspList(properties []*model.Property) []*PropertyRsp {
+	rsp := make([]*PropertyRsp, 0, len(properties))
+	for _, property := range properties {
+		rsp = append(rsp, NewPropertyRsp(property))
+	}
+	return rsp
 }
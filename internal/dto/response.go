@@ -0,0 +1,63 @@
+package dto
+
+import (
+	customerrors "booking.com/pkg/custom_errors"
+)
+
+// Envelope is the /v2 success response, data holds the payload as a nested JSON value
+type Envelope struct {
+	Status  string    `json:"status"`
+	Message string    `json:"message,omitempty"`
+	Data    any       `json:"data,omitempty"`
+	Meta    *PageMeta `json:"meta,omitempty"`
+}
+
+// Problem is an RFC 7807 problem details document used for /v2 errors
+type Problem struct {
+	Type     string                    `json:"type"`
+	Title    string                    `json:"title"`
+	Status   int                       `json:"status"`
+	Detail   string                    `json:"detail,omitempty"`
+	Instance string                    `json:"instance,omitempty"`
+	Code     string                    `json:"code"`
+	Errors   []customerrors.FieldError `json:"errors,omitempty"`
+}
+
+type PageReq struct {
+	Page  int `form:"page" binding:"min=0"`
+	Limit int `form:"limit" binding:"min=0,max=100"`
+}
+
+// Normalize fills in defaults, a zero limit after normalizing means no pagination
+func (p *PageReq) Normalize(defaultLimit int) {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.Limit < 1 {
+		p.Limit = defaultLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+}
+
+func (p PageReq) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+const MaxPageLimit = 100
+
+type PageMeta struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+func NewPageMeta(page PageReq, total int64) *PageMeta {
+	meta := &PageMeta{Page: page.Page, Limit: page.Limit, Total: total, TotalPages: 1}
+	if page.Limit > 0 {
+		meta.TotalPages = (total + int64(page.Limit) - 1) / int64(page.Limit)
+	}
+	return meta
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+)
+
+type CreateReviewReq struct {
+	Rating     int32  `json:"rating" binding:"required,min=1,max=5"`
+	ReviewText string `json:"review_text" binding:"max=2000"`
+}
+
+type ReviewRsp struct {
+	ID            int64     `json:"id"`
+	PropertyID    int64     `json:"property_id"`
+	BuyerUsername string    `json:"buyer_username"`
+	Rating        int32     `json:"rating"`
+	ReviewText    string    `json:"review_text,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func NewReviewRsp(rating *model.Rating) *ReviewRsp {
+	return &ReviewRsp{
+		ID:            rating.ID,
+		PropertyID:    rating.PropertyID,
+		BuyerUsername: rating.BuyerUsername,
+		Rating:        rating.Rating,
+		ReviewText:    rating.ReviewText,
+		CreatedAt:     rating.CreatedAt,
+	}
+}
@@ -0,0 +1,8 @@
+package dto
+
+// StreamReq opens an event stream, the Last-Event-ID header takes precedence
+// over LastEventID. AccessToken replaces the Authorization header for browsers
+type StreamReq struct {
+	LastEventID int64  `form:"last_event_id" binding:"min=0"`
+	AccessToken string `form:"access_token"`
+}
@@ -1,22 +1,52 @@
 package dto
 
+import (
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+)
+
 type Login struct {
-	UserName string `json:"username"`
-	Password string `json:"password"`
+	UserName string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	// Native clients get the refresh token in the response instead of a cookie
+	Native bool `json:"native"`
 }
 type CreateUser struct {
-	FirstName string `gorm:"column:first_name;type:character varying(100);not null" json:"first_name"`
-	LastName  string `gorm:"column:last_name;type:character varying(100);not null" json:"last_name"`
-	Email     string `gorm:"column:email;type:character varying(100);not null" json:"email"`
-	Phone     string `gorm:"column:phone;type:character varying(20)" json:"phone"`
-	Password  string `gorm:"column:password;type:character varying(255)" json:"password"`
-	Address   string `gorm:"column:address;type:character varying(255);not null" json:"address"`
+	FirstName string `gorm:"column:first_name;type:character varying(100);not null" json:"first_name" binding:"required,max=100"`
+	LastName  string `gorm:"column:last_name;type:character varying(100);not null" json:"last_name" binding:"required,max=100"`
+	Email     string `gorm:"column:email;type:character varying(100);not null" json:"email" binding:"required,email_addr,max=100"`
+	Phone     string `gorm:"column:phone;type:character varying(20)" json:"phone" binding:"required,phone_e164"`
+	Password  string `gorm:"column:password;type:character varying(255)" json:"password" binding:"required,strong_password,max=64"`
+	Address   string `gorm:"column:address;type:character varying(255);not null" json:"address" binding:"max=255"`
+	// Locale is taken from the Accept-Language of the registration request
+	Locale string `json:"-"`
 }
 
 type UpdateUser struct {
-	FirstName string `gorm:"column:first_name;type:character varying(100);not null" json:"first_name"`
-	LastName  string `gorm:"column:last_name;type:character varying(100);not null" json:"last_name"`
-	Address   string `gorm:"column:address;type:character varying(255);not null" json:"address"`
+	FirstName string `gorm:"column:first_name;type:character varying(100);not null" json:"first_name" binding:"max=100"`
+	LastName  string `gorm:"column:last_name;type:character varying(100);not null" json:"last_name" binding:"max=100"`
+	Address   string `gorm:"column:address;type:character varying(255);not null" json:"address" binding:"max=255"`
+}
+
+// PatchUser is a JSON Merge Patch of the current user, members left out keep
+// their value and null clears the address or the locale
+type PatchUser struct {
+	FirstName PatchField[string] `json:"first_name" patch:"required,max=100"`
+	LastName  PatchField[string] `json:"last_name" patch:"required,max=100"`
+	Address   PatchField[string] `json:"address" patch:"max=255"`
+	// Locale is the language mails are sent in, e.g. en-GB
+	Locale PatchField[string] `json:"locale" patch:"max=35,bcp47_language_tag"`
+}
+
+// Apply writes the present members into user and returns their columns
+func (r PatchUser) Apply(user *model.User) []string {
+	var columns []string
+	r.FirstName.apply(&user.FirstName, "first_name", &columns)
+	r.LastName.apply(&user.LastName, "last_name", &columns)
+	r.Address.apply(&user.Address, "address", &columns)
+	r.Locale.apply(&user.Locale, "locale", &columns)
+	return columns
 }
 
 type APIResponse struct {
@@ -24,13 +54,71 @@ type APIResponse struct {
 	Message string `json:"message,omitempty"` // human-readable message
 	Data    any    `json:"data,omitempty"`    // actual response payload
 	Error   any    `json:"error,omitempty"`   // optional detailed error info
+	Code    string `json:"code,omitempty"`    // machine-readable error code
+	Details any    `json:"details,omitempty"` // per field validation errors
 }
 
 type UserRoleReq struct {
-	UserName string `json:"username"`
-	Role     string `gorm:"column:role;type:character varying(100);not null" json:"role"`
+	UserName string `json:"username" binding:"required"`
+	Role     string `gorm:"column:role;type:character varying(100);not null" json:"role" binding:"required,oneof=user partner admin"`
 }
 
 type Activate struct {
-	UserName string `json:"username"`
+	UserName string `json:"username" binding:"required"`
+}
+
+// UserRsp is the public view of a user, it never carries credentials or tokens
+type UserRsp struct {
+	Username        string  `json:"username"`
+	FirstName       string  `json:"first_name"`
+	LastName        string  `json:"last_name"`
+	Email           string  `json:"email"`
+	Phone           string  `json:"phone"`
+	ProfilePicURL   string  `json:"profile_pic_url,omitempty"`
+	Address         string  `json:"address"`
+	Role            string  `json:"role"`
+	Locale          string  `json:"locale,omitempty"`
+	IsEmailVerified bool    `json:"is_email_verified"`
+	IsPhoneVerified bool    `json:"is_phone_verified"`
+	Rating          float64 `json:"rating"`
+	NoShowCount     int32   `json:"no_show_count"`
+	// BookingRestrictedUntil is set while repeated no-shows keep the user from booking visits
+	BookingRestrictedUntil *time.Time `json:"booking_restricted_until,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+	// Version is the ETag of the user, send it back in If-Match to update it
+	Version int32 `json:"version"`
+}
+
+func NewUserRsp(user *model.User) *UserRsp {
+	rsp := &UserRsp{
+		Username:        user.Username,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Email:           user.Email,
+		Phone:           user.Phone,
+		ProfilePicURL:   user.ProfilePicURL,
+		Address:         user.Address,
+		Role:            user.Role,
+		Locale:          user.Locale,
+		IsEmailVerified: user.IsEmailVerified,
+		IsPhoneVerified: user.IsPhoneVerified,
+		Rating:          user.Rating,
+		NoShowCount:     user.NoShowCount,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		Version:         user.Version,
+	}
+	if user.BookingRestrictedUntil.After(time.Now()) {
+		rsp.BookingRestrictedUntil = &user.BookingRestrictedUntil
+	}
+	return rsp
+}
+
+func NewUserRspList(users []*model.User) []*UserRsp {
+	rsp := make([]*UserRsp, 0, len(users))
+	for _, user := range users {
+		rsp = append(rsp, NewUserRsp(user))
+	}
+	return rsp
 }
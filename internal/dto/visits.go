@@ -1,17 +1,76 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+)
 
 type ScheduleReq struct {
-	PropertyID    int64 `json:"property_id"`
-	BuyerUsername string
-	ScheduledTime time.Time `json:"scheduled_time"`
-	BuyerNote     string    `json:"buyer_note"`
+	PropertyID    int64     `json:"property_id" binding:"required,gt=0"`
+	BuyerUsername string    `json:"-"`
+	ScheduledTime time.Time `json:"scheduled_time" binding:"required,future"`
+	BuyerNote     string    `json:"buyer_note" binding:"max=1000"`
+}
+
+type UpdateVisitReq struct {
+	ID             int64      `json:"id" binding:"required,gt=0"`
+	Status         string     `json:"status" binding:"omitempty,oneof=accepted rejected rescheduled completed cancelled no_show"`
+	RescheduleTime *time.Time `json:"reschedule_time" binding:"omitempty,future"`
+	PartnerNote    string     `json:"partner_note" binding:"max=1000"`
+	BuyerNote      string     `json:"buyer_note" binding:"max=1000"`
+}
+
+type GetVisit struct {
+	ID int64 `uri:"id" binding:"required,gt=0"`
 }
 
 type VisitFilterReq struct {
-	Status          string
-	PropertyID      int64
-	BuyersUserName  string
-	PartnerUserName string
+	Status          string `form:"status" binding:"omitempty,oneof=pending accepted rejected rescheduled completed cancelled no_show"`
+	PropertyID      int64  `form:"property_id"`
+	BuyersUserName  string `form:"buyer_username"`
+	PartnerUserName string `form:"partner_username"`
+	PageReq
+}
+
+type VisitRsp struct {
+	ID             int64      `json:"id"`
+	PropertyID     int64      `json:"property_id"`
+	BuyerUsername  string     `json:"buyer_username"`
+	ScheduledTime  time.Time  `json:"scheduled_time"`
+	Status         string     `json:"status"`
+	RescheduleTime *time.Time `json:"reschedule_time,omitempty"`
+	PartnerNote    string     `json:"partner_note,omitempty"`
+	BuyerNote      string     `json:"buyer_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// Version is the ETag of the visit, send it back in If-Match to update it
+	Version int32 `json:"version"`
+}
+
+func NewVisitRsp(visit *model.Visit) *VisitRsp {
+	rsp := &VisitRsp{
+		ID:            visit.ID,
+		PropertyID:    visit.PropertyID,
+		BuyerUsername: visit.BuyerUsername,
+		ScheduledTime: visit.ScheduledTime,
+		Status:        visit.Status,
+		PartnerNote:   visit.PartnerNote,
+		BuyerNote:     visit.BuyerNote,
+		CreatedAt:     visit.CreatedAt,
+		UpdatedAt:     visit.UpdatedAt,
+		Version:       visit.Version,
+	}
+	if !visit.RescheduleTime.IsZero() {
+		rsp.RescheduleTime = &visit.RescheduleTime
+	}
+	return rsp
+}
+
+func NewVisitRspList(visits []*model.Visit) []*VisitRsp {
+	rsp := make([]*VisitRsp, 0, len(visits))
+	for _, visit := range visits {
+		rsp = append(rsp, NewVisitRsp(visit))
+	}
+	return rsp
 }
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+)
+
+// CreateWebhookReq subscribes url to events, no event types subscribes to every event
+type CreateWebhookReq struct {
+	URL        string   `json:"url" binding:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"omitempty,dive,oneof=property.listed property.price_dropped visit.scheduled visit.accepted visit.rejected visit.rescheduled visit.cancelled visit.completed visit.no_show"`
+}
+
+type UpdateWebhookReq struct {
+	URL        string   `json:"url" binding:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"omitempty,dive,oneof=property.listed property.price_dropped visit.scheduled visit.accepted visit.rejected visit.rescheduled visit.cancelled visit.completed visit.no_show"`
+	Active     *bool    `json:"active" binding:"required"`
+}
+
+type GetWebhook struct {
+	ID int64 `uri:"id" binding:"required,gt=0"`
+}
+
+type WebhookRsp struct {
+	ID         int64    `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Active     bool     `json:"active"`
+	// Secret is only returned when the webhook is created
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryRsp struct {
+	ID         int64     `json:"id"`
+	EventID    int64     `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int32     `json:"attempt"`
+	StatusCode int32     `json:"status_code"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int32     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func NewWebhookRsp(webhook *model.Webhook) *WebhookRsp {
+	eventTypes := make([]string, 0)
+	_ = json.Unmarshal([]byte(webhook.EventTypes), &eventTypes)
+	return &WebhookRsp{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		EventTypes: eventTypes,
+		Active:     webhook.Active,
+		CreatedAt:  webhook.CreatedAt,
+		UpdatedAt:  webhook.UpdatedAt,
+	}
+}
+
+func NewWebhookRspList(webhooks []*model.Webhook) []*WebhookRsp {
+	rsps := make([]*WebhookRsp, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		rsps = append(rsps, NewWebhookRsp(webhook))
+	}
+	return rsps
+}
+
+func NewWebhookDeliveryRsp(delivery *model.WebhookDelivery) *WebhookDeliveryRsp {
+	return &WebhookDeliveryRsp{
+		ID:         delivery.ID,
+		EventID:    delivery.EventID,
+		EventType:  delivery.EventType,
+		Attempt:    delivery.Attempt,
+		StatusCode: delivery.StatusCode,
+		Success:    delivery.Success,
+		Error:      delivery.Error,
+		DurationMs: delivery.DurationMs,
+		CreatedAt:  delivery.CreatedAt,
+	}
+}
+
+func NewWebhookDeliveryRspList(deliveries []*model.WebhookDelivery) []*WebhookDeliveryRsp {
+	rsps := make([]*WebhookDeliveryRsp, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		rsps = append(rsps, NewWebhookDeliveryRsp(delivery))
+	}
+	return rsps
+}
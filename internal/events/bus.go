@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Handler consumes an event, delivery is at least once so handlers must be idempotent
+type Handler func(ctx context.Context, event Event) error
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+// Bus routes events to the subscribers registered for their type
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[string][]subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: map[string][]subscriber{}}
+}
+
+// Subscribe registers handler for eventType, use All to receive every event
+func (b *Bus) Subscribe(eventType, name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[eventType] = append(b.subs[eventType], subscriber{name: name, handler: handler})
+}
+
+// Publish calls every matching subscriber and joins their errors, a failing
+// event is retried as a whole so the other subscribers see it again
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	subs := append(append([]subscriber{}, b.subs[event.Type]...), b.subs[All]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if err := safeCall(ctx, sub.handler, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func safeCall(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
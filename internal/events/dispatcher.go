@@ -0,0 +1,77 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/repo"
+	"booking.com/pkg/utils"
+)
+
+// Dispatcher polls the outbox and hands due events to the bus, failed events
+// are retried with exponential backoff and dead-lettered after MaxAttempts
+type Dispatcher struct {
+	outbox repo.OutboxRepo
+	bus    *Bus
+	cfg    config.Outbox
+}
+
+func NewDispatcher(outbox repo.OutboxRepo, bus *Bus, cfg config.Outbox) *Dispatcher {
+	return &Dispatcher{outbox: outbox, bus: bus, cfg: cfg}
+}
+
+// Run dispatches until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		// drain the backlog before waiting for the next tick
+		for {
+			n, err := d.DispatchOnce(ctx)
+			if err != nil {
+				log.Printf("outbox dispatch failed, error: %v", err)
+			}
+			if err != nil || n < d.cfg.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce delivers one batch of due events and returns how many it claimed
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	rows, err := d.outbox.Claim(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		event := fromOutbox(row)
+		pubErr := d.bus.Publish(ctx, event)
+		if pubErr == nil {
+			if err := d.outbox.MarkDelivered(ctx, row.ID); err != nil {
+				return len(rows), err
+			}
+			continue
+		}
+		dead := row.Attempts >= d.cfg.MaxAttempts
+		if dead {
+			log.Printf("outbox event %d (%s) dead after %d attempts, error: %v", row.ID, row.EventType, row.Attempts, pubErr)
+		}
+		if err := d.outbox.MarkFailed(ctx, row.ID, pubErr.Error(), time.Now().Add(d.Backoff(row.Attempts)), dead); err != nil {
+			return len(rows), err
+		}
+	}
+	return len(rows), nil
+}
+
+// Backoff doubles the base delay per attempt up to the configured maximum
+func (d *Dispatcher) Backoff(attempt int32) time.Duration {
+	return utils.Backoff(d.cfg.BaseBackoff, d.cfg.MaxBackoff, attempt)
+}
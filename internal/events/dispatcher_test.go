@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/repo/memrepo"
+	"booking.com/pkg/constants"
+)
+
+type eventLister interface {
+	Events() []*model.Outbox
+}
+
+func TestDispatchOnce(t *testing.T) {
+	cfg := config.Outbox{BatchSize: 10, Lease: time.Minute, MaxAttempts: 3, BaseBackoff: 0, MaxBackoff: 0}
+	tests := []struct {
+		name         string
+		failures     int
+		rounds       int
+		wantStatus   string
+		wantAttempts int32
+		wantCalls    int
+	}{
+		{name: "delivered first time", rounds: 1, wantStatus: constants.EventDelivered, wantAttempts: 1, wantCalls: 1},
+		{name: "delivered after retries", failures: 2, rounds: 3, wantStatus: constants.EventDelivered, wantAttempts: 3, wantCalls: 3},
+		{name: "dead after max attempts", failures: 5, rounds: 5, wantStatus: constants.EventDead, wantAttempts: 3, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := memrepo.New()
+			calls := 0
+			bus := NewBus()
+			bus.Subscribe(UserRegistered, "test", func(_ context.Context, event Event) error {
+				calls++
+				var payload UserPayload
+				if err := event.Decode(&payload); err != nil || payload.Username != "jane_doe_abc" {
+					t.Errorf("unexpected payload %s: %v", event.Payload, err)
+				}
+				if calls <= tt.failures {
+					return errors.New("mail server unavailable")
+				}
+				return nil
+			})
+			row, err := New(UserRegistered, AggregateUser, "jane_doe_abc", UserPayload{Username: "jane_doe_abc"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := repos.Outbox.Add(context.Background(), row); err != nil {
+				t.Fatal(err)
+			}
+			d := NewDispatcher(repos.Outbox, bus, cfg)
+			for range tt.rounds {
+				if _, err := d.DispatchOnce(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := repos.Outbox.(eventLister).Events()[0]
+			if got.Status != tt.wantStatus || got.Attempts != tt.wantAttempts || calls != tt.wantCalls {
+				t.Errorf("got status=%s attempts=%d calls=%d, want status=%s attempts=%d calls=%d",
+					got.Status, got.Attempts, calls, tt.wantStatus, tt.wantAttempts, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, nil, config.Outbox{BaseBackoff: 2 * time.Second, MaxBackoff: time.Minute})
+	tests := []struct {
+		attempt int32
+		want    time.Duration
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{4, 16 * time.Second},
+		{6, time.Minute},
+		{30, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := d.Backoff(tt.attempt); got != tt.want {
+			t.Errorf("attempt %d: got %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
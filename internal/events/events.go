@@ -0,0 +1,159 @@
+// Package events defines the domain events written to the outbox and the bus
+// delivering them to in-process subscribers
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/pkg/constants"
+)
+
+// Event types, visit events are named after the status the visit moved to
+const (
+	UserRegistered = "user.registered"
+	PropertyListed = "property.listed"
+	// PropertyPriceDropped is published when a partner lowers the price of a listing
+	PropertyPriceDropped = "property.price_dropped"
+	VisitScheduled       = "visit.scheduled"
+	VisitPrefix          = "visit."
+	// ReviewReceived is published when a buyer reviews a property
+	ReviewReceived = "review.received"
+
+	// All subscribes a handler to every event type
+	All = "*"
+)
+
+// Aggregate types
+const (
+	AggregateUser     = "user"
+	AggregateProperty = "property"
+	AggregateVisit    = "visit"
+	AggregateReview   = "review"
+)
+
+// VisitStatusChanged returns the event type published when a visit moves to status
+func VisitStatusChanged(status string) string {
+	return VisitPrefix + status
+}
+
+// Event is a delivered outbox row as seen by subscribers
+type Event struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempt       int32           `json:"attempt"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// Decode unmarshals the payload into v
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// New builds an outbox row, it is meant to be added in the transaction of the state change
+func New(eventType, aggregateType string, aggregateID any, payload any) (*model.Outbox, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s payload: %w", eventType, err)
+	}
+	now := time.Now().UTC()
+	return &model.Outbox{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   fmt.Sprint(aggregateID),
+		Payload:       string(data),
+		Status:        constants.EventPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+func fromOutbox(row *model.Outbox) Event {
+	return Event{
+		ID:            row.ID,
+		Type:          row.EventType,
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		Payload:       json.RawMessage(row.Payload),
+		Attempt:       row.Attempts,
+		OccurredAt:    row.CreatedAt,
+	}
+}
+
+// UserPayload is the payload of user events, it never carries credentials
+type UserPayload struct {
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	// Locale is the language the user asked mails in
+	Locale string `json:"locale,omitempty"`
+}
+
+// PropertyPayload is the payload of property events
+type PropertyPayload struct {
+	ID              int64   `json:"id"`
+	PartnerUsername string  `json:"partner_username"`
+	Title           string  `json:"title"`
+	PropertyType    string  `json:"property_type"`
+	Price           float64 `json:"price"`
+	PreviousPrice   float64 `json:"previous_price,omitempty"`
+	City            string  `json:"city"`
+	State           string  `json:"state"`
+	Status          string  `json:"status"`
+}
+
+// VisitPayload is the payload of visit events
+type VisitPayload struct {
+	ID              int64      `json:"id"`
+	PropertyID      int64      `json:"property_id"`
+	PartnerUsername string     `json:"partner_username"`
+	BuyerUsername   string     `json:"buyer_username"`
+	Status          string     `json:"status"`
+	PreviousStatus  string     `json:"previous_status,omitempty"`
+	ScheduledTime   time.Time  `json:"scheduled_time"`
+	RescheduleTime  *time.Time `json:"reschedule_time,omitempty"`
+	ChangedBy       string     `json:"changed_by,omitempty"`
+	// Sequence orders the calendar invites of the visit
+	Sequence int32 `json:"sequence"`
+}
+
+// ReviewPayload is the payload of review events
+type ReviewPayload struct {
+	ID              int64  `json:"id"`
+	PropertyID      int64  `json:"property_id"`
+	PartnerUsername string `json:"partner_username"`
+	BuyerUsername   string `json:"buyer_username"`
+	Rating          int32  `json:"rating"`
+	ReviewText      string `json:"review_text,omitempty"`
+}
+
+func NewUserPayload(user *model.User) UserPayload {
+	return UserPayload{
+		Username:  user.Username,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Phone:     user.Phone,
+		Locale:    user.Locale,
+	}
+}
+
+func NewPropertyPayload(property *model.Property) PropertyPayload {
+	return PropertyPayload{
+		ID:              property.ID,
+		PartnerUsername: property.PartnerUsername,
+		Title:           property.Title,
+		PropertyType:    property.PropertyType,
+		Price:           property.Price,
+		City:            property.City,
+		State:           property.State,
+		Status:          property.Status,
+	}
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"booking.com/internal/dto"
 	"booking.com/internal/svcs"
@@ -10,7 +11,6 @@ import (
 	jwtauth "booking.com/pkg/auth/jwt-auth"
 	"booking.com/pkg/constants"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
@@ -27,148 +27,156 @@ func NewAuthHandler(authSvc *svcs.AuthSvc, usrSvc *svcs.UserSvc) *AuthHandler {
 func (a *AuthHandler) Register(c *gin.Context) {
 	var userReq dto.CreateUser
 	if err := c.ShouldBindBodyWithJSON(&userReq); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
-		return
-	}
-	var errMsg string
-	if userReq.Phone == "" {
-		errMsg += "phone number not provided"
-	}
-	if errMsg != "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New(errMsg), nil))
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
+	userReq.Locale = c.GetHeader("Accept-Language")
 	if err := a.AuthSvc.RegisterUser(&userReq, a.UsrSvc); err != nil {
-		if errors.Is(err, utils.ErrUserAlreadyExistsWithEmail) || errors.Is(err, utils.ErrUserAlreadyExistsWithPhone) {
-			c.AbortWithStatusJSON(http.StatusOK, utils.WriteAppResponse("", err, nil))
-			return
-		}
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, utils.WriteAppResponse("user registered successfully.", nil, nil))
+	utils.Respond(c, http.StatusCreated, "user registered successfully.", nil)
 }
 
 func (a *AuthHandler) Login(c *gin.Context) {
 	var reqUser dto.Login
 	if err := c.ShouldBindBodyWithJSON(&reqUser); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
-		return
-	}
-	if reqUser.UserName == "" || reqUser.Password == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("username or password is empty"), nil))
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
 	token, refreshToken, err := a.AuthSvc.Login(reqUser, a.UsrSvc)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", utils.ErrUserNotFound, nil))
-			return
-		}
-		c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     constants.RefreshToken,
-		Value:    refreshToken,
-		HttpOnly: true,
-		Secure:   false,
-		Path:     "/",
-	})
-	c.JSON(http.StatusOK, utils.WriteAppResponse("", nil, map[string]string{
-		constants.AccessToken: token,
-		constants.TokenType:   constants.Bearer,
-	}))
+	a.respondTokens(c, token, refreshToken, reqUser.Native)
 }
 
 func (a *AuthHandler) Refresh(c *gin.Context) {
-	refreshToken, err := c.Cookie(constants.RefreshToken)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
-		return
-	}
+	refreshToken, native := requestRefreshToken(c)
 	if refreshToken == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("missing refresh token"), nil))
+		utils.AbortWithError(c, utils.ErrMissingToken.WithMsg("missing refresh token"))
 		return
 	}
 	userName, err := jwtauth.GetUnVerifiedJwtClaims(refreshToken, constants.UserName)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadGateway, utils.WriteAppResponse("", err, nil))
-		return
-	}
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("invalid refresh token", nil, nil))
+	if err != nil || userName == "" {
+		utils.AbortWithError(c, utils.ErrInvalidToken.WithMsg("invalid refresh token"))
 		return
 	}
 
 	newToken, newRefreshToken, err := a.AuthSvc.Refresh(userName, refreshToken, a.UsrSvc)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", utils.ErrUserNotFound, nil))
-			return
+		if errors.Is(err, utils.ErrUserNotFound) {
+			err = utils.ErrInvalidToken.WithMsg("invalid refresh token")
 		}
-		c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     constants.RefreshToken,
-		Value:    newRefreshToken,
-		HttpOnly: true,
-		Secure:   false,
-		Path:     "/",
-	})
-	c.JSON(http.StatusOK, utils.WriteAppResponse("", nil, map[string]string{
-		constants.AccessToken: newToken,
-		constants.TokenType:   constants.Bearer,
-	}))
+	a.respondTokens(c, newToken, newRefreshToken, native)
 }
 func (a *AuthHandler) LogOut(c *gin.Context) {
-	refreshToken, err := c.Cookie(constants.RefreshToken)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
-		return
-	}
+	refreshToken, native := requestRefreshToken(c)
 	if refreshToken == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("missing refresh_token"), nil))
+		utils.AbortWithError(c, utils.ErrMissingToken.WithMsg("missing refresh_token"))
 		return
 	}
 
 	userName, err := jwtauth.GetUnVerifiedJwtClaims(refreshToken, constants.UserName)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
-		return
-	}
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid token data"), nil))
+	if err != nil || userName == "" {
+		utils.AbortWithError(c, utils.ErrInvalidToken.WithMsg("invalid token data"))
 		return
 	}
 	if err := a.AuthSvc.LogOut(userName, a.UsrSvc); err != nil {
 		if errors.Is(err, utils.ErrUserAlreadyLoggedOut) {
-			c.AbortWithStatusJSON(http.StatusOK, utils.WriteAppResponse("", utils.ErrUserAlreadyLoggedOut, nil))
+			utils.Respond(c, http.StatusOK, utils.ErrUserAlreadyLoggedOut.Msg, nil)
 			return
 		}
 		if errors.Is(err, utils.ErrUserNotFound) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", utils.ErrUserNotFound, nil))
-			return
-		}
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", utils.ErrUserNotFound, nil))
-			return
+			err = utils.ErrInvalidToken.WithMsg("invalid token data")
 		}
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, utils.WriteAppResponse("user logged out successfully", nil, nil))
+	if !native {
+		a.setSessionCookies(c, "")
+	}
+	utils.Respond(c, http.StatusOK, "user logged out successfully", nil)
 }
+
+// requestRefreshToken returns the refresh token cookie of browsers or else the
+// token native clients send in the Authorization header or the body. Without
+// an ambient cookie there is nothing to forge, so native requests skip csrf
+func requestRefreshToken(c *gin.Context) (refreshToken string, native bool) {
+	if cookie, err := c.Cookie(constants.RefreshToken); err == nil && cookie != "" {
+		return cookie, false
+	}
+	if header := c.GetHeader(constants.Authorization); strings.HasPrefix(header, constants.Bearer) {
+		return strings.TrimPrefix(header, constants.Bearer), true
+	}
+	var req dto.RefreshReq
+	if c.Request.ContentLength != 0 && c.ShouldBindBodyWithJSON(&req) == nil {
+		return req.RefreshToken, true
+	}
+	return "", false
+}
+
+// respondTokens sends the tokens of a new session, browsers get the refresh
+// token as a cookie and native clients in the body
+func (a *AuthHandler) respondTokens(c *gin.Context, token, refreshToken string, native bool) {
+	rsp := dto.TokenRsp{AccessToken: token, TokenType: constants.Bearer}
+	if native {
+		rsp.RefreshToken = refreshToken
+	} else {
+		a.setSessionCookies(c, refreshToken)
+		rsp.CsrfToken = svcs.CsrfToken(refreshToken)
+	}
+	utils.Respond(c, http.StatusOK, "", rsp)
+}
+
+// setSessionCookies stores the refresh token in an http only cookie and its
+// csrf token in a cookie scripts can read, an empty refresh token clears both
+func (a *AuthHandler) setSessionCookies(c *gin.Context, refreshToken string) {
+	cfg := a.AuthSvc.AppCfg.Cookies
+	sameSite := map[string]http.SameSite{
+		"strict": http.SameSiteStrictMode,
+		"lax":    http.SameSiteLaxMode,
+		"none":   http.SameSiteNoneMode,
+	}[cfg.SameSite]
+	maxAge, csrfToken := 0, ""
+	if refreshToken == "" {
+		maxAge = -1
+	} else {
+		csrfToken = svcs.CsrfToken(refreshToken)
+	}
+	for _, cookie := range []struct {
+		name, value string
+		httpOnly    bool
+	}{
+		{constants.RefreshToken, refreshToken, true},
+		{constants.CsrfToken, csrfToken, false},
+	} {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     cookie.name,
+			Value:    cookie.value,
+			HttpOnly: cookie.httpOnly,
+			Secure:   cfg.Secure,
+			SameSite: sameSite,
+			Domain:   cfg.Domain,
+			Path:     "/",
+			MaxAge:   maxAge,
+		})
+	}
+}
+
 func (a *AuthHandler) ActivateUser(c *gin.Context) {
 	var userReq dto.Activate
 	if err := c.ShouldBindBodyWithJSON(&userReq); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
 	if err := a.AuthSvc.ActivateUser(userReq.UserName, a.UsrSvc); err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, utils.WriteAppResponse("user activated successfully", nil, nil))
+	utils.Respond(c, http.StatusOK, "user activated successfully", nil)
 }
@@ -0,0 +1,66 @@
+package calendar
+
+import (
+	"net/http"
+	"strings"
+
+	"booking.com/internal/calendar"
+	"booking.com/internal/dto"
+	"booking.com/internal/svcs"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	CalendarSvc *svcs.CalendarSvc
+}
+
+func NewCalendarHandler(calendarSvc *svcs.CalendarSvc) *CalendarHandler {
+	return &CalendarHandler{CalendarSvc: calendarSvc}
+}
+
+// CreateFeed issues the private feed url of the current user, calling it again
+// replaces the url
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	token, err := h.CalendarSvc.CreateFeed(userName)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusCreated, "calendar feed created, keep the url private", h.CalendarSvc.FeedURLs(c.GetString(constants.APIVersion), token))
+}
+
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	if err := h.CalendarSvc.RevokeFeed(userName); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusAccepted, "calendar feed revoked", nil)
+}
+
+// Feed serves the calendar of the token's owner, the token is the only credential
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	var feedReq dto.GetCalendarFeed
+	if err := c.ShouldBindUri(&feedReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	ics, err := h.CalendarSvc.Feed(strings.TrimSuffix(feedReq.Token, ".ics"))
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, calendar.ContentType, ics)
+}
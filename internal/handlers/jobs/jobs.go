@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"net/http"
+
+	"booking.com/internal/dto"
+	"booking.com/internal/svcs"
+	"booking.com/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type JobsHandler struct {
+	JobsSvc *svcs.JobsSvc
+}
+
+func NewJobsHandler(jobsSvc *svcs.JobsSvc) *JobsHandler {
+	return &JobsHandler{JobsSvc: jobsSvc}
+}
+
+func (j *JobsHandler) FilterJobs(c *gin.Context) {
+	if !utils.IsAdmin(c) {
+		utils.AbortWithError(c, utils.ErrAdminOnly.WithMsg("user don't have access to list jobs"))
+		return
+	}
+	var filterReq dto.JobFilterReq
+	if err := c.ShouldBindQuery(&filterReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	filterReq.Normalize(utils.DefaultPageLimit(c))
+	jobs, total, err := j.JobsSvc.FilterJobs(&filterReq)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.RespondPage(c, http.StatusOK, "", dto.NewJobRspList(jobs), dto.NewPageMeta(filterReq.PageReq, total))
+}
+
+func (j *JobsHandler) GetJob(c *gin.Context) {
+	if !utils.IsAdmin(c) {
+		utils.AbortWithError(c, utils.ErrAdminOnly.WithMsg("user don't have access to get jobs"))
+		return
+	}
+	var getReq dto.GetJob
+	if err := c.ShouldBindUri(&getReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	job, err := j.JobsSvc.GetJob(getReq.ID)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusOK, "", dto.NewJobRsp(job))
+}
+
+func (j *JobsHandler) RetryJob(c *gin.Context) {
+	if !utils.IsAdmin(c) {
+		utils.AbortWithError(c, utils.ErrAdminOnly.WithMsg("user don't have access to retry jobs"))
+		return
+	}
+	var retryReq dto.GetJob
+	if err := c.ShouldBindUri(&retryReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	job, err := j.JobsSvc.RetryJob(retryReq.ID)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusAccepted, "job queued for retry", dto.NewJobRsp(job))
+}
+
+func (j *JobsHandler) CancelJob(c *gin.Context) {
+	if !utils.IsAdmin(c) {
+		utils.AbortWithError(c, utils.ErrAdminOnly.WithMsg("user don't have access to cancel jobs"))
+		return
+	}
+	var cancelReq dto.GetJob
+	if err := c.ShouldBindUri(&cancelReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	job, err := j.JobsSvc.CancelJob(cancelReq.ID)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusAccepted, "job cancelled", dto.NewJobRsp(job))
+}
@@ -0,0 +1,117 @@
+package notifications
+
+import (
+	"net/http"
+
+	"booking.com/internal/dto"
+	"booking.com/internal/svcs"
+	"booking.com/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationsHandler struct {
+	NotificationSvc *svcs.NotificationSvc
+}
+
+func NewNotificationsHandler(notificationSvc *svcs.NotificationSvc) *NotificationsHandler {
+	return &NotificationsHandler{NotificationSvc: notificationSvc}
+}
+
+func (n *NotificationsHandler) FilterNotifications(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	var filterReq dto.NotificationFilterReq
+	if err := c.ShouldBindQuery(&filterReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	filterReq.Normalize(utils.DefaultPageLimit(c))
+	notifications, total, err := n.NotificationSvc.FilterNotifications(userName, &filterReq)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.RespondPage(c, http.StatusOK, "", dto.NewNotificationRspList(notifications), dto.NewPageMeta(filterReq.PageReq, total))
+}
+
+func (n *NotificationsHandler) UnreadCount(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	unread, err := n.NotificationSvc.UnreadCount(userName)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusOK, "", dto.UnreadCountRsp{Unread: unread})
+}
+
+func (n *NotificationsHandler) MarkRead(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	var readReq dto.GetNotification
+	if err := c.ShouldBindUri(&readReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	if err := n.NotificationSvc.MarkRead(userName, readReq.ID); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusAccepted, "notification marked read", nil)
+}
+
+func (n *NotificationsHandler) MarkAllRead(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	updated, err := n.NotificationSvc.MarkAllRead(userName)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusAccepted, "notifications marked read", dto.MarkAllReadRsp{Updated: updated})
+}
+
+func (n *NotificationsHandler) GetPreferences(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	prefs, err := n.NotificationSvc.Preferences(userName)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusOK, "", dto.NewPreferenceRspList(prefs))
+}
+
+func (n *NotificationsHandler) UpdatePreferences(c *gin.Context) {
+	var updateReq dto.UpdatePreferencesReq
+	if err := c.ShouldBindBodyWithJSON(&updateReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	prefs, err := n.NotificationSvc.UpdatePreferences(userName, &updateReq)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusAccepted, "preferences updated", dto.NewPreferenceRspList(prefs))
+}
@@ -1,13 +1,11 @@
 package properties
 
 import (
-	"errors"
 	"net/http"
 
 	"booking.com/internal/dto"
 	"booking.com/internal/svcs"
 	"booking.com/internal/utils"
-	"booking.com/pkg/constants"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,101 +19,157 @@ func NewPropertyHandler(propertySvc *svcs.PropertySvc) *PropertyHandler {
 func (p *PropertyHandler) AddProperties(c *gin.Context) {
 	var propertiesReq []dto.AddPropertyReq
 	if err := c.ShouldBindBodyWithJSON(&propertiesReq); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
-	reqUserName, ok := c.Get(constants.CurrentUserName)
-	if !ok || reqUserName == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	if len(propertiesReq) == 0 {
+		utils.AbortWithError(c, utils.ErrInvalidRequest.WithMsg("at least one property is required"))
 		return
 	}
-	userName := reqUserName.(string)
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	err := p.PropertySvc.AddProperties(userName, propertiesReq...)
+	err = p.PropertySvc.AddProperties(userName, propertiesReq...)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, utils.WriteAppResponse("properties added", nil, nil))
+	utils.Respond(c, http.StatusCreated, "properties added", nil)
 }
 func (p *PropertyHandler) UpdateProperty(c *gin.Context) {
 	var propertiesReq dto.UpdatePropertyReq
 	if err := c.ShouldBindBodyWithJSON(&propertiesReq); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
-	reqUserName, ok := c.Get(constants.CurrentUserName)
-	if !ok || reqUserName == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	userName := reqUserName.(string)
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	version, err := utils.IfMatch(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	err := p.PropertySvc.UpdateProperty(propertiesReq)
+	err = p.PropertySvc.UpdateProperty(userName, version, propertiesReq)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, utils.WriteAppResponse("property updated", nil, nil))
+	utils.Respond(c, http.StatusCreated, "property updated", nil)
 }
 
-func (p *PropertyHandler) GetFilteredProperties(c *gin.Context) {
-	reqUserName, ok := c.Get(constants.CurrentUserName)
-	if !ok || reqUserName == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+// PatchProperty applies a JSON Merge Patch to an owned property and returns
+// it with its new version
+func (p *PropertyHandler) PatchProperty(c *gin.Context) {
+	var getReq dto.GetProperty
+	if err := c.ShouldBindUri(&getReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	var patchReq dto.PatchPropertyReq
+	if err := c.ShouldBindBodyWithJSON(&patchReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	version, err := utils.IfMatch(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	property, err := p.PropertySvc.PatchProperty(userName, getReq.ID, version, patchReq)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.SetETag(c, property.Version)
+	utils.Respond(c, http.StatusOK, "property updated", dto.NewPropertyRsp(property))
+}
+
+// GetProperty returns an active property tagged with its version
+func (p *PropertyHandler) GetProperty(c *gin.Context) {
+	var getReq dto.GetProperty
+	if err := c.ShouldBindUri(&getReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
-	userName := reqUserName.(string)
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	property, err := p.PropertySvc.GetPropertyByID(getReq.ID, true)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.SetETag(c, property.Version)
+	utils.Respond(c, http.StatusOK, "", dto.NewPropertyRsp(property))
+}
+
+func (p *PropertyHandler) GetFilteredProperties(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 	var filterReq dto.PropertFilterReq
 	if err := c.ShouldBindQuery(&filterReq); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	filterReq.Normalize(utils.DefaultPageLimit(c))
+	properties, total, err := p.PropertySvc.GetFilteredProperties(userName, filterReq, true)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	properties, err := p.PropertySvc.GetFilteredProperties(userName, filterReq, true)
-	if err != nil || len(properties) == 0 {
-		c.AbortWithStatusJSON(http.StatusNotFound, utils.WriteAppResponse("", err, nil))
+	if len(properties) == 0 && !utils.IsV2(c) {
+		utils.AbortWithError(c, utils.ErrPropertyNotFound.WithMsg("no properties matched the filter"))
 		return
 	}
-	c.JSON(http.StatusFound, utils.WriteAppResponse("", nil, properties))
+	utils.RespondPage(c, http.StatusOK, "", dto.NewPropertyRspList(properties), dto.NewPageMeta(filterReq.PageReq, total))
 }
 
 func (p *PropertyHandler) GetAllProperties(c *gin.Context) {
-	properties, err := p.PropertySvc.GetFilteredProperties("", dto.PropertFilterReq{}, true)
-	if err != nil || len(properties) == 0 {
-		c.AbortWithStatusJSON(http.StatusNotFound, utils.WriteAppResponse("", err, nil))
+	var filterReq dto.PropertFilterReq
+	if err := c.ShouldBindQuery(&filterReq.PageReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
-	c.JSON(http.StatusFound, utils.WriteAppResponse("", nil, properties))
+	filterReq.Normalize(utils.DefaultPageLimit(c))
+	properties, total, err := p.PropertySvc.GetFilteredProperties("", filterReq, true)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	if len(properties) == 0 && !utils.IsV2(c) {
+		utils.AbortWithError(c, utils.ErrPropertyNotFound.WithMsg("no properties found"))
+		return
+	}
+	utils.RespondPage(c, http.StatusOK, "", dto.NewPropertyRspList(properties), dto.NewPageMeta(filterReq.PageReq, total))
 }
 func (p *PropertyHandler) DeleteProperty(c *gin.Context) {
-	reqUserName, ok := c.Get(constants.CurrentUserName)
-	if !ok || reqUserName == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	userName := reqUserName.(string)
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	var delReq dto.GetProperty
+	if err := c.ShouldBindUri(&delReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
-	var delReq dto.GetProperty
-	if err := c.ShouldBindUri(delReq); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
+	if _, err := p.PropertySvc.GetOwnedProperty(userName, delReq.ID); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 	if err := p.PropertySvc.DeletePropertyByID(delReq.ID, true); err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, utils.WriteAppResponse("property deleted", nil, ""))
+	utils.Respond(c, http.StatusOK, "property deleted", nil)
 }
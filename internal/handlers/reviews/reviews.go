@@ -0,0 +1,43 @@
+package reviews
+
+import (
+	"net/http"
+
+	"booking.com/internal/dto"
+	"booking.com/internal/svcs"
+	"booking.com/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewsHandler struct {
+	ReviewSvc   *svcs.ReviewSvc
+	PropertySvc *svcs.PropertySvc
+}
+
+func NewReviewsHandler(reviewSvc *svcs.ReviewSvc, propertySvc *svcs.PropertySvc) *ReviewsHandler {
+	return &ReviewsHandler{ReviewSvc: reviewSvc, PropertySvc: propertySvc}
+}
+
+func (r *ReviewsHandler) AddReview(c *gin.Context) {
+	var getReq dto.GetProperty
+	if err := c.ShouldBindUri(&getReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	var reviewReq dto.CreateReviewReq
+	if err := c.ShouldBindBodyWithJSON(&reviewReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	rating, err := r.ReviewSvc.AddReview(userName, getReq.ID, &reviewReq, r.PropertySvc)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusCreated, "review added", dto.NewReviewRsp(rating))
+}
@@ -0,0 +1,165 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/dto"
+	"booking.com/internal/realtime"
+	"booking.com/internal/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// writeTimeout drops a websocket client that stopped reading
+	writeTimeout = 10 * time.Second
+	// reconnectDelay is how long EventSource waits before reconnecting
+	reconnectDelay = 3 * time.Second
+)
+
+type StreamHandler struct {
+	Hub *realtime.Hub
+	Cfg config.Realtime
+}
+
+func NewStreamHandler(hub *realtime.Hub, cfg config.Realtime) *StreamHandler {
+	return &StreamHandler{Hub: hub, Cfg: cfg}
+}
+
+// Events streams the messages of the current user as Server-Sent Events, the
+// stream ends when the client falls too far behind and it resumes with Last-Event-ID
+func (s *StreamHandler) Events(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	lastID, err := lastEventID(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	sub, replay := s.Hub.Subscribe(userName, lastID)
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// stops nginx from buffering the stream
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", reconnectDelay.Milliseconds())
+	for _, msg := range replay {
+		writeEvent(c.Writer, msg)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(s.Cfg.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case msg, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			writeEvent(c.Writer, msg)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+// WebSocket streams the same messages as Events as json text frames, a
+// heartbeat message is sent when the stream is idle
+func (s *StreamHandler) WebSocket(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	lastID, err := lastEventID(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	// the stream is authenticated with a token, not a cookie, so any origin may connect
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		s.serveWebSocket(ws, userName, lastID)
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (s *StreamHandler) serveWebSocket(ws *websocket.Conn, userName string, lastID int64) {
+	defer ws.Close()
+	sub, replay := s.Hub.Subscribe(userName, lastID)
+	defer sub.Close()
+
+	// the client never sends anything, reading only notices that it went away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var discard string
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+	}()
+	send := func(msg realtime.Message) error {
+		if err := ws.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+			return err
+		}
+		return websocket.JSON.Send(ws, msg)
+	}
+	for _, msg := range replay {
+		if send(msg) != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(s.Cfg.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var msg realtime.Message
+		select {
+		case <-closed:
+			return
+		case m, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			msg = m
+		case now := <-heartbeat.C:
+			msg = realtime.Message{Type: realtime.TypeHeartbeat, At: now.UTC()}
+		}
+		if send(msg) != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w gin.ResponseWriter, msg realtime.Message) {
+	data, _ := json.Marshal(msg)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, data)
+}
+
+func lastEventID(c *gin.Context) (int64, error) {
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		lastID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || lastID < 0 {
+			return 0, utils.ErrInvalidRequest.WithMsg("Last-Event-ID must be a message id")
+		}
+		return lastID, nil
+	}
+	var streamReq dto.StreamReq
+	if err := c.ShouldBindQuery(&streamReq); err != nil {
+		return 0, utils.BindError(err)
+	}
+	return streamReq.LastEventID, nil
+}
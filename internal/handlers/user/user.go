@@ -1,15 +1,12 @@
 package user
 
 import (
-	"errors"
 	"net/http"
-	"strconv"
 
 	"booking.com/internal/db/postgresql/model"
 	"booking.com/internal/dto"
 	"booking.com/internal/svcs"
 	"booking.com/internal/utils"
-	"booking.com/pkg/constants"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,107 +19,125 @@ func NewUserHandler(userSvc *svcs.UserSvc) *UserHandler {
 }
 
 func (u *UserHandler) UpdateUser(c *gin.Context) {
-	var updateReq *dto.UpdateUser
+	var updateReq dto.UpdateUser
 	if err := c.ShouldBindBodyWithJSON(&updateReq); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
-	reqUserName, ok := c.Get(constants.CurrentUserName)
-	if !ok || reqUserName == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	userName := reqUserName.(string)
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	version, err := utils.IfMatch(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	err := u.UserSvc.UpdateUser(userName, &model.User{FirstName: updateReq.FirstName, LastName: updateReq.LastName, Address: updateReq.Address})
+	err = u.UserSvc.UpdateUser(userName, version, &model.User{FirstName: updateReq.FirstName, LastName: updateReq.LastName, Address: updateReq.Address})
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusAccepted, utils.WriteAppResponse("user updated", nil, nil))
+	utils.Respond(c, http.StatusAccepted, "user updated", nil)
 }
-func (u *UserHandler) GetProfile(c *gin.Context) {
-	reqUserName, ok := c.Get(constants.CurrentUserName)
-	if !ok || reqUserName == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+
+// PatchProfile applies a JSON Merge Patch to the current user and returns the
+// profile with its new version
+func (u *UserHandler) PatchProfile(c *gin.Context) {
+	var patchReq dto.PatchUser
+	if err := c.ShouldBindBodyWithJSON(&patchReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
-	userName := reqUserName.(string)
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	user, err := u.UserSvc.GetUserByUserName(userName, true)
+	version, err := utils.IfMatch(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	user, err := u.UserSvc.PatchUser(userName, version, patchReq)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.SetETag(c, user.Version)
+	utils.Respond(c, http.StatusOK, "user updated", dto.NewUserRsp(user))
+}
+func (u *UserHandler) GetProfile(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	if user == nil {
-		c.AbortWithStatusJSON(http.StatusNotFound, utils.WriteAppResponse("", errors.New("user not found"), nil))
+	user, err := u.UserSvc.GetUserByUserName(userName, true)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusFound, utils.WriteAppResponse("", nil, user))
+	utils.SetETag(c, user.Version)
+	utils.Respond(c, http.StatusOK, "", dto.NewUserRsp(user))
 }
 
 func (u *UserHandler) ListUsers(c *gin.Context) {
-	if role, ok := c.Get(constants.Role); !ok || role != constants.AdminRole {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("user don't have access to get all users", nil, nil))
+	if !utils.IsAdmin(c) {
+		utils.AbortWithError(c, utils.ErrAdminOnly.WithMsg("user don't have access to get all users"))
 		return
 	}
-	page, limit := 1, 10
-	if p, err := strconv.Atoi(c.Query("page")); err != nil && p != 0 {
-		page = p
-	}
-	if l, err := strconv.Atoi(c.Query("limit")); err != nil && l != 0 {
-		limit = l
+	var pageReq dto.PageReq
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
 	}
-	users, err := u.UserSvc.GettAllUsers(page, limit)
+	pageReq.Normalize(10)
+	users, total, err := u.UserSvc.GettAllUsers(pageReq.Page, pageReq.Limit)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	if len(users) == 0 {
-		c.AbortWithStatusJSON(http.StatusNotFound, utils.WriteAppResponse("", errors.New("users data not found"), nil))
+	if len(users) == 0 && !utils.IsV2(c) {
+		utils.AbortWithError(c, utils.ErrUserNotFound.WithMsg("users data not found"))
 		return
 	}
-	c.JSON(http.StatusFound, utils.WriteAppResponse("", nil, users))
+	utils.RespondPage(c, http.StatusOK, "", dto.NewUserRspList(users), dto.NewPageMeta(pageReq, total))
 }
 
 func (u *UserHandler) UpdateRole(c *gin.Context) {
-	if role, ok := c.Get(constants.Role); !ok || role != constants.AdminRole {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("user don't have access to modify role", nil, nil))
+	if !utils.IsAdmin(c) {
+		utils.AbortWithError(c, utils.ErrAdminOnly.WithMsg("user don't have access to modify role"))
 		return
 	}
-	var roleReq *dto.UserRoleReq
+	var roleReq dto.UserRoleReq
 	if err := c.ShouldBindBodyWithJSON(&roleReq); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
-	err := u.UserSvc.UpdateUser(roleReq.UserName, &model.User{Role: roleReq.Role})
+	version, err := utils.IfMatch(c)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusAccepted, utils.WriteAppResponse("role updated", nil, nil))
-}
-func (u *UserHandler) DeleteUser(c *gin.Context) {
-	reqUserName, ok := c.Get(constants.CurrentUserName)
-	if !ok || reqUserName == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	err = u.UserSvc.UpdateUser(roleReq.UserName, version, &model.User{Role: roleReq.Role})
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	userName := reqUserName.(string)
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	utils.Respond(c, http.StatusAccepted, "role updated", nil)
+}
+func (u *UserHandler) DeleteUser(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	err := u.UserSvc.DelUser(userName)
+	err = u.UserSvc.DelUser(userName)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusAccepted, utils.WriteAppResponse("user deleted", nil, nil))
+	utils.Respond(c, http.StatusAccepted, "user deleted", nil)
 }
@@ -1,13 +1,11 @@
 package visits
 
 import (
-	"errors"
 	"net/http"
 
 	"booking.com/internal/dto"
 	"booking.com/internal/svcs"
 	"booking.com/internal/utils"
-	"booking.com/pkg/constants"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,33 +15,83 @@ type VisitsHandler struct {
 	PropertySvc *svcs.PropertySvc
 }
 
-func NewVisitsHandler(visitsSvc *svcs.VisitsSvc) *VisitsHandler {
-	return &VisitsHandler{VisitsSvc: visitsSvc}
+func NewVisitsHandler(visitsSvc *svcs.VisitsSvc, propertySvc *svcs.PropertySvc) *VisitsHandler {
+	return &VisitsHandler{VisitsSvc: visitsSvc, PropertySvc: propertySvc}
 }
 
 func (u *VisitsHandler) ScheduleVisit(c *gin.Context) {
-	var scheduleReq *dto.ScheduleReq
+	var scheduleReq dto.ScheduleReq
 	if err := c.ShouldBindBodyWithJSON(&scheduleReq); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", err, nil))
+		utils.AbortWithError(c, utils.BindError(err))
 		return
 	}
-	reqUserName, ok := c.Get(constants.CurrentUserName)
-	if !ok || reqUserName == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-	userName := reqUserName.(string)
-	if userName == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, utils.WriteAppResponse("", errors.New("invalid request"), nil))
+	scheduleReq.BuyerUsername = userName
+	if err := u.VisitsSvc.ScheduleVisit(&scheduleReq, u.PropertySvc); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
-
+	utils.Respond(c, http.StatusCreated, "visit scheduled", nil)
 }
 func (u *VisitsHandler) UpdateVisit(c *gin.Context) {
+	var updateReq dto.UpdateVisitReq
+	if err := c.ShouldBindBodyWithJSON(&updateReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	version, err := utils.IfMatch(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	if err := u.VisitsSvc.UpdateVisit(userName, version, &updateReq, u.PropertySvc); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusAccepted, "visit updated", nil)
 }
 func (u *VisitsHandler) FilterVisits(c *gin.Context) {
-
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	var filterReq dto.VisitFilterReq
+	if err := c.ShouldBindQuery(&filterReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	filterReq.Normalize(utils.DefaultPageLimit(c))
+	visits, total, err := u.VisitsSvc.FilterVisits(userName, &filterReq, utils.IsAdmin(c))
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.RespondPage(c, http.StatusOK, "", dto.NewVisitRspList(visits), dto.NewPageMeta(filterReq.PageReq, total))
 }
-func (u *VisitsHandler) DeleteVisit(*gin.Context) {
-
+func (u *VisitsHandler) DeleteVisit(c *gin.Context) {
+	userName, err := utils.CurrentUserName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	var delReq dto.GetVisit
+	if err := c.ShouldBindUri(&delReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	if err := u.VisitsSvc.DeleteVisit(userName, delReq.ID, utils.IsAdmin(c)); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusOK, "visit deleted", nil)
 }
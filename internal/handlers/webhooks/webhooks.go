@@ -0,0 +1,152 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"booking.com/internal/dto"
+	"booking.com/internal/svcs"
+	"booking.com/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhooksHandler struct {
+	WebhookSvc *svcs.WebhookSvc
+}
+
+func NewWebhooksHandler(webhookSvc *svcs.WebhookSvc) *WebhooksHandler {
+	return &WebhooksHandler{WebhookSvc: webhookSvc}
+}
+
+func (w *WebhooksHandler) CreateWebhook(c *gin.Context) {
+	userName, err := partnerName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	var createReq dto.CreateWebhookReq
+	if err := c.ShouldBindBodyWithJSON(&createReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	webhook, err := w.WebhookSvc.CreateWebhook(userName, &createReq)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	rsp := dto.NewWebhookRsp(webhook)
+	rsp.Secret = webhook.Secret
+	utils.Respond(c, http.StatusCreated, "webhook created, store the secret to verify signatures", rsp)
+}
+
+func (w *WebhooksHandler) ListWebhooks(c *gin.Context) {
+	userName, err := partnerName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	webhooks, err := w.WebhookSvc.ListWebhooks(userName)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusOK, "", dto.NewWebhookRspList(webhooks))
+}
+
+func (w *WebhooksHandler) UpdateWebhook(c *gin.Context) {
+	userName, err := partnerName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	var getReq dto.GetWebhook
+	if err := c.ShouldBindUri(&getReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	var updateReq dto.UpdateWebhookReq
+	if err := c.ShouldBindBodyWithJSON(&updateReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	webhook, err := w.WebhookSvc.UpdateWebhook(userName, getReq.ID, &updateReq)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusAccepted, "webhook updated", dto.NewWebhookRsp(webhook))
+}
+
+func (w *WebhooksHandler) DeleteWebhook(c *gin.Context) {
+	userName, err := partnerName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	var getReq dto.GetWebhook
+	if err := c.ShouldBindUri(&getReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	if err := w.WebhookSvc.DeleteWebhook(userName, getReq.ID); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.Respond(c, http.StatusAccepted, "webhook deleted", nil)
+}
+
+func (w *WebhooksHandler) Deliveries(c *gin.Context) {
+	userName, err := partnerName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	var getReq dto.GetWebhook
+	if err := c.ShouldBindUri(&getReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	var pageReq dto.PageReq
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	pageReq.Normalize(utils.DefaultPageLimit(c))
+	deliveries, total, err := w.WebhookSvc.Deliveries(userName, getReq.ID, pageReq)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.RespondPage(c, http.StatusOK, "", dto.NewWebhookDeliveryRspList(deliveries), dto.NewPageMeta(pageReq, total))
+}
+
+func (w *WebhooksHandler) SendTest(c *gin.Context) {
+	userName, err := partnerName(c)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	var getReq dto.GetWebhook
+	if err := c.ShouldBindUri(&getReq); err != nil {
+		utils.AbortWithError(c, utils.BindError(err))
+		return
+	}
+	delivery, err := w.WebhookSvc.SendTest(userName, getReq.ID)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	msg := "test event delivered"
+	if !delivery.Success {
+		msg = "test event was not accepted by the endpoint"
+	}
+	utils.Respond(c, http.StatusOK, msg, dto.NewWebhookDeliveryRsp(delivery))
+}
+
+// partnerName returns the current user when it is a partner, webhooks only
+// carry events about listings so other roles have nothing to subscribe to
+func partnerName(c *gin.Context) (string, error) {
+	if !utils.IsPartner(c) {
+		return "", utils.ErrPartnerOnly
+	}
+	return utils.CurrentUserName(c)
+}
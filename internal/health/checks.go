@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+)
+
+// Database pings the primary and every replica
+func Database(db *dao.Cluster) CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		nodes := db.Check(ctx)
+		for _, node := range nodes {
+			if !node.Up {
+				return nodes, fmt.Errorf("%s node %s is down", node.Role, node.Name)
+			}
+		}
+		return nodes, nil
+	}
+}
+
+// Migrations fails when the last migration was left dirty
+func Migrations(db *dao.Cluster) CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		version, err := db.MigrationVersion(ctx)
+		if err != nil {
+			if errors.Is(err, dao.ErrNotFound) {
+				return nil, errors.New("no migration has been applied")
+			}
+			return nil, err
+		}
+		details := map[string]any{"version": version.Version, "dirty": version.Dirty}
+		if version.Dirty {
+			return details, fmt.Errorf("migration %d is dirty", version.Version)
+		}
+		return details, nil
+	}
+}
+
+// Mailer validates the mail configuration without sending anything
+func Mailer(cfg config.Mail) CheckFunc {
+	return func(context.Context) (any, error) {
+		details := map[string]string{"provider": cfg.Provider}
+		if cfg.Provider == "local" {
+			return details, nil
+		}
+		if _, err := mail.ParseAddress(cfg.From); err != nil {
+			return details, fmt.Errorf("invalid sender address %q", cfg.From)
+		}
+		switch cfg.Provider {
+		case "ses":
+			if cfg.SesRegion == "" {
+				return details, errors.New("ses region is not set")
+			}
+		case "smtp":
+			if _, _, err := net.SplitHostPort(cfg.SmtpAddr); err != nil {
+				return details, fmt.Errorf("invalid smtp address %q", cfg.SmtpAddr)
+			}
+		default:
+			return details, fmt.Errorf("unknown mail provider %q", cfg.Provider)
+		}
+		return details, nil
+	}
+}
+
+// BlobStore checks that the photo bucket answers, any status below 500 counts as reachable
+func BlobStore(cfg config.BlobStore, client *http.Client) CheckFunc {
+	return func(ctx context.Context) (any, error) {
+		if cfg.Endpoint == "" {
+			return nil, ErrSkipped
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, strings.TrimRight(cfg.Endpoint, "/")+"/", nil)
+		if err != nil {
+			return nil, err
+		}
+		rsp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		rsp.Body.Close()
+		details := map[string]int{"status_code": rsp.StatusCode}
+		if rsp.StatusCode >= http.StatusInternalServerError {
+			return details, fmt.Errorf("blob store answered %d", rsp.StatusCode)
+		}
+		return details, nil
+	}
+}
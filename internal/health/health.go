@@ -0,0 +1,133 @@
+// Package health runs the dependency checks behind the liveness and readiness probes
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp      = "up"
+	StatusDown    = "down"
+	StatusSkipped = "skipped"
+
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+	StatusDraining = "draining"
+)
+
+// ErrSkipped marks a dependency that is not configured, it does not fail readiness
+var ErrSkipped = errors.New("not configured")
+
+// CheckFunc checks one dependency, the returned details are reported as is
+type CheckFunc func(ctx context.Context) (details any, err error)
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Result is the outcome of a single dependency check
+type Result struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Details   any    `json:"details,omitempty"`
+}
+
+// Report is the readiness breakdown per dependency
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Probe holds the readiness checks and the draining flag set on shutdown
+type Probe struct {
+	timeout  time.Duration
+	checks   []check
+	draining atomic.Bool
+}
+
+func NewProbe(timeout time.Duration) *Probe {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Probe{timeout: timeout}
+}
+
+// Add registers a dependency check, checks run concurrently on every readiness request
+func (p *Probe) Add(name string, fn CheckFunc) *Probe {
+	p.checks = append(p.checks, check{name: name, fn: fn})
+	return p
+}
+
+// Drain makes every following readiness check fail
+func (p *Probe) Drain() {
+	p.draining.Store(true)
+}
+
+func (p *Probe) Draining() bool {
+	return p.draining.Load()
+}
+
+// Ready runs all checks with the probe timeout, it reports ready only when no
+// check is down and the server is not draining
+func (p *Probe) Ready(ctx context.Context) (bool, Report) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusReady, Checks: make(map[string]Result, len(p.checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, c := range p.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := run(ctx, c.fn)
+			mu.Lock()
+			report.Checks[c.name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == StatusDown {
+			report.Status = StatusNotReady
+		}
+	}
+	if p.Draining() {
+		report.Status = StatusDraining
+	}
+	return report.Status == StatusReady, report
+}
+
+func run(ctx context.Context, fn CheckFunc) Result {
+	start := time.Now()
+	done := make(chan Result, 1)
+	go func() {
+		details, err := fn(ctx)
+		result := Result{Status: StatusUp, Details: details}
+		switch {
+		case errors.Is(err, ErrSkipped):
+			result.Status = StatusSkipped
+		case err != nil:
+			result.Status = StatusDown
+			result.Error = err.Error()
+		}
+		done <- result
+	}()
+	var result Result
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = Result{Status: StatusDown, Error: ctx.Err().Error()}
+	}
+	result.LatencyMs = time.Since(start).Milliseconds()
+	return result
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProbeReady(t *testing.T) {
+	up := func(context.Context) (any, error) { return nil, nil }
+	down := func(context.Context) (any, error) { return nil, errors.New("connection refused") }
+	skipped := func(context.Context) (any, error) { return nil, ErrSkipped }
+	hangs := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		return nil, nil
+	}
+
+	tests := []struct {
+		name       string
+		checks     map[string]CheckFunc
+		drain      bool
+		wantReady  bool
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{name: "all up", checks: map[string]CheckFunc{"db": up, "mail": up}, wantReady: true, wantStatus: StatusReady,
+			wantChecks: map[string]string{"db": StatusUp, "mail": StatusUp}},
+		{name: "skipped is ready", checks: map[string]CheckFunc{"db": up, "blob": skipped}, wantReady: true, wantStatus: StatusReady,
+			wantChecks: map[string]string{"db": StatusUp, "blob": StatusSkipped}},
+		{name: "one down", checks: map[string]CheckFunc{"db": down, "mail": up}, wantStatus: StatusNotReady,
+			wantChecks: map[string]string{"db": StatusDown, "mail": StatusUp}},
+		{name: "timeout", checks: map[string]CheckFunc{"db": hangs}, wantStatus: StatusNotReady,
+			wantChecks: map[string]string{"db": StatusDown}},
+		{name: "draining", checks: map[string]CheckFunc{"db": up}, drain: true, wantStatus: StatusDraining,
+			wantChecks: map[string]string{"db": StatusUp}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := NewProbe(20 * time.Millisecond)
+			for name, fn := range tt.checks {
+				probe.Add(name, fn)
+			}
+			if tt.drain {
+				probe.Drain()
+			}
+			ready, report := probe.Ready(context.Background())
+			if ready != tt.wantReady || report.Status != tt.wantStatus {
+				t.Fatalf("got ready=%v status=%s, want ready=%v status=%s", ready, report.Status, tt.wantReady, tt.wantStatus)
+			}
+			for name, want := range tt.wantChecks {
+				if got := report.Checks[name].Status; got != want {
+					t.Errorf("check %s: got %s, want %s", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package jobs runs background work stored in the jobs table, jobs are claimed
+// with FOR UPDATE SKIP LOCKED so any number of workers can share the queue
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/repo"
+)
+
+// Job is a claimed job as seen by its handler
+type Job struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempt     int32           `json:"attempt"`
+	MaxAttempts int32           `json:"max_attempts"`
+}
+
+// Decode unmarshals the payload into v
+func (j Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Handler runs a job, a job is retried until it succeeds so handlers must be idempotent
+type Handler func(ctx context.Context, job Job) error
+
+// Option customises an enqueued job
+type Option func(*model.Job)
+
+// RunAt delays the job until t
+func RunAt(t time.Time) Option {
+	return func(job *model.Job) {
+		job.RunAt = t.UTC()
+	}
+}
+
+// UniqueKey makes enqueueing idempotent, a job with a key that was ever used is skipped
+func UniqueKey(key string) Option {
+	return func(job *model.Job) {
+		job.UniqueKey = &key
+	}
+}
+
+// MaxAttempts overrides the default of 5 attempts before the job is dead
+func MaxAttempts(n int32) Option {
+	return func(job *model.Job) {
+		job.MaxAttempts = n
+	}
+}
+
+// Enqueue adds a job of kind, it can run in the transaction of the change that
+// caused it by passing the transaction's JobRepo. A duplicate unique key is not an error
+func Enqueue(ctx context.Context, jobs repo.JobRepo, kind string, payload any, opts ...Option) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", kind, err)
+	}
+	job := &model.Job{Kind: kind, Payload: string(data), RunAt: time.Now().UTC()}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if err := jobs.Enqueue(ctx, job); err != nil && !errors.Is(err, dao.ErrConflict) {
+		return err
+	}
+	return nil
+}
+
+func fromModel(row *model.Job) Job {
+	return Job{
+		ID:          row.ID,
+		Kind:        row.Kind,
+		Payload:     json.RawMessage(row.Payload),
+		Attempt:     row.Attempts,
+		MaxAttempts: row.MaxAttempts,
+	}
+}
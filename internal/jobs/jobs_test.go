@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/dto"
+	"booking.com/internal/repo/memrepo"
+	"booking.com/pkg/constants"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2025, time.January, 31, 10, 17, 30, 0, time.UTC) // a friday
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "* * * * *", want: time.Date(2025, time.January, 31, 10, 18, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", want: time.Date(2025, time.January, 31, 10, 30, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2025, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{spec: "0 3 * * *", want: time.Date(2025, time.February, 1, 3, 0, 0, 0, time.UTC)},
+		{spec: "30 9 * * 1-5", want: time.Date(2025, time.February, 3, 9, 30, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", want: time.Date(2025, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{spec: "@monthly", want: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 12 29 2 *", want: time.Date(2028, time.February, 29, 12, 0, 0, 0, time.UTC)},
+		{spec: "0 0 13 * 5", want: time.Date(2025, time.February, 7, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 31 2 *", want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) expected an error", spec)
+		}
+	}
+}
+
+type thumbnail struct {
+	PhotoID int64 `json:"photo_id"`
+}
+
+func TestRunOnceRetriesUntilDead(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		wantStatus   string
+		wantAttempts int32
+	}{
+		{name: "succeeds first time", wantStatus: constants.JobSucceeded, wantAttempts: 1},
+		{name: "succeeds after retries", failures: 2, wantStatus: constants.JobSucceeded, wantAttempts: 3},
+		{name: "dead after max attempts", failures: 5, wantStatus: constants.JobDead, wantAttempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := memrepo.New()
+			runner := NewRunner(repos.Jobs, config.Jobs{Lease: time.Minute})
+			calls := 0
+			Handle(runner, "photos.thumbnail", func(_ context.Context, payload thumbnail) error {
+				calls++
+				if payload.PhotoID != 42 {
+					t.Errorf("unexpected payload %+v", payload)
+				}
+				if calls <= tt.failures {
+					return errors.New("blob store unavailable")
+				}
+				return nil
+			})
+			if err := Enqueue(context.Background(), repos.Jobs, "photos.thumbnail", thumbnail{PhotoID: 42}, MaxAttempts(3)); err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+			for range 5 {
+				if _, err := runner.RunOnce(context.Background(), 10); err != nil {
+					t.Fatalf("RunOnce() error = %v", err)
+				}
+			}
+			rows, _, _ := repos.Jobs.Filter(context.Background(), dto.JobFilterReq{})
+			if len(rows) != 1 {
+				t.Fatalf("got %d jobs, want 1", len(rows))
+			}
+			if rows[0].Status != tt.wantStatus || rows[0].Attempts != tt.wantAttempts {
+				t.Errorf("job status = %s attempts = %d, want %s %d", rows[0].Status, rows[0].Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestSchedulerEnqueuesSlotOnce(t *testing.T) {
+	repos := memrepo.New()
+	// two schedulers stand in for two worker processes sharing the queue
+	schedulers := []*Scheduler{NewScheduler(repos.Jobs), NewScheduler(repos.Jobs)}
+	for _, s := range schedulers {
+		if err := s.Add("prune", "@hourly", "maintenance.prune", struct{}{}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	ctx := context.Background()
+	now := time.Now().UTC().Add(time.Hour)
+	for _, s := range schedulers {
+		s.Tick(ctx, now)
+		s.Tick(ctx, now)
+	}
+	rows, _, _ := repos.Jobs.Filter(ctx, dto.JobFilterReq{Kind: "maintenance.prune"})
+	if len(rows) != 1 {
+		t.Fatalf("got %d jobs, want 1", len(rows))
+	}
+	if rows[0].UniqueKey == nil || rows[0].RunAt.Minute() != 0 {
+		t.Errorf("unexpected slot job %+v", rows[0])
+	}
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/repo"
+	"booking.com/pkg/utils"
+)
+
+// Runner claims due jobs of the registered kinds and runs their handlers,
+// failed jobs are retried with exponential backoff until MaxAttempts
+type Runner struct {
+	jobs     repo.JobRepo
+	cfg      config.Jobs
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRunner(jobs repo.JobRepo, cfg config.Jobs) *Runner {
+	return &Runner{jobs: jobs, cfg: cfg, handlers: map[string]Handler{}}
+}
+
+// Register sets the handler for kind, registering a kind twice replaces the handler
+func (r *Runner) Register(kind string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[kind] = handler
+}
+
+// Handle registers fn for kind with the payload decoded into T
+func Handle[T any](r *Runner, kind string, fn func(ctx context.Context, payload T) error) {
+	r.Register(kind, func(ctx context.Context, job Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("decode %s payload: %w", kind, err)
+		}
+		return fn(ctx, payload)
+	})
+}
+
+// Run starts Concurrency workers and blocks until ctx is cancelled and they
+// finished the jobs they hold
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range max(r.cfg.Concurrency, 1) {
+		wg.Go(func() {
+			r.work(ctx)
+		})
+	}
+	wg.Wait()
+}
+
+func (r *Runner) work(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		// keep claiming while there is a backlog before waiting for the next tick
+		for ctx.Err() == nil {
+			n, err := r.RunOnce(ctx, 1)
+			if err != nil {
+				log.Printf("job run failed, error: %v", err)
+			}
+			if err != nil || n == 0 {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims up to limit due jobs, runs them and returns how many it claimed
+func (r *Runner) RunOnce(ctx context.Context, limit int) (int, error) {
+	r.mu.RLock()
+	handlers := maps.Clone(r.handlers)
+	r.mu.RUnlock()
+	if len(handlers) == 0 {
+		return 0, nil
+	}
+
+	rows, err := r.jobs.Claim(ctx, slices.Collect(maps.Keys(handlers)), limit, r.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		job := fromModel(row)
+		// the job is finished even when shutdown cancels ctx while it runs
+		doneCtx := context.WithoutCancel(ctx)
+		runErr := safeRun(ctx, handlers[job.Kind], job)
+		if runErr == nil {
+			if err := r.jobs.Complete(doneCtx, job.ID); err != nil {
+				return len(rows), err
+			}
+			continue
+		}
+		dead := job.Attempt >= job.MaxAttempts
+		if dead {
+			log.Printf("job %d (%s) dead after %d attempts, error: %v", job.ID, job.Kind, job.Attempt, runErr)
+		}
+		retryAt := time.Now().Add(utils.Backoff(r.cfg.BaseBackoff, r.cfg.MaxBackoff, job.Attempt))
+		if err := r.jobs.Fail(doneCtx, job.ID, runErr.Error(), retryAt, dead); err != nil {
+			return len(rows), err
+		}
+	}
+	return len(rows), nil
+}
+
+func safeRun(ctx context.Context, handler Handler, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"booking.com/internal/repo"
+)
+
+type entry struct {
+	name     string
+	schedule *Schedule
+	kind     string
+	payload  any
+	next     time.Time
+}
+
+// Scheduler enqueues recurring jobs, every slot is enqueued with a unique key
+// so several schedulers running side by side enqueue it only once
+type Scheduler struct {
+	jobs    repo.JobRepo
+	mu      sync.Mutex
+	entries []*entry
+}
+
+func NewScheduler(jobs repo.JobRepo) *Scheduler {
+	return &Scheduler{jobs: jobs}
+}
+
+// Add schedules a job of kind with payload at every time matching the cron spec, in UTC
+func (s *Scheduler) Add(name, spec, kind string, payload any) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &entry{name: name, schedule: schedule, kind: kind, payload: payload,
+		next: schedule.Next(time.Now().UTC())})
+	return nil
+}
+
+// Run enqueues due slots every minute until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Tick(ctx, now)
+		}
+	}
+}
+
+// Tick enqueues the slots that are due at now, a missed slot is enqueued once
+// and the schedule resumes from now
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) {
+	now = now.UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if e.next.IsZero() || e.next.After(now) {
+			continue
+		}
+		slot := e.next
+		if err := Enqueue(ctx, s.jobs, e.kind, e.payload, RunAt(slot),
+			UniqueKey("cron:"+e.name+":"+slot.Format(time.RFC3339))); err != nil {
+			log.Printf("schedule %s failed to enqueue slot %v, error: %v", e.name, slot, err)
+			continue
+		}
+		e.next = e.schedule.Next(now)
+	}
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SentMail is a message captured by the LocalMailer with its encoded form
+type SentMail struct {
+	*Message
+	Raw []byte
+}
+
+// LocalMailer is the development sink, it keeps every message in memory and
+// writes it as an .eml file when a directory is configured
+type LocalMailer struct {
+	dir  string
+	mu   sync.Mutex
+	sent []SentMail
+}
+
+func NewLocalMailer(dir string) *LocalMailer {
+	return &LocalMailer{dir: dir}
+}
+
+func (l *LocalMailer) Send(_ context.Context, msg *Message) error {
+	raw, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sent = append(l.sent, SentMail{Message: msg, Raw: raw})
+	if l.dir == "" {
+		log.Printf("mail to %s: %s", strings.Join(msg.To, ", "), msg.Subject)
+		return nil
+	}
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return err
+	}
+	name := filepath.Join(l.dir, fmt.Sprintf("%s-%03d.eml", time.Now().UTC().Format("20060102T150405"), len(l.sent)))
+	if err := os.WriteFile(name, raw, 0o644); err != nil {
+		return err
+	}
+	log.Printf("mail to %s: %s, saved to %s", strings.Join(msg.To, ", "), msg.Subject, name)
+	return nil
+}
+
+// Sent returns the messages sent so far
+func (l *LocalMailer) Sent() []SentMail {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]SentMail{}, l.sent...)
+}
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Bytes encodes the message as multipart/mixed with the text and html bodies as
+// multipart/alternative followed by the attachments
+func (m *Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", m.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n", writer.Boundary())
+
+	if err := m.writeBody(writer); err != nil {
+		return nil, err
+	}
+	for _, attachment := range m.Attachments {
+		if err := writeAttachment(writer, attachment); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *Message) writeBody(writer *multipart.Writer) error {
+	var alt bytes.Buffer
+	altWriter := multipart.NewWriter(&alt)
+	// clients show the last alternative they support, so html goes last
+	for _, body := range []struct{ contentType, content string }{
+		{"text/plain; charset=\"utf-8\"", m.Text},
+		{"text/html; charset=\"utf-8\"", m.HTML},
+	} {
+		if body.content == "" {
+			continue
+		}
+		part, err := altWriter.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(body.content)); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+	}
+	if err := altWriter.Close(); err != nil {
+		return err
+	}
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=\"%s\"", altWriter.Boundary())},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(alt.Bytes())
+	return err
+}
+
+func writeAttachment(writer *multipart.Writer, attachment Attachment) error {
+	data, err := io.ReadAll(attachment.Reader)
+	if err != nil {
+		return fmt.Errorf("cannot read attachment %s: %w", attachment.Filename, err)
+	}
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = detectMimeType(attachment.Filename)
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid content type %q of attachment %s: %w", contentType, attachment.Filename, err)
+	}
+	params["name"] = attachment.Filename
+	header := textproto.MIMEHeader{}
+	header.Add("Content-Type", mime.FormatMediaType(mediaType, params))
+	header.Add("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	header.Add("Content-Transfer-Encoding", "base64")
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create MIME part for %s: %w", attachment.Filename, err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for i := 0; i < len(encoded); i += 76 {
+		end := min(i+76, len(encoded))
+		if _, err := part.Write([]byte(encoded[i:end] + "\r\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func detectMimeType(filename string) string {
+	switch filepath.Ext(filename) {
+	case ".pdf":
+		return "application/pdf"
+	case ".ics":
+		return "text/calendar; charset=utf-8"
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".txt":
+		return "text/plain"
+	default:
+		return "application/octet-stream"
+	}
+}
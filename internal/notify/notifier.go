@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Notifier renders a template and sends it from the configured sender
+type Notifier struct {
+	Mailer    Mailer
+	Templates *Templates
+	From      string
+}
+
+func NewNotifier(mailer Mailer, templates *Templates, from string) *Notifier {
+	return &Notifier{Mailer: mailer, Templates: templates, From: from}
+}
+
+// Send mails the template rendered with data in locale to the recipients
+func (n *Notifier) Send(ctx context.Context, to []string, template, locale string, data any, attachments ...Attachment) error {
+	msg, err := n.Templates.Render(template, locale, data)
+	if err != nil {
+		return err
+	}
+	msg.From = n.From
+	msg.To = to
+	msg.Attachments = attachments
+	return n.Mailer.Send(ctx, msg)
+}
+
+// Email is a templated email that can be queued as a job payload
+type Email struct {
+	To          []string          `json:"to"`
+	Template    string            `json:"template"`
+	Locale      string            `json:"locale"`
+	Data        json.RawMessage   `json:"data"`
+	Attachments []EmailAttachment `json:"attachments,omitempty"`
+}
+
+// EmailAttachment is an attachment held in memory so it survives the queue,
+// keep it small as it is stored with the job
+type EmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Content     []byte `json:"content"`
+}
+
+// NewEmail encodes data for a queued email
+func NewEmail(to []string, template, locale string, data any) (Email, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Email{}, fmt.Errorf("marshal %s data: %w", template, err)
+	}
+	return Email{To: to, Template: template, Locale: locale, Data: raw}, nil
+}
+
+// SendEmail decodes the data of a queued email into the type its template expects and sends it
+func (n *Notifier) SendEmail(ctx context.Context, email Email) error {
+	var data any
+	switch email.Template {
+	case TemplateRegistration:
+		data = &RegistrationData{}
+	case TemplateVerification:
+		data = &VerificationData{}
+	case TemplateVisitStatus:
+		data = &VisitStatusData{}
+	case TemplatePasswordReset:
+		data = &PasswordResetData{}
+	case TemplatePriceDrop:
+		data = &PriceDropData{}
+	case TemplateVisitInvite:
+		data = &VisitInviteData{}
+	default:
+		return fmt.Errorf("unknown mail template %q", email.Template)
+	}
+	if err := json.Unmarshal(email.Data, data); err != nil {
+		return fmt.Errorf("decode %s data: %w", email.Template, err)
+	}
+	attachments := make([]Attachment, 0, len(email.Attachments))
+	for _, attachment := range email.Attachments {
+		attachments = append(attachments, Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Reader:      bytes.NewReader(attachment.Content),
+		})
+	}
+	return n.Send(ctx, email.To, email.Template, email.Locale, data, attachments...)
+}
@@ -0,0 +1,60 @@
+// Package notify renders the transactional emails and sends them through the
+// configured Mailer
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"booking.com/internal/config"
+)
+
+// Mail providers
+const (
+	ProviderSES   = "ses"
+	ProviderSMTP  = "smtp"
+	ProviderLocal = "local"
+)
+
+// Job kinds sending queued messages, the payloads are Email and SMS
+const (
+	KindSendMail = "mail.send"
+	KindSendSMS  = "sms.send"
+)
+
+// Attachment is read once when the message is sent, so a message with
+// attachments can't be sent twice
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Reader      io.Reader
+}
+
+type Message struct {
+	From        string
+	To          []string
+	Subject     string
+	HTML        string
+	Text        string
+	Attachments []Attachment
+}
+
+// Mailer delivers a message, implementations must be safe for concurrent use
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewMailer builds the Mailer of the configured provider
+func NewMailer(ctx context.Context, cfg config.Mail) (Mailer, error) {
+	switch cfg.Provider {
+	case ProviderSES:
+		return NewSESMailer(ctx, cfg.SesRegion)
+	case ProviderSMTP:
+		return NewSMTPMailer(cfg.SmtpAddr, cfg.SmtpUsername, cfg.SmtpPassword), nil
+	case ProviderLocal, "":
+		return NewLocalMailer(cfg.LocalDir), nil
+	default:
+		return nil, fmt.Errorf("unknown mail provider %q", cfg.Provider)
+	}
+}
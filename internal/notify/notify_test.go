@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+func TestRenderLocales(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+	reschedule := time.Date(2025, time.March, 2, 11, 0, 0, 0, time.UTC)
+	data := map[string]any{
+		TemplateRegistration: RegistrationData{FirstName: "Jane", Username: "jane_doe_abc"},
+		TemplateVerification: VerificationData{FirstName: "Jane", Link: "https://bookmylab.com/verify?t=1&u=2", ExpiresIn: time.Hour},
+		TemplateVisitStatus: VisitStatusData{FirstName: "Jane", PropertyTitle: "Sea view flat", Status: "rescheduled",
+			ChangedBy: "raj_kuma_xyz", ScheduledTime: reschedule.Add(-24 * time.Hour), RescheduleTime: &reschedule},
+		TemplatePasswordReset:  PasswordResetData{FirstName: "Jane", Link: "https://bookmylab.com/reset", ExpiresIn: 30 * time.Minute},
+		TemplatePriceDrop:      PriceDropData{FirstName: "Jane", PropertyTitle: "Sea view flat", City: "Mumbai", Price: 90, PreviousPrice: 100},
+		TemplateVisitInvite:    VisitInviteData{FirstName: "Jane", PropertyTitle: "Sea view flat", Location: "Juhu, Mumbai", Start: reschedule, Updated: true},
+		TemplateVisitReminder:  VisitReminderData{FirstName: "Jane", PropertyTitle: "Sea view flat", Counterpart: "John Doe", Start: reschedule, FollowUp: true},
+		TemplateReviewReceived: ReviewReceivedData{FirstName: "Jane", PropertyTitle: "Sea view flat", Buyer: "raj_kuma_xyz", Rating: 4, ReviewText: "Bright and airy"},
+	}
+	for _, locale := range []string{"en", "hi"} {
+		for name, d := range data {
+			t.Run(locale+"/"+name, func(t *testing.T) {
+				msg, err := templates.Render(name, locale, d)
+				if err != nil {
+					t.Fatalf("Render() error = %v", err)
+				}
+				if msg.Subject == "" || strings.Contains(msg.Subject, "\n") {
+					t.Errorf("bad subject %q", msg.Subject)
+				}
+				if !strings.Contains(msg.Text, "Jane") || !strings.Contains(msg.HTML, "Jane") {
+					t.Errorf("body is missing the data, text %q html %q", msg.Text, msg.HTML)
+				}
+				if strings.Contains(msg.Text, "subject") {
+					t.Errorf("text body contains the subject block: %q", msg.Text)
+				}
+			})
+		}
+	}
+
+	msg, err := templates.Render(TemplateVerification, "en", data[TemplateVerification])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg.HTML, "t=1&amp;u=2") || !strings.Contains(msg.Text, "t=1&u=2") {
+		t.Errorf("html must be escaped and text left as is, html %q text %q", msg.HTML, msg.Text)
+	}
+
+	tests := []struct{ locale, want string }{
+		{locale: "hi-IN", want: "नमस्ते"},
+		{locale: "hi_IN", want: "नमस्ते"},
+		{locale: "fr", want: "Hi Jane"},
+		{locale: "", want: "Hi Jane"},
+	}
+	for _, tt := range tests {
+		msg, err := templates.Render(TemplateRegistration, tt.locale, data[TemplateRegistration])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(msg.Text, tt.want) {
+			t.Errorf("locale %q rendered %q, want %q", tt.locale, msg.Text, tt.want)
+		}
+	}
+	if _, err := templates.Render("unknown", "en", nil); err == nil {
+		t.Error("expected an error for an unknown template")
+	}
+}
+
+func TestSendEmailWithAttachment(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailer := NewLocalMailer("")
+	notifier := NewNotifier(mailer, templates, "BookMyLab <no-reply@bookmylab.com>")
+
+	email, err := NewEmail([]string{"jane@example.com"}, TemplateRegistration, "en",
+		RegistrationData{FirstName: "Jane", Username: "jane_doe_abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.SendEmail(context.Background(), email); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	invite := Attachment{Filename: "visit.ics", Reader: strings.NewReader("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")}
+	if err := notifier.Send(context.Background(), []string{"jane@example.com"}, TemplateRegistration, "en",
+		RegistrationData{FirstName: "Jane"}, invite); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	sent := mailer.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("got %d mails, want 2", len(sent))
+	}
+	raw := string(sent[1].Raw)
+	for _, want := range []string{
+		"From: BookMyLab <no-reply@bookmylab.com>",
+		"To: jane@example.com",
+		"multipart/alternative",
+		"Content-Type: text/plain",
+		"Content-Type: text/html",
+		"Content-Type: text/calendar",
+		`filename=visit.ics`,
+		"QkVHSU46VkNBTEVOREFS",
+	} {
+		if !strings.Contains(raw, want) {
+			t.Errorf("raw message is missing %q:\n%s", want, raw)
+		}
+	}
+}
+
+func TestPreferredLocale(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "fr-FR,fr;q=0.9,en;q=0.8", want: "fr-FR"},
+		{header: "en;q=0.5, hi-IN", want: "hi-IN"},
+		{header: "*", want: ""},
+		{header: "", want: ""},
+		{header: "not a;;language", want: ""},
+	}
+	for _, tt := range tests {
+		if got := PreferredLocale(tt.header); got != tt.want {
+			t.Errorf("PreferredLocale(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSESMailerSendsRawContent(t *testing.T) {
+	var got struct {
+		path, auth string
+		input      sendEmailInput
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.path, got.auth = r.URL.Path, r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got.input); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"MessageId":"1"}`))
+	}))
+	defer server.Close()
+
+	mailer := &SESMailer{
+		client: server.Client(),
+		credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+		}),
+		signer:   v4.NewSigner(),
+		region:   "ap-south-1",
+		endpoint: server.URL,
+	}
+	msg := &Message{From: "no-reply@bookmylab.com", To: []string{"jane@example.com"}, Subject: "Hi", Text: "Hello"}
+	if err := mailer.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.path != "/v2/email/outbound-emails" {
+		t.Errorf("path = %q, want the SES v2 SendEmail operation", got.path)
+	}
+	if !strings.Contains(got.auth, "/ap-south-1/ses/aws4_request") {
+		t.Errorf("Authorization = %q, want a SigV4 signature for ses", got.auth)
+	}
+	if got.input.FromEmailAddress != msg.From || len(got.input.Destination.ToAddresses) != 1 ||
+		!strings.Contains(string(got.input.Content.Raw.Data), "Subject: Hi") {
+		t.Errorf("SendEmail input = %+v", got.input)
+	}
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsCfg "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// sesSigningName is the service name SES v2 requests are signed for
+const sesSigningName = "ses"
+
+// SESMailer sends raw MIME messages with the SES v2 SendEmail operation,
+// credentials come from the default AWS chain
+type SESMailer struct {
+	client      aws.HTTPClient
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	region      string
+	endpoint    string
+}
+
+func NewSESMailer(ctx context.Context, region string) (*SESMailer, error) {
+	cfg, err := awsCfg.LoadDefaultConfig(ctx, awsCfg.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &SESMailer{
+		client:      client,
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		region:      cfg.Region,
+		endpoint:    fmt.Sprintf("https://email.%s.amazonaws.com", cfg.Region),
+	}, nil
+}
+
+// sendEmailInput is the body of SendEmail with Content.Raw, the raw message is
+// base64 encoded by encoding/json like every blob of the SES v2 api
+type sendEmailInput struct {
+	FromEmailAddress string      `json:"FromEmailAddress"`
+	Destination      destination `json:"Destination"`
+	Content          content     `json:"Content"`
+}
+
+type destination struct {
+	ToAddresses []string `json:"ToAddresses"`
+}
+
+type content struct {
+	Raw rawMessage `json:"Raw"`
+}
+
+type rawMessage struct {
+	Data []byte `json:"Data"`
+}
+
+func (s *SESMailer) Send(ctx context.Context, msg *Message) error {
+	raw, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(sendEmailInput{
+		FromEmailAddress: msg.From,
+		Destination:      destination{ToAddresses: msg.To},
+		Content:          content{Raw: rawMessage{Data: raw}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	if err := s.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), sesSigningName, s.region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign SES request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to send email: %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// SMS is a plain text message to a phone number, it can be queued as a job payload
+type SMS struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+// SMSSender delivers text messages, implementations must be safe for concurrent use
+type SMSSender interface {
+	Send(ctx context.Context, sms SMS) error
+}
+
+// LocalSMS logs and keeps the messages, it is the only sender until an sms
+// provider is integrated
+type LocalSMS struct {
+	mu   sync.Mutex
+	sent []SMS
+}
+
+func NewLocalSMS() *LocalSMS {
+	return &LocalSMS{}
+}
+
+func (l *LocalSMS) Send(_ context.Context, sms SMS) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sent = append(l.sent, sms)
+	log.Printf("sms to %s: %s", sms.To, sms.Body)
+	return nil
+}
+
+// Sent returns the messages sent so far
+func (l *LocalSMS) Sent() []SMS {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]SMS{}, l.sent...)
+}
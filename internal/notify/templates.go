@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Template names, every template has an html body and a text fallback whose
+// "subject" block is the subject line
+const (
+	TemplateRegistration   = "registration"
+	TemplateVerification   = "verification"
+	TemplateVisitStatus    = "visit_status"
+	TemplatePasswordReset  = "password_reset"
+	TemplatePriceDrop      = "price_drop"
+	TemplateVisitInvite    = "visit_invite"
+	TemplateVisitReminder  = "visit_reminder"
+	TemplateReviewReceived = "review_received"
+)
+
+// DefaultLocale is used when a template has no variant for the requested locale
+const DefaultLocale = "en"
+
+// maxLocaleLen is the width of the locale column of users
+const maxLocaleLen = 35
+
+// anyLanguage is what the "*" of an Accept-Language header parses to
+var anyLanguage = language.Make("mul")
+
+// PreferredLocale is the locale of the most preferred language of an
+// Accept-Language header, empty when the header names none
+func PreferredLocale(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return ""
+	}
+	for _, tag := range tags {
+		if tag == language.Und || tag == anyLanguage {
+			continue
+		}
+		if locale := tag.String(); len(locale) <= maxLocaleLen {
+			return locale
+		}
+	}
+	return ""
+}
+
+//go:embed templates
+var templateFS embed.FS
+
+type RegistrationData struct {
+	FirstName string
+	Username  string
+}
+
+type VerificationData struct {
+	FirstName string
+	Link      string
+	ExpiresIn time.Duration
+}
+
+type VisitStatusData struct {
+	FirstName      string
+	PropertyTitle  string
+	Status         string
+	ChangedBy      string
+	ScheduledTime  time.Time
+	RescheduleTime *time.Time
+}
+
+type PasswordResetData struct {
+	FirstName string
+	Link      string
+	ExpiresIn time.Duration
+}
+
+type PriceDropData struct {
+	FirstName     string
+	PropertyTitle string
+	City          string
+	Price         float64
+	PreviousPrice float64
+}
+
+type ReviewReceivedData struct {
+	FirstName     string
+	PropertyTitle string
+	Buyer         string
+	Rating        int32
+	ReviewText    string
+}
+
+// VisitInviteData goes with the .ics invite of a visit, Updated is set for
+// the invites following the first one
+type VisitInviteData struct {
+	FirstName     string
+	PropertyTitle string
+	Location      string
+	Start         time.Time
+	Updated       bool
+	Cancelled     bool
+}
+
+// VisitReminderData reminds of an upcoming visit, with FollowUp it asks the
+// partner to mark a past visit completed or no-show
+type VisitReminderData struct {
+	FirstName     string
+	PropertyTitle string
+	Location      string
+	Counterpart   string
+	Start         time.Time
+	FollowUp      bool
+}
+
+type localized struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// Templates holds the parsed templates keyed by locale and name
+type Templates struct {
+	byLocale map[string]map[string]*localized
+}
+
+// LoadTemplates parses the embedded templates, a locale directory must hold
+// both the html and the text file of a template
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{byLocale: map[string]map[string]*localized{}}
+	locales, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, locale := range locales {
+		dir := path.Join("templates", locale.Name())
+		files, err := fs.Glob(templateFS, path.Join(dir, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+		t.byLocale[locale.Name()] = map[string]*localized{}
+		for _, file := range files {
+			name := strings.TrimSuffix(path.Base(file), ".txt")
+			text, err := texttemplate.ParseFS(templateFS, file)
+			if err != nil {
+				return nil, err
+			}
+			if text.Lookup("subject") == nil {
+				return nil, fmt.Errorf("template %s has no subject block", file)
+			}
+			html, err := htmltemplate.ParseFS(templateFS, path.Join(dir, name+".html"))
+			if err != nil {
+				return nil, err
+			}
+			t.byLocale[locale.Name()][name] = &localized{html: html, text: text}
+		}
+	}
+	return t, nil
+}
+
+// Render executes the template for locale, falling back to the base language
+// of the locale and then to DefaultLocale
+func (t *Templates) Render(name, locale string, data any) (*Message, error) {
+	tmpl := t.lookup(name, locale)
+	if tmpl == nil {
+		return nil, fmt.Errorf("unknown mail template %q", name)
+	}
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return nil, err
+	}
+	return &Message{Subject: strings.TrimSpace(subject.String()), Text: text.String(), HTML: html.String()}, nil
+}
+
+func (t *Templates) lookup(name, locale string) *localized {
+	locale = strings.ReplaceAll(locale, "_", "-")
+	base, _, _ := strings.Cut(locale, "-")
+	for _, l := range []string{locale, base, DefaultLocale} {
+		if tmpl, ok := t.byLocale[l][name]; ok {
+			return tmpl
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"booking.com/internal/config"
+)
+
+// Subscription is one open stream of a user. C is closed when the hub drops a
+// subscriber that can't keep up, the client is expected to reconnect and
+// resume from the last ID it saw
+type Subscription struct {
+	C    <-chan Message
+	ch   chan Message
+	user string
+	hub  *Hub
+}
+
+// Close unregisters the subscription, it is safe to call after the hub dropped it
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Hub fans messages out to the open streams of their user and keeps the
+// recent messages of every user for resuming, the history of a user without
+// streams is dropped once it was idle for the configured TTL
+type Hub struct {
+	cfg     config.Realtime
+	mu      sync.Mutex
+	subs    map[string]map[*Subscription]struct{}
+	history map[string][]Message
+	// idle holds when the users with a history but no streams last had activity
+	idle   map[string]time.Time
+	closed bool
+}
+
+func NewHub(cfg config.Realtime) *Hub {
+	return &Hub{
+		cfg:     cfg,
+		subs:    map[string]map[*Subscription]struct{}{},
+		history: map[string][]Message{},
+		idle:    map[string]time.Time{},
+	}
+}
+
+// Run feeds the hub from ps and expires idle histories until ctx is cancelled
+func (h *Hub) Run(ctx context.Context, ps PubSub) error {
+	if h.cfg.HistoryTTL > 0 {
+		go h.expireIdle(ctx)
+	}
+	return ps.Subscribe(ctx, h.Deliver)
+}
+
+func (h *Hub) expireIdle(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.HistoryTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			h.expire(now)
+		}
+	}
+}
+
+// expire drops the history of the users idle for at least the TTL
+func (h *Hub) expire(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for user, since := range h.idle {
+		if now.Sub(since) >= h.cfg.HistoryTTL {
+			delete(h.history, user)
+			delete(h.idle, user)
+		}
+	}
+}
+
+// Subscribe opens a stream for user, the messages after lastID that are still
+// in the history are returned for replay before anything arrives on C
+func (h *Hub) Subscribe(user string, lastID int64) (*Subscription, []Message) {
+	ch := make(chan Message, max(h.cfg.Buffer, 1))
+	sub := &Subscription{C: ch, ch: ch, user: user, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return sub, nil
+	}
+	if h.subs[user] == nil {
+		h.subs[user] = map[*Subscription]struct{}{}
+	}
+	h.subs[user][sub] = struct{}{}
+	delete(h.idle, user)
+	var replay []Message
+	if lastID > 0 {
+		for _, msg := range h.history[user] {
+			if msg.ID > lastID {
+				replay = append(replay, msg)
+			}
+		}
+	}
+	return sub, replay
+}
+
+// Deliver records the message and hands it to the streams of its user without
+// blocking, a stream whose buffer is full is dropped
+func (h *Hub) Deliver(msg Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	history := append(h.history[msg.User], msg)
+	if over := len(history) - h.cfg.History; over > 0 {
+		history = append([]Message{}, history[over:]...)
+	}
+	h.history[msg.User] = history
+	if len(h.subs[msg.User]) == 0 {
+		h.idle[msg.User] = time.Now()
+	}
+
+	for sub := range h.subs[msg.User] {
+		select {
+		case sub.ch <- msg:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribers counts the open streams of user
+func (h *Hub) Subscribers(user string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[user])
+}
+
+// Close ends every open stream and refuses new ones, it lets the http server
+// shut down without waiting for the streams to time out
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove closes and unregisters sub, h.mu must be held
+func (h *Hub) remove(sub *Subscription) {
+	subs := h.subs[sub.user]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.ch)
+	if len(subs) == 0 {
+		delete(h.subs, sub.user)
+		if _, ok := h.history[sub.user]; ok {
+			h.idle[sub.user] = time.Now()
+		}
+	}
+}
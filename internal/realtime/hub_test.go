@@ -0,0 +1,110 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+)
+
+func TestHubReplayAndBackpressure(t *testing.T) {
+	hub := NewHub(config.Realtime{Buffer: 2, History: 3})
+	var sent []Message
+	for i := range 4 {
+		msg, err := NewMessage("alice", TypeNotification, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hub.Deliver(msg)
+		sent = append(sent, msg)
+	}
+	hub.Deliver(Message{ID: nextID(), User: "bob", Type: TypeNotification})
+
+	// only the last 3 messages are kept, the ones after lastID are replayed
+	_, replay := hub.Subscribe("alice", sent[1].ID)
+	if len(replay) != 2 || replay[0].ID != sent[2].ID || replay[1].ID != sent[3].ID {
+		t.Fatalf("replay = %+v, want the last 2 messages", replay)
+	}
+	sub, replay := hub.Subscribe("alice", 0)
+	if len(replay) != 0 {
+		t.Fatalf("replay without lastID = %d messages, want none", len(replay))
+	}
+
+	// the subscriber never reads, the third message overflows its buffer
+	for range 3 {
+		msg, _ := NewMessage("alice", TypeNotification, nil)
+		hub.Deliver(msg)
+	}
+	received := 0
+	for range sub.C {
+		received++
+	}
+	if received != 2 {
+		t.Fatalf("received %d messages before being dropped, want 2", received)
+	}
+	sub.Close()
+
+	hub.Close()
+	closed, _ := hub.Subscribe("bob", 0)
+	if _, ok := <-closed.C; ok {
+		t.Fatal("subscribe after Close must return a closed stream")
+	}
+}
+
+func TestHubRunsOnLocalPubSub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ps := NewLocalPubSub()
+	hub := NewHub(config.Realtime{Buffer: 1, History: 1})
+	sub, _ := hub.Subscribe("alice", 0)
+	go hub.Run(ctx, ps)
+
+	msg, _ := NewMessage("alice", TypeNotification, "hello")
+	deadline := time.After(time.Second)
+	for {
+		// Run subscribes asynchronously, publish until it is listening
+		if err := ps.Publish(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-sub.C:
+			if got.ID != msg.ID || string(got.Data) != `"hello"` {
+				t.Fatalf("got %+v, want %+v", got, msg)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("message never reached the hub")
+		}
+	}
+}
+
+func TestHubExpiresIdleHistory(t *testing.T) {
+	ttl := time.Minute
+	hub := NewHub(config.Realtime{Buffer: 4, History: 10, HistoryTTL: ttl})
+	sub, _ := hub.Subscribe("alice", 0)
+	for _, user := range []string{"alice", "bob"} {
+		msg, _ := NewMessage(user, TypeNotification, nil)
+		hub.Deliver(msg)
+	}
+
+	// bob has no stream, alice keeps hers until after the first sweep
+	hub.expire(time.Now().Add(ttl))
+	if _, ok := hub.history["bob"]; ok {
+		t.Error("history of bob without streams survived the TTL")
+	}
+	if _, ok := hub.history["alice"]; !ok {
+		t.Fatal("history of alice with an open stream expired")
+	}
+
+	sub.Close()
+	hub.expire(time.Now().Add(ttl / 2))
+	if _, ok := hub.history["alice"]; !ok {
+		t.Fatal("history of alice expired before the TTL")
+	}
+	hub.expire(time.Now().Add(ttl))
+	if len(hub.history) != 0 || len(hub.idle) != 0 {
+		t.Errorf("history = %v, idle = %v, want both empty", hub.history, hub.idle)
+	}
+}
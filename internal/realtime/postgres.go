@@ -0,0 +1,100 @@
+package realtime
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// maxNotifyPayload stays below the 8000 byte limit of a postgres NOTIFY payload
+const maxNotifyPayload = 7900
+
+// wire is a Message as sent through NOTIFY, User is not part of the client json
+type wire struct {
+	Message
+	User string `json:"user"`
+}
+
+// PGPubSub fans messages out to every instance with LISTEN/NOTIFY, a
+// notification is only seen by the listeners connected when it is sent
+type PGPubSub struct {
+	pool    *sql.DB
+	channel string
+}
+
+func NewPGPubSub(pool *sql.DB, channel string) *PGPubSub {
+	return &PGPubSub{pool: pool, channel: channel}
+}
+
+func (p *PGPubSub) Publish(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(wire{Message: msg, User: msg.User})
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("%s message of %d bytes exceeds the notify payload limit", msg.Type, len(payload))
+	}
+	_, err = p.pool.ExecContext(ctx, "SELECT pg_notify($1, $2)", p.channel, string(payload))
+	return err
+}
+
+// Subscribe holds one connection of the pool for LISTEN and reconnects after
+// a failure until ctx is cancelled
+func (p *PGPubSub) Subscribe(ctx context.Context, fn func(Message)) error {
+	for {
+		err := p.listen(ctx, fn)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("realtime listener on %s failed, reconnecting, error: %v", p.channel, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (p *PGPubSub) listen(ctx context.Context, fn func(Message)) error {
+	conn, err := p.pool.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("realtime pubsub needs the pgx driver")
+		}
+		pgConn := stdConn.Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+quoteIdent(p.channel)); err != nil {
+			return err
+		}
+		// the session would otherwise keep listening when the connection returns to the pool
+		defer func() { _, _ = pgConn.Exec(context.Background(), "UNLISTEN *") }()
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			var w wire
+			if err := json.Unmarshal([]byte(notification.Payload), &w); err != nil {
+				log.Printf("realtime dropped a malformed notification, error: %v", err)
+				continue
+			}
+			w.Message.User = w.User
+			fn(w.Message)
+		}
+	})
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
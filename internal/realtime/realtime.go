@@ -0,0 +1,121 @@
+// Package realtime pushes per-user messages to the open SSE and WebSocket
+// streams. Messages travel through a PubSub so every api instance receives
+// them and its Hub fans them out to the streams of the user
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+)
+
+// PubSub providers
+const (
+	ProviderLocal    = "local"
+	ProviderPostgres = "postgres"
+)
+
+// Message types besides the visit and review event types
+const (
+	TypeNotification = "notification.created"
+	TypeHeartbeat    = "heartbeat"
+)
+
+// Message is pushed to every stream of User, IDs grow over time so a client
+// resumes with the last ID it saw
+type Message struct {
+	ID   int64           `json:"id"`
+	User string          `json:"-"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+	At   time.Time       `json:"at"`
+}
+
+var lastID atomic.Int64
+
+// NewMessage stamps data for user with a new ID
+func NewMessage(user, msgType string, data any) (Message, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Message{}, fmt.Errorf("marshal %s message: %w", msgType, err)
+	}
+	return Message{ID: nextID(), User: user, Type: msgType, Data: raw, At: time.Now().UTC()}, nil
+}
+
+// nextID is the current time in microseconds, bumped to stay unique within the process
+func nextID() int64 {
+	for {
+		last, now := lastID.Load(), time.Now().UnixMicro()
+		if now <= last {
+			now = last + 1
+		}
+		if lastID.CompareAndSwap(last, now) {
+			return now
+		}
+	}
+}
+
+// Publisher sends a message to the hubs of every instance
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// PubSub connects the publishers to the hubs, Subscribe blocks until ctx is
+// cancelled and calls fn for every published message
+type PubSub interface {
+	Publisher
+	Subscribe(ctx context.Context, fn func(Message)) error
+}
+
+// NewPubSub builds the configured PubSub, the local one only reaches the hub of
+// the same process
+func NewPubSub(cfg config.Realtime, db *dao.Cluster) (PubSub, error) {
+	switch cfg.PubSub {
+	case ProviderLocal, "":
+		return NewLocalPubSub(), nil
+	case ProviderPostgres:
+		return NewPGPubSub(db.Nodes[0].Pool, cfg.Channel), nil
+	default:
+		return nil, fmt.Errorf("unknown realtime pubsub %q", cfg.PubSub)
+	}
+}
+
+// LocalPubSub delivers messages to the subscribers of the same process
+type LocalPubSub struct {
+	mu   sync.RWMutex
+	subs map[int]func(Message)
+	next int
+}
+
+func NewLocalPubSub() *LocalPubSub {
+	return &LocalPubSub{subs: map[int]func(Message){}}
+}
+
+func (l *LocalPubSub) Publish(_ context.Context, msg Message) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, fn := range l.subs {
+		fn(msg)
+	}
+	return nil
+}
+
+func (l *LocalPubSub) Subscribe(ctx context.Context, fn func(Message)) error {
+	l.mu.Lock()
+	id := l.next
+	l.next++
+	l.subs[id] = fn
+	l.mu.Unlock()
+
+	<-ctx.Done()
+	l.mu.Lock()
+	delete(l.subs, id)
+	l.mu.Unlock()
+	return nil
+}
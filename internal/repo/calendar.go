@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"context"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"gorm.io/gorm/clause"
+)
+
+type calendarRepo struct {
+	q *dao.Query
+}
+
+func (r *calendarRepo) SetFeed(ctx context.Context, userName, tokenHash string) error {
+	feed := r.q.CalendarFeed
+	err := feed.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: feed.Username.ColumnName().String()}},
+			DoUpdates: clause.Assignments(map[string]any{feed.TokenHash.ColumnName().String(): tokenHash, feed.CreatedAt.ColumnName().String(): clause.Expr{SQL: "CURRENT_TIMESTAMP"}}),
+		}).
+		Create(&model.CalendarFeed{Username: userName, TokenHash: tokenHash})
+	return dao.TranslateError(err)
+}
+
+func (r *calendarRepo) DeleteFeed(ctx context.Context, userName string) error {
+	feed := r.q.CalendarFeed
+	info, err := feed.WithContext(ctx).Where(feed.Username.Eq(userName)).Delete()
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *calendarRepo) UserByFeed(ctx context.Context, tokenHash string) (string, error) {
+	feed := r.q.CalendarFeed
+	row, err := feed.WithContext(ctx).WriteDB().Where(feed.TokenHash.Eq(tokenHash)).First()
+	if err != nil {
+		return "", dao.TranslateError(err)
+	}
+	return row.Username, nil
+}
@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+)
+
+type idempotencyRepo struct {
+	q *dao.Query
+}
+
+func (r *idempotencyRepo) Reserve(ctx context.Context, key *model.IdempotencyKey) error {
+	idem := r.q.IdempotencyKey
+	// an expired key is free again, the primary key rejects a live one
+	_, err := idem.WithContext(ctx).
+		Where(idem.Username.Eq(key.Username), idem.IdempotencyKey.Eq(key.IdempotencyKey), idem.ExpiresAt.Lt(time.Now().UTC())).
+		Delete()
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	return dao.TranslateError(idem.WithContext(ctx).Create(key))
+}
+
+func (r *idempotencyRepo) Get(ctx context.Context, userName, key string) (*model.IdempotencyKey, error) {
+	idem := r.q.IdempotencyKey
+	row, err := idem.WithContext(ctx).WriteDB().
+		Where(idem.Username.Eq(userName), idem.IdempotencyKey.Eq(key), idem.ExpiresAt.Gte(time.Now().UTC())).
+		First()
+	return row, dao.TranslateError(err)
+}
+
+func (r *idempotencyRepo) Complete(ctx context.Context, userName, key string, statusCode int32, contentType string, response []byte) error {
+	idem := r.q.IdempotencyKey
+	info, err := idem.WithContext(ctx).
+		Where(idem.Username.Eq(userName), idem.IdempotencyKey.Eq(key)).
+		UpdateSimple(idem.StatusCode.Value(statusCode), idem.ContentType.Value(contentType), idem.Response.Value(response))
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *idempotencyRepo) Release(ctx context.Context, userName, key string) error {
+	idem := r.q.IdempotencyKey
+	_, err := idem.WithContext(ctx).Where(idem.Username.Eq(userName), idem.IdempotencyKey.Eq(key)).Delete()
+	return dao.TranslateError(err)
+}
+
+func (r *idempotencyRepo) Prune(ctx context.Context, before time.Time) (int64, error) {
+	idem := r.q.IdempotencyKey
+	info, err := idem.WithContext(ctx).Where(idem.ExpiresAt.Lt(before.UTC())).Delete()
+	return info.RowsAffected, dao.TranslateError(err)
+}
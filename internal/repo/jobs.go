@@ -0,0 +1,138 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/pkg/constants"
+	"gorm.io/gen/field"
+	"gorm.io/gorm/clause"
+)
+
+type jobRepo struct {
+	q *dao.Query
+}
+
+func (r *jobRepo) Enqueue(ctx context.Context, job *model.Job) error {
+	jb := r.q.Job
+	err := jb.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: jb.UniqueKey.ColumnName().String()}}, DoNothing: true}).
+		Create(job)
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	// ON CONFLICT DO NOTHING returns no id for a duplicate
+	if job.ID == 0 {
+		return dao.ErrConflict.WithMsg("job with unique key already exists")
+	}
+	return nil
+}
+
+func (r *jobRepo) Claim(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]*model.Job, error) {
+	var jobs []*model.Job
+	err := r.q.Transaction(func(tx *dao.Query) error {
+		jb := tx.Job
+		now := time.Now().UTC()
+		var err error
+		// running jobs whose lease expired belong to a crashed worker and are picked up again
+		jobs, err = jb.WithContext(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(jb.Kind.In(kinds...), jb.RunAt.Lte(now),
+				field.Or(jb.Status.Eq(constants.JobPending), jb.Status.Eq(constants.JobRunning))).
+			Order(jb.RunAt, jb.ID).Limit(limit).Find()
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+		ids := make([]int64, 0, len(jobs))
+		for _, job := range jobs {
+			ids = append(ids, job.ID)
+			job.Attempts++
+			job.Status = constants.JobRunning
+		}
+		_, err = jb.WithContext(ctx).Where(jb.ID.In(ids...)).
+			UpdateSimple(jb.Status.Value(constants.JobRunning), jb.Attempts.Add(1), jb.RunAt.Value(now.Add(lease)))
+		return err
+	})
+	return jobs, dao.TranslateError(err)
+}
+
+func (r *jobRepo) Complete(ctx context.Context, id int64) error {
+	jb := r.q.Job
+	_, err := jb.WithContext(ctx).Where(jb.ID.Eq(id)).
+		UpdateSimple(jb.Status.Value(constants.JobSucceeded), jb.FinishedAt.Value(time.Now().UTC()), jb.LastError.Value(""))
+	return dao.TranslateError(err)
+}
+
+func (r *jobRepo) Fail(ctx context.Context, id int64, lastErr string, retryAt time.Time, dead bool) error {
+	jb := r.q.Job
+	assigns := []field.AssignExpr{jb.LastError.Value(lastErr), jb.RunAt.Value(retryAt.UTC())}
+	if dead {
+		assigns = append(assigns, jb.Status.Value(constants.JobDead), jb.FinishedAt.Value(time.Now().UTC()))
+	} else {
+		assigns = append(assigns, jb.Status.Value(constants.JobPending))
+	}
+	_, err := jb.WithContext(ctx).Where(jb.ID.Eq(id)).UpdateSimple(assigns...)
+	return dao.TranslateError(err)
+}
+
+func (r *jobRepo) GetByID(ctx context.Context, id int64) (*model.Job, error) {
+	jb := r.q.Job
+	job, err := jb.WithContext(ctx).WriteDB().Where(jb.ID.Eq(id)).First()
+	return job, dao.TranslateError(err)
+}
+
+func (r *jobRepo) Filter(ctx context.Context, filterReq dto.JobFilterReq) ([]*model.Job, int64, error) {
+	jb := r.q.Job
+	q := jb.WithContext(ctx).WriteDB()
+	if filterReq.Status != "" {
+		q = q.Where(jb.Status.Eq(filterReq.Status))
+	}
+	if filterReq.Kind != "" {
+		q = q.Where(jb.Kind.Eq(filterReq.Kind))
+	}
+	q = q.Order(jb.ID.Desc())
+	if filterReq.Limit <= 0 {
+		jobs, err := q.Find()
+		return jobs, int64(len(jobs)), dao.TranslateError(err)
+	}
+	jobs, total, err := q.FindByPage(filterReq.Offset(), filterReq.Limit)
+	return jobs, total, dao.TranslateError(err)
+}
+
+func (r *jobRepo) Retry(ctx context.Context, id int64) error {
+	jb := r.q.Job
+	info, err := jb.WithContext(ctx).
+		Where(jb.ID.Eq(id), jb.Status.In(constants.JobDead, constants.JobCancelled)).
+		UpdateSimple(jb.Status.Value(constants.JobPending), jb.Attempts.Value(0), jb.RunAt.Value(time.Now().UTC()),
+			jb.FinishedAt.Null())
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *jobRepo) Cancel(ctx context.Context, id int64) error {
+	jb := r.q.Job
+	info, err := jb.WithContext(ctx).Where(jb.ID.Eq(id), jb.Status.Eq(constants.JobPending)).
+		UpdateSimple(jb.Status.Value(constants.JobCancelled), jb.FinishedAt.Value(time.Now().UTC()))
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *jobRepo) Prune(ctx context.Context, before time.Time) (int64, error) {
+	jb := r.q.Job
+	info, err := jb.WithContext(ctx).
+		Where(jb.Status.In(constants.JobSucceeded, constants.JobCancelled), jb.FinishedAt.Lt(before.UTC())).Delete()
+	return info.RowsAffected, dao.TranslateError(err)
+}
@@ -0,0 +1,48 @@
+package memrepo
+
+import (
+	"context"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+)
+
+type calendarRepo struct {
+	s *store
+}
+
+func (r *calendarRepo) SetFeed(_ context.Context, userName, tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[userName]; !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	for _, feed := range r.s.feeds {
+		if feed.TokenHash == tokenHash && feed.Username != userName {
+			return dao.ErrConflict
+		}
+	}
+	r.s.feeds[userName] = &model.CalendarFeed{Username: userName, TokenHash: tokenHash, CreatedAt: now()}
+	return nil
+}
+
+func (r *calendarRepo) DeleteFeed(_ context.Context, userName string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.feeds[userName]; !ok {
+		return dao.ErrNotFound
+	}
+	delete(r.s.feeds, userName)
+	return nil
+}
+
+func (r *calendarRepo) UserByFeed(_ context.Context, tokenHash string) (string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, feed := range r.s.feeds {
+		if feed.TokenHash == tokenHash {
+			return feed.Username, nil
+		}
+	}
+	return "", dao.ErrNotFound
+}
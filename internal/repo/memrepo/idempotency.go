@@ -0,0 +1,75 @@
+package memrepo
+
+import (
+	"context"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+)
+
+type idempotencyKey struct {
+	userName, key string
+}
+
+type idempotencyRepo struct {
+	s *store
+}
+
+func (r *idempotencyRepo) Reserve(_ context.Context, key *model.IdempotencyKey) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[key.Username]; !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	id := idempotencyKey{key.Username, key.IdempotencyKey}
+	if row, ok := r.s.idempotency[id]; ok && !row.ExpiresAt.Before(now()) {
+		return dao.ErrConflict
+	}
+	key.CreatedAt = now()
+	r.s.idempotency[id] = clone(key)
+	return nil
+}
+
+func (r *idempotencyRepo) Get(_ context.Context, userName, key string) (*model.IdempotencyKey, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	row, ok := r.s.idempotency[idempotencyKey{userName, key}]
+	if !ok || row.ExpiresAt.Before(now()) {
+		return nil, dao.ErrNotFound
+	}
+	return clone(row), nil
+}
+
+func (r *idempotencyRepo) Complete(_ context.Context, userName, key string, statusCode int32, contentType string, response []byte) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row, ok := r.s.idempotency[idempotencyKey{userName, key}]
+	if !ok {
+		return dao.ErrNotFound
+	}
+	row.StatusCode = statusCode
+	row.ContentType = contentType
+	row.Response = response
+	return nil
+}
+
+func (r *idempotencyRepo) Release(_ context.Context, userName, key string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.idempotency, idempotencyKey{userName, key})
+	return nil
+}
+
+func (r *idempotencyRepo) Prune(_ context.Context, before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for id, row := range r.s.idempotency {
+		if row.ExpiresAt.Before(before) {
+			delete(r.s.idempotency, id)
+			n++
+		}
+	}
+	return n, nil
+}
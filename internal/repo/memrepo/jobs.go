@@ -0,0 +1,168 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/pkg/constants"
+)
+
+type jobRepo struct {
+	s *store
+}
+
+func (r *jobRepo) Enqueue(_ context.Context, job *model.Job) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if job.UniqueKey != nil {
+		for _, j := range r.s.jobs {
+			if j.UniqueKey != nil && *j.UniqueKey == *job.UniqueKey {
+				return dao.ErrConflict.WithMsg("job with unique key already exists")
+			}
+		}
+	}
+	job.ID = r.s.nextID()
+	if job.Status == "" {
+		job.Status = constants.JobPending
+	}
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = 5
+	}
+	if job.Payload == "" {
+		job.Payload = "{}"
+	}
+	job.CreatedAt = now()
+	job.UpdatedAt = job.CreatedAt
+	if job.RunAt.IsZero() {
+		job.RunAt = job.CreatedAt
+	}
+	r.s.jobs[job.ID] = clone(job)
+	return nil
+}
+
+func (r *jobRepo) Claim(_ context.Context, kinds []string, limit int, lease time.Duration) ([]*model.Job, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	due := make([]*model.Job, 0)
+	for _, j := range r.s.jobs {
+		if slices.Contains(kinds, j.Kind) && !j.RunAt.After(now()) &&
+			(j.Status == constants.JobPending || j.Status == constants.JobRunning) {
+			due = append(due, j)
+		}
+	}
+	slices.SortFunc(due, func(a, b *model.Job) int {
+		return cmp.Or(a.RunAt.Compare(b.RunAt), cmp.Compare(a.ID, b.ID))
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]*model.Job, 0, len(due))
+	for _, j := range due {
+		j.Status = constants.JobRunning
+		j.Attempts++
+		j.RunAt = now().Add(lease)
+		j.UpdatedAt = now()
+		claimed = append(claimed, clone(j))
+	}
+	return claimed, nil
+}
+
+func (r *jobRepo) Complete(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if j, ok := r.s.jobs[id]; ok {
+		j.Status = constants.JobSucceeded
+		j.FinishedAt = now()
+		j.LastError = ""
+		j.UpdatedAt = now()
+	}
+	return nil
+}
+
+func (r *jobRepo) Fail(_ context.Context, id int64, lastErr string, retryAt time.Time, dead bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if j, ok := r.s.jobs[id]; ok {
+		j.Status = constants.JobPending
+		if dead {
+			j.Status = constants.JobDead
+			j.FinishedAt = now()
+		}
+		j.LastError = lastErr
+		j.RunAt = retryAt.UTC()
+		j.UpdatedAt = now()
+	}
+	return nil
+}
+
+func (r *jobRepo) GetByID(_ context.Context, id int64) (*model.Job, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	j, ok := r.s.jobs[id]
+	if !ok {
+		return nil, dao.ErrNotFound
+	}
+	return clone(j), nil
+}
+
+func (r *jobRepo) Filter(_ context.Context, filterReq dto.JobFilterReq) ([]*model.Job, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	jobs := make([]*model.Job, 0)
+	for _, j := range r.s.jobs {
+		if (filterReq.Status != "" && j.Status != filterReq.Status) ||
+			(filterReq.Kind != "" && j.Kind != filterReq.Kind) {
+			continue
+		}
+		jobs = append(jobs, clone(j))
+	}
+	slices.SortFunc(jobs, func(a, b *model.Job) int { return cmp.Compare(b.ID, a.ID) })
+	rows, total := page(jobs, filterReq.Offset(), filterReq.Limit)
+	return rows, total, nil
+}
+
+func (r *jobRepo) Retry(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	j, ok := r.s.jobs[id]
+	if !ok || (j.Status != constants.JobDead && j.Status != constants.JobCancelled) {
+		return dao.ErrNotFound
+	}
+	j.Status = constants.JobPending
+	j.Attempts = 0
+	j.RunAt = now()
+	j.FinishedAt = time.Time{}
+	j.UpdatedAt = now()
+	return nil
+}
+
+func (r *jobRepo) Cancel(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	j, ok := r.s.jobs[id]
+	if !ok || j.Status != constants.JobPending {
+		return dao.ErrNotFound
+	}
+	j.Status = constants.JobCancelled
+	j.FinishedAt = now()
+	j.UpdatedAt = now()
+	return nil
+}
+
+func (r *jobRepo) Prune(_ context.Context, before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for id, j := range r.s.jobs {
+		if (j.Status == constants.JobSucceeded || j.Status == constants.JobCancelled) && j.FinishedAt.Before(before) {
+			delete(r.s.jobs, id)
+			n++
+		}
+	}
+	return n, nil
+}
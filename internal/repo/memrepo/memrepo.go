@@ -0,0 +1,216 @@
+// Package memrepo is an in-memory implementation of the repositories used by
+// the service tests, it mirrors the constraints and triggers of the postgres schema
+package memrepo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/repo"
+)
+
+type store struct {
+	mu   sync.RWMutex
+	txMu sync.Mutex
+	tables
+}
+
+// tables are the rows of the store, a failed transaction restores them as a whole
+type tables struct {
+	users         map[string]*model.User
+	properties    map[int64]*model.Property
+	visits        map[int64]*model.Visit
+	outbox        map[int64]*model.Outbox
+	jobs          map[int64]*model.Job
+	notifications map[int64]*model.Notification
+	preferences   map[prefKey]*model.NotificationPreference
+	webhooks      map[int64]*model.Webhook
+	deliveries    map[int64]*model.WebhookDelivery
+	feeds         map[string]*model.CalendarFeed
+	idempotency   map[idempotencyKey]*model.IdempotencyKey
+	ratings       map[int64]*model.Rating
+	lastID        int64
+}
+
+// New returns empty repositories sharing one store
+func New() *repo.Repos {
+	s := &store{tables: tables{
+		users:         map[string]*model.User{},
+		properties:    map[int64]*model.Property{},
+		visits:        map[int64]*model.Visit{},
+		outbox:        map[int64]*model.Outbox{},
+		jobs:          map[int64]*model.Job{},
+		notifications: map[int64]*model.Notification{},
+		preferences:   map[prefKey]*model.NotificationPreference{},
+		webhooks:      map[int64]*model.Webhook{},
+		deliveries:    map[int64]*model.WebhookDelivery{},
+		feeds:         map[string]*model.CalendarFeed{},
+		idempotency:   map[idempotencyKey]*model.IdempotencyKey{},
+		ratings:       map[int64]*model.Rating{},
+	}}
+	repos := &repo.Repos{
+		Users:         &userRepo{s},
+		Properties:    &propertyRepo{s},
+		Visits:        &visitRepo{s},
+		Outbox:        &outboxRepo{s},
+		Jobs:          &jobRepo{s},
+		Notifications: &notificationRepo{s},
+		Webhooks:      &webhookRepo{s},
+		Calendar:      &calendarRepo{s},
+		Idempotency:   &idempotencyRepo{s},
+		Ratings:       &ratingRepo{s},
+	}
+	repos.Transaction = func(_ context.Context, fn func(tx *repo.Repos) error) error {
+		return s.transaction(func() error { return fn(repos) })
+	}
+	return repos
+}
+
+// transaction serializes transactions and restores the store when fn fails,
+// writes made outside a transaction are not isolated from it
+func (s *store) transaction(fn func() error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.RLock()
+	snapshot := s.tables.copy()
+	s.mu.RUnlock()
+
+	err := fn()
+	if err != nil {
+		s.mu.Lock()
+		s.tables = snapshot
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (t *tables) copy() tables {
+	return tables{
+		users:         copyMap(t.users),
+		properties:    copyMap(t.properties),
+		visits:        copyMap(t.visits),
+		outbox:        copyMap(t.outbox),
+		jobs:          copyMap(t.jobs),
+		notifications: copyMap(t.notifications),
+		preferences:   copyMap(t.preferences),
+		webhooks:      copyMap(t.webhooks),
+		deliveries:    copyMap(t.deliveries),
+		feeds:         copyMap(t.feeds),
+		idempotency:   copyMap(t.idempotency),
+		ratings:       copyMap(t.ratings),
+		lastID:        t.lastID,
+	}
+}
+
+func copyMap[K comparable, V any](m map[K]*V) map[K]*V {
+	cp := make(map[K]*V, len(m))
+	for k, v := range m {
+		cp[k] = clone(v)
+	}
+	return cp
+}
+
+func (s *store) nextID() int64 {
+	s.lastID++
+	return s.lastID
+}
+
+// generateUserName follows the generate_unique_username trigger, first4_last4_xxx
+func (s *store) generateUserName(user *model.User) string {
+	base := prefix(user.FirstName) + "_" + prefix(user.LastName) + "_"
+	for {
+		b := make([]byte, 2)
+		_, _ = rand.Read(b)
+		candidate := base + hex.EncodeToString(b)[:3]
+		if _, ok := s.users[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+func prefix(s string) string {
+	s = strings.ToLower(s)
+	if len(s) > 4 {
+		return s[:4]
+	}
+	return s
+}
+
+// mergeNonZero copies the non zero fields of src into dst like gorm Updates does
+func mergeNonZero[T any](dst, src *T) {
+	dv, sv := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	for i := 0; i < sv.NumField(); i++ {
+		if !sv.Field(i).IsZero() {
+			dv.Field(i).Set(sv.Field(i))
+		}
+	}
+}
+
+// copyColumns copies the fields of src stored in the given columns into dst,
+// zero values included like gorm Select with Updates does
+func copyColumns[T any](dst, src *T, columns []string) error {
+	dv, sv := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	fields := map[string]int{}
+	for i := 0; i < sv.NumField(); i++ {
+		for _, setting := range strings.Split(sv.Type().Field(i).Tag.Get("gorm"), ";") {
+			if column, ok := strings.CutPrefix(setting, "column:"); ok {
+				fields[column] = i
+			}
+		}
+	}
+	for _, column := range columns {
+		i, ok := fields[column]
+		if !ok {
+			return fmt.Errorf("unknown column %q", column)
+		}
+		dv.Field(i).Set(sv.Field(i))
+	}
+	return nil
+}
+
+// touch mirrors the update_timestamp and bump_version triggers on a row
+// changed in place, before is a copy of the row taken ahead of the change
+func touch[T any](row *T, before T) {
+	rv, bv := reflect.ValueOf(row).Elem(), reflect.ValueOf(&before).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		switch rv.Type().Field(i).Name {
+		case "Version", "UpdatedAt", "RefreshToken":
+			continue
+		}
+		if !reflect.DeepEqual(rv.Field(i).Interface(), bv.Field(i).Interface()) {
+			version := rv.FieldByName("Version")
+			version.SetInt(version.Int() + 1)
+			break
+		}
+	}
+	rv.FieldByName("UpdatedAt").Set(reflect.ValueOf(now()))
+}
+
+func clone[T any](v *T) *T {
+	cp := *v
+	return &cp
+}
+
+func page[T any](rows []*T, offset, limit int) ([]*T, int64) {
+	total := int64(len(rows))
+	if limit <= 0 {
+		return rows, total
+	}
+	if offset > len(rows) {
+		offset = len(rows)
+	}
+	end := min(offset+limit, len(rows))
+	return rows[offset:end], total
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
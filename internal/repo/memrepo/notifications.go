@@ -0,0 +1,119 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+)
+
+type prefKey struct {
+	userName, notificationType string
+}
+
+type notificationRepo struct {
+	s *store
+}
+
+func (r *notificationRepo) Create(_ context.Context, notification *model.Notification) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[notification.Username]; !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	for _, n := range r.s.notifications {
+		if n.Username == notification.Username && n.Type == notification.Type && n.EventID == notification.EventID {
+			return dao.ErrConflict.WithMsg("user already notified of the event")
+		}
+	}
+	notification.ID = r.s.nextID()
+	if notification.Data == "" {
+		notification.Data = "{}"
+	}
+	notification.CreatedAt = now()
+	r.s.notifications[notification.ID] = clone(notification)
+	return nil
+}
+
+func (r *notificationRepo) Filter(_ context.Context, userName string, filterReq dto.NotificationFilterReq) ([]*model.Notification, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	notifications := make([]*model.Notification, 0)
+	for _, n := range r.s.notifications {
+		if n.Username != userName || (filterReq.Type != "" && n.Type != filterReq.Type) ||
+			(filterReq.UnreadOnly && !n.ReadAt.IsZero()) {
+			continue
+		}
+		notifications = append(notifications, clone(n))
+	}
+	slices.SortFunc(notifications, func(a, b *model.Notification) int { return cmp.Compare(b.ID, a.ID) })
+	rows, total := page(notifications, filterReq.Offset(), filterReq.Limit)
+	return rows, total, nil
+}
+
+func (r *notificationRepo) UnreadCount(_ context.Context, userName string) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var count int64
+	for _, n := range r.s.notifications {
+		if n.Username == userName && n.ReadAt.IsZero() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *notificationRepo) MarkRead(_ context.Context, userName string, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	n, ok := r.s.notifications[id]
+	if !ok || n.Username != userName {
+		return dao.ErrNotFound
+	}
+	if n.ReadAt.IsZero() {
+		n.ReadAt = now()
+	}
+	return nil
+}
+
+func (r *notificationRepo) MarkAllRead(_ context.Context, userName string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var updated int64
+	for _, n := range r.s.notifications {
+		if n.Username == userName && n.ReadAt.IsZero() {
+			n.ReadAt = now()
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (r *notificationRepo) Preferences(_ context.Context, userName string) ([]*model.NotificationPreference, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	prefs := make([]*model.NotificationPreference, 0)
+	for key, pref := range r.s.preferences {
+		if key.userName == userName {
+			prefs = append(prefs, clone(pref))
+		}
+	}
+	slices.SortFunc(prefs, func(a, b *model.NotificationPreference) int { return cmp.Compare(a.Type, b.Type) })
+	return prefs, nil
+}
+
+func (r *notificationRepo) SetPreferences(_ context.Context, prefs ...*model.NotificationPreference) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, pref := range prefs {
+		if _, ok := r.s.users[pref.Username]; !ok {
+			return dao.ErrInvalid.WithMsg("referenced record does not exist")
+		}
+		pref.UpdatedAt = now()
+		r.s.preferences[prefKey{pref.Username, pref.Type}] = clone(pref)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/pkg/constants"
+)
+
+type outboxRepo struct {
+	s *store
+}
+
+func (r *outboxRepo) Add(_ context.Context, events ...*model.Outbox) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, e := range events {
+		e.ID = r.s.nextID()
+		if e.Status == "" {
+			e.Status = constants.EventPending
+		}
+		e.CreatedAt = now()
+		e.UpdatedAt = e.CreatedAt
+		if e.NextAttemptAt.IsZero() {
+			e.NextAttemptAt = e.CreatedAt
+		}
+		r.s.outbox[e.ID] = clone(e)
+	}
+	return nil
+}
+
+func (r *outboxRepo) Claim(_ context.Context, limit int, lease time.Duration) ([]*model.Outbox, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	due := make([]*model.Outbox, 0)
+	for _, e := range r.s.outbox {
+		if e.Status == constants.EventPending && !e.NextAttemptAt.After(now()) {
+			due = append(due, e)
+		}
+	}
+	slices.SortFunc(due, func(a, b *model.Outbox) int { return cmp.Compare(a.ID, b.ID) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]*model.Outbox, 0, len(due))
+	for _, e := range due {
+		e.Attempts++
+		e.NextAttemptAt = now().Add(lease)
+		claimed = append(claimed, clone(e))
+	}
+	return claimed, nil
+}
+
+func (r *outboxRepo) MarkDelivered(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if e, ok := r.s.outbox[id]; ok {
+		e.Status = constants.EventDelivered
+		e.DeliveredAt = now()
+		e.LastError = ""
+		e.UpdatedAt = now()
+	}
+	return nil
+}
+
+func (r *outboxRepo) MarkFailed(_ context.Context, id int64, lastErr string, retryAt time.Time, dead bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if e, ok := r.s.outbox[id]; ok {
+		e.Status = constants.EventPending
+		if dead {
+			e.Status = constants.EventDead
+		}
+		e.LastError = lastErr
+		e.NextAttemptAt = retryAt.UTC()
+		e.UpdatedAt = now()
+	}
+	return nil
+}
+
+// Events returns every outbox row ordered by id, tests use it to assert what was published
+func (r *outboxRepo) Events() []*model.Outbox {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	events := make([]*model.Outbox, 0, len(r.s.outbox))
+	for _, e := range r.s.outbox {
+		events = append(events, clone(e))
+	}
+	slices.SortFunc(events, func(a, b *model.Outbox) int { return cmp.Compare(a.ID, b.ID) })
+	return events
+}
+
+func (r *outboxRepo) Prune(_ context.Context, before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for id, e := range r.s.outbox {
+		if e.Status == constants.EventDelivered && e.CreatedAt.Before(before) {
+			delete(r.s.outbox, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,149 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+)
+
+type propertyRepo struct {
+	s *store
+}
+
+func (r *propertyRepo) Create(_ context.Context, properties ...*model.Property) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, p := range properties {
+		if _, ok := r.s.users[p.PartnerUsername]; !ok {
+			return dao.ErrInvalid.WithMsg("referenced record does not exist")
+		}
+	}
+	for _, p := range properties {
+		p.ID = r.s.nextID()
+		p.CreatedAt = now()
+		p.UpdatedAt = p.CreatedAt
+		p.Version = 1
+		r.s.properties[p.ID] = clone(p)
+	}
+	return nil
+}
+
+// active reports whether neither the property nor its partner is deleted, callers hold the lock
+func (r *propertyRepo) active(p *model.Property) bool {
+	partner, ok := r.s.users[p.PartnerUsername]
+	return !p.Deleted && ok && !partner.Deleted
+}
+
+func (r *propertyRepo) GetByID(_ context.Context, id int64, activeOnly bool) (*model.Property, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	p, ok := r.s.properties[id]
+	if !ok || (activeOnly && !r.active(p)) {
+		return nil, dao.ErrNotFound
+	}
+	return clone(p), nil
+}
+
+func (r *propertyRepo) ListByPartner(_ context.Context, userName string, activeOnly bool) ([]*model.Property, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	properties := make([]*model.Property, 0)
+	for _, p := range r.s.properties {
+		if p.PartnerUsername == userName && (!activeOnly || r.active(p)) {
+			properties = append(properties, clone(p))
+		}
+	}
+	slices.SortFunc(properties, func(a, b *model.Property) int { return cmp.Compare(a.ID, b.ID) })
+	return properties, nil
+}
+
+func (r *propertyRepo) Filter(_ context.Context, userName string, filterReq dto.PropertFilterReq, activeOnly bool) ([]*model.Property, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	minPrice, maxPrice := 0.0, -1.0
+	switch {
+	case filterReq.From_Price != 0 && filterReq.To_Price != 0:
+		minPrice, maxPrice = filterReq.From_Price, filterReq.To_Price
+	case filterReq.From_Price != 0:
+		maxPrice = filterReq.From_Price
+	case filterReq.To_Price != 0:
+		maxPrice = filterReq.To_Price
+	}
+	properties := make([]*model.Property, 0)
+	for _, p := range r.s.properties {
+		if (filterReq.Id != 0 && p.ID != filterReq.Id) ||
+			(filterReq.Title != "" && !strings.Contains(p.Title, filterReq.Title)) ||
+			(filterReq.PartnerName != "" && !strings.Contains(p.PartnerUsername, filterReq.PartnerName)) ||
+			(maxPrice >= 0 && (p.Price < minPrice || p.Price > maxPrice)) ||
+			(filterReq.City != "" && !strings.Contains(p.City, filterReq.City)) ||
+			(filterReq.State != "" && !strings.Contains(p.State, filterReq.State)) ||
+			(filterReq.Status != "" && p.Status != filterReq.Status) ||
+			(filterReq.ExcludeSelf && p.PartnerUsername == userName) ||
+			(activeOnly && !r.active(p)) {
+			continue
+		}
+		properties = append(properties, clone(p))
+	}
+	slices.SortFunc(properties, func(a, b *model.Property) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	rows, total := page(properties, filterReq.Offset(), filterReq.Limit)
+	return rows, total, nil
+}
+
+func (r *propertyRepo) Update(_ context.Context, id int64, version int32, property *model.Property) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	p, ok := r.s.properties[id]
+	ok = ok && !p.Deleted
+	if version != 0 && (!ok || p.Version != version) {
+		return dao.ErrNotFound
+	}
+	if ok {
+		before := *p
+		mergeNonZero(p, property)
+		touch(p, before)
+	}
+	return nil
+}
+
+func (r *propertyRepo) Patch(_ context.Context, id int64, version int32, property *model.Property, columns ...string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	p, ok := r.s.properties[id]
+	ok = ok && !p.Deleted
+	if version != 0 && (!ok || p.Version != version) {
+		return dao.ErrNotFound
+	}
+	if ok {
+		before := *p
+		if err := copyColumns(p, property, columns); err != nil {
+			return err
+		}
+		touch(p, before)
+	}
+	return nil
+}
+
+func (r *propertyRepo) SetDeleted(_ context.Context, id int64, deleted bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	p, ok := r.s.properties[id]
+	if !ok {
+		return dao.ErrNotFound
+	}
+	before := *p
+	p.Deleted = deleted
+	touch(p, before)
+	return nil
+}
+
+// FavoritedBy finds no one, favorites can't be created through the repositories yet
+func (r *propertyRepo) FavoritedBy(_ context.Context, _ int64) ([]string, error) {
+	return nil, nil
+}
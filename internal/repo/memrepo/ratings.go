@@ -0,0 +1,47 @@
+package memrepo
+
+import (
+	"context"
+	"math"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+)
+
+type ratingRepo struct {
+	s *store
+}
+
+func (r *ratingRepo) Create(_ context.Context, rating *model.Rating) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.properties[rating.PropertyID]; !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	if _, ok := r.s.users[rating.BuyerUsername]; !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	partner, ok := r.s.users[rating.PartnerUsername]
+	if !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	sum, count := rating.Rating, 1
+	for _, existing := range r.s.ratings {
+		if existing.Deleted {
+			continue
+		}
+		if existing.PropertyID == rating.PropertyID && existing.BuyerUsername == rating.BuyerUsername {
+			return dao.ErrConflict
+		}
+		if existing.PartnerUsername == rating.PartnerUsername {
+			sum += existing.Rating
+			count++
+		}
+	}
+	rating.ID = r.s.nextID()
+	rating.CreatedAt = now()
+	r.s.ratings[rating.ID] = clone(rating)
+	// update_partner_avg_rating keeps the average of the partner's reviews
+	partner.Rating = math.Round(float64(sum)/float64(count)*100) / 100
+	return nil
+}
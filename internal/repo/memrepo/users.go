@@ -0,0 +1,161 @@
+package memrepo
+
+import (
+	"context"
+	"slices"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	customerrors "booking.com/pkg/custom_errors"
+)
+
+type userRepo struct {
+	s *store
+}
+
+func (r *userRepo) Create(_ context.Context, user *model.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, u := range r.s.users {
+		if u.Email == user.Email {
+			return dao.ErrConflict.WithMsg("email already exists").
+				WithDetails(customerrors.FieldError{Field: "email", Message: "already exists"})
+		}
+		if user.Phone != "" && u.Phone == user.Phone {
+			return dao.ErrConflict.WithMsg("phone already exists").
+				WithDetails(customerrors.FieldError{Field: "phone", Message: "already exists"})
+		}
+	}
+	if user.Username == "" {
+		user.Username = r.s.generateUserName(user)
+	} else if _, ok := r.s.users[user.Username]; ok {
+		return dao.ErrConflict
+	}
+	user.CreatedAt = now()
+	user.UpdatedAt = user.CreatedAt
+	user.Version = 1
+	r.s.users[user.Username] = clone(user)
+	return nil
+}
+
+func (r *userRepo) first(match func(*model.User) bool, activeOnly bool) (*model.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, u := range r.s.users {
+		if activeOnly && u.Deleted {
+			continue
+		}
+		if match(u) {
+			return clone(u), nil
+		}
+	}
+	return nil, dao.ErrNotFound
+}
+
+func (r *userRepo) GetByUserName(_ context.Context, userName string, activeOnly bool) (*model.User, error) {
+	return r.first(func(u *model.User) bool { return u.Username == userName }, activeOnly)
+}
+
+func (r *userRepo) GetByEmailOrPhone(_ context.Context, email, phone string, activeOnly bool) (*model.User, error) {
+	return r.first(func(u *model.User) bool { return u.Email == email || u.Phone == phone }, activeOnly)
+}
+
+func (r *userRepo) GetByEmailAndPhone(_ context.Context, email, phone string, activeOnly bool) (*model.User, error) {
+	return r.first(func(u *model.User) bool { return u.Email == email && u.Phone == phone }, activeOnly)
+}
+
+func (r *userRepo) Filter(_ context.Context, userName, email, phone string, activeOnly bool) ([]*model.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	users := make([]*model.User, 0)
+	for _, u := range r.s.users {
+		if (activeOnly && u.Deleted) ||
+			(userName != "" && u.Username != userName) ||
+			(email != "" && u.Email != email) ||
+			(phone != "" && u.Phone != phone) {
+			continue
+		}
+		users = append(users, clone(u))
+	}
+	return users, nil
+}
+
+func (r *userRepo) List(_ context.Context, offset, limit int) ([]*model.User, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	users := make([]*model.User, 0)
+	for _, u := range r.s.users {
+		if !u.Deleted {
+			users = append(users, clone(u))
+		}
+	}
+	slices.SortFunc(users, func(a, b *model.User) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	rows, total := page(users, offset, limit)
+	return rows, total, nil
+}
+
+func (r *userRepo) Update(_ context.Context, userName string, version int32, user *model.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	u, ok := r.s.users[userName]
+	if version != 0 && (!ok || u.Version != version) {
+		return dao.ErrNotFound
+	}
+	if ok {
+		before := *u
+		mergeNonZero(u, user)
+		touch(u, before)
+	}
+	return nil
+}
+
+func (r *userRepo) Patch(_ context.Context, userName string, version int32, user *model.User, columns ...string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	u, ok := r.s.users[userName]
+	if version != 0 && (!ok || u.Version != version) {
+		return dao.ErrNotFound
+	}
+	if ok {
+		before := *u
+		if err := copyColumns(u, user, columns); err != nil {
+			return err
+		}
+		touch(u, before)
+	}
+	return nil
+}
+
+func (r *userRepo) UpdateRefreshToken(_ context.Context, userName, refreshToken string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if u, ok := r.s.users[userName]; ok && !u.Deleted {
+		u.RefreshToken = refreshToken
+		u.UpdatedAt = now()
+	}
+	return nil
+}
+
+func (r *userRepo) SetDeleted(_ context.Context, userName string, deleted bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if u, ok := r.s.users[userName]; ok {
+		before := *u
+		u.Deleted = deleted
+		touch(u, before)
+	}
+	return nil
+}
+
+func (r *userRepo) AddNoShow(_ context.Context, userName string) (int32, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	u, ok := r.s.users[userName]
+	if !ok {
+		return 0, dao.ErrNotFound
+	}
+	before := *u
+	u.NoShowCount++
+	touch(u, before)
+	return u.NoShowCount, nil
+}
@@ -0,0 +1,91 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+)
+
+type visitRepo struct {
+	s *store
+}
+
+func (r *visitRepo) Create(_ context.Context, visit *model.Visit) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.properties[visit.PropertyID]; !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	if _, ok := r.s.users[visit.BuyerUsername]; !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	visit.ID = r.s.nextID()
+	visit.CreatedAt = now()
+	visit.UpdatedAt = visit.CreatedAt
+	visit.Version = 1
+	r.s.visits[visit.ID] = clone(visit)
+	return nil
+}
+
+func (r *visitRepo) GetByID(_ context.Context, id int64) (*model.Visit, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	v, ok := r.s.visits[id]
+	if !ok || v.Deleted {
+		return nil, dao.ErrNotFound
+	}
+	return clone(v), nil
+}
+
+func (r *visitRepo) Filter(_ context.Context, participant string, filterReq dto.VisitFilterReq) ([]*model.Visit, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	visits := make([]*model.Visit, 0)
+	for _, v := range r.s.visits {
+		p, ok := r.s.properties[v.PropertyID]
+		if !ok || v.Deleted ||
+			(filterReq.Status != "" && v.Status != filterReq.Status) ||
+			(filterReq.PropertyID != 0 && v.PropertyID != filterReq.PropertyID) ||
+			(filterReq.BuyersUserName != "" && v.BuyerUsername != filterReq.BuyersUserName) ||
+			(filterReq.PartnerUserName != "" && p.PartnerUsername != filterReq.PartnerUserName) ||
+			(participant != "" && v.BuyerUsername != participant && p.PartnerUsername != participant) {
+			continue
+		}
+		visits = append(visits, clone(v))
+	}
+	slices.SortFunc(visits, func(a, b *model.Visit) int {
+		return cmp.Or(a.ScheduledTime.Compare(b.ScheduledTime), cmp.Compare(a.ID, b.ID))
+	})
+	rows, total := page(visits, filterReq.Offset(), filterReq.Limit)
+	return rows, total, nil
+}
+
+func (r *visitRepo) Update(_ context.Context, id int64, version int32, visit *model.Visit) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	v, ok := r.s.visits[id]
+	if version != 0 && (!ok || v.Version != version) {
+		return dao.ErrNotFound
+	}
+	if ok {
+		before := *v
+		mergeNonZero(v, visit)
+		touch(v, before)
+	}
+	return nil
+}
+
+func (r *visitRepo) SetDeleted(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if v, ok := r.s.visits[id]; ok {
+		before := *v
+		v.Deleted = true
+		touch(v, before)
+	}
+	return nil
+}
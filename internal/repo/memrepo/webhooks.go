@@ -0,0 +1,123 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+)
+
+type webhookRepo struct {
+	s *store
+}
+
+func (r *webhookRepo) Create(_ context.Context, webhook *model.Webhook) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[webhook.Username]; !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	webhook.ID = r.s.nextID()
+	if webhook.EventTypes == "" {
+		webhook.EventTypes = "[]"
+	}
+	webhook.CreatedAt = now()
+	webhook.UpdatedAt = webhook.CreatedAt
+	r.s.webhooks[webhook.ID] = clone(webhook)
+	return nil
+}
+
+func (r *webhookRepo) GetByID(_ context.Context, id int64) (*model.Webhook, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	webhook, ok := r.s.webhooks[id]
+	if !ok {
+		return nil, dao.ErrNotFound
+	}
+	return clone(webhook), nil
+}
+
+func (r *webhookRepo) ListByUser(_ context.Context, userName string) ([]*model.Webhook, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	webhooks := make([]*model.Webhook, 0)
+	for _, webhook := range r.s.webhooks {
+		if webhook.Username == userName {
+			webhooks = append(webhooks, clone(webhook))
+		}
+	}
+	slices.SortFunc(webhooks, func(a, b *model.Webhook) int { return cmp.Compare(a.ID, b.ID) })
+	return webhooks, nil
+}
+
+func (r *webhookRepo) Update(_ context.Context, webhook *model.Webhook) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.webhooks[webhook.ID]
+	if !ok {
+		return dao.ErrNotFound
+	}
+	current.URL = webhook.URL
+	current.EventTypes = webhook.EventTypes
+	current.Active = webhook.Active
+	current.UpdatedAt = now()
+	return nil
+}
+
+func (r *webhookRepo) Delete(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.webhooks[id]; !ok {
+		return dao.ErrNotFound
+	}
+	delete(r.s.webhooks, id)
+	for deliveryID, delivery := range r.s.deliveries {
+		if delivery.WebhookID == id {
+			delete(r.s.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (r *webhookRepo) AddDelivery(_ context.Context, delivery *model.WebhookDelivery) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.webhooks[delivery.WebhookID]; !ok {
+		return dao.ErrInvalid.WithMsg("referenced record does not exist")
+	}
+	delivery.ID = r.s.nextID()
+	delivery.CreatedAt = now()
+	r.s.deliveries[delivery.ID] = clone(delivery)
+	return nil
+}
+
+func (r *webhookRepo) Deliveries(_ context.Context, webhookID int64, pageReq dto.PageReq) ([]*model.WebhookDelivery, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	deliveries := make([]*model.WebhookDelivery, 0)
+	for _, delivery := range r.s.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, clone(delivery))
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b *model.WebhookDelivery) int { return cmp.Compare(b.ID, a.ID) })
+	rows, total := page(deliveries, pageReq.Offset(), pageReq.Limit)
+	return rows, total, nil
+}
+
+func (r *webhookRepo) PruneDeliveries(_ context.Context, before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for id, delivery := range r.s.deliveries {
+		if delivery.CreatedAt.Before(before) {
+			delete(r.s.deliveries, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"gorm.io/gorm/clause"
+)
+
+type notificationRepo struct {
+	q *dao.Query
+}
+
+func (r *notificationRepo) Create(ctx context.Context, notification *model.Notification) error {
+	ntf := r.q.Notification
+	err := ntf.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: ntf.Username.ColumnName().String()},
+			{Name: ntf.Type.ColumnName().String()}, {Name: ntf.EventID.ColumnName().String()}}, DoNothing: true}).
+		Create(notification)
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	// ON CONFLICT DO NOTHING returns no id for a duplicate
+	if notification.ID == 0 {
+		return dao.ErrConflict.WithMsg("user already notified of the event")
+	}
+	return nil
+}
+
+func (r *notificationRepo) Filter(ctx context.Context, userName string, filterReq dto.NotificationFilterReq) ([]*model.Notification, int64, error) {
+	ntf := r.q.Notification
+	q := ntf.WithContext(ctx).WriteDB().Where(ntf.Username.Eq(userName))
+	if filterReq.Type != "" {
+		q = q.Where(ntf.Type.Eq(filterReq.Type))
+	}
+	if filterReq.UnreadOnly {
+		q = q.Where(ntf.ReadAt.IsNull())
+	}
+	q = q.Order(ntf.ID.Desc())
+	if filterReq.Limit <= 0 {
+		notifications, err := q.Find()
+		return notifications, int64(len(notifications)), dao.TranslateError(err)
+	}
+	notifications, total, err := q.FindByPage(filterReq.Offset(), filterReq.Limit)
+	return notifications, total, dao.TranslateError(err)
+}
+
+func (r *notificationRepo) UnreadCount(ctx context.Context, userName string) (int64, error) {
+	ntf := r.q.Notification
+	count, err := ntf.WithContext(ctx).WriteDB().Where(ntf.Username.Eq(userName), ntf.ReadAt.IsNull()).Count()
+	return count, dao.TranslateError(err)
+}
+
+func (r *notificationRepo) MarkRead(ctx context.Context, userName string, id int64) error {
+	ntf := r.q.Notification
+	notification, err := ntf.WithContext(ctx).WriteDB().Where(ntf.ID.Eq(id), ntf.Username.Eq(userName)).First()
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if !notification.ReadAt.IsZero() {
+		return nil
+	}
+	_, err = ntf.WithContext(ctx).Where(ntf.ID.Eq(id), ntf.ReadAt.IsNull()).
+		UpdateSimple(ntf.ReadAt.Value(time.Now().UTC()))
+	return dao.TranslateError(err)
+}
+
+func (r *notificationRepo) MarkAllRead(ctx context.Context, userName string) (int64, error) {
+	ntf := r.q.Notification
+	info, err := ntf.WithContext(ctx).Where(ntf.Username.Eq(userName), ntf.ReadAt.IsNull()).
+		UpdateSimple(ntf.ReadAt.Value(time.Now().UTC()))
+	return info.RowsAffected, dao.TranslateError(err)
+}
+
+func (r *notificationRepo) Preferences(ctx context.Context, userName string) ([]*model.NotificationPreference, error) {
+	pref := r.q.NotificationPreference
+	prefs, err := pref.WithContext(ctx).WriteDB().Where(pref.Username.Eq(userName)).Order(pref.Type).Find()
+	return prefs, dao.TranslateError(err)
+}
+
+func (r *notificationRepo) SetPreferences(ctx context.Context, prefs ...*model.NotificationPreference) error {
+	pref := r.q.NotificationPreference
+	// the columns are selected so a false channel isn't replaced by the column default
+	err := pref.WithContext(ctx).Select(pref.Username, pref.Type, pref.Inbox, pref.Email, pref.Sms).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: pref.Username.ColumnName().String()}, {Name: pref.Type.ColumnName().String()}},
+			DoUpdates: clause.AssignmentColumns([]string{pref.Inbox.ColumnName().String(), pref.Email.ColumnName().String(), pref.Sms.ColumnName().String()}),
+		}).
+		Create(prefs...)
+	return dao.TranslateError(err)
+}
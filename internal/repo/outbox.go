@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/pkg/constants"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepo struct {
+	q *dao.Query
+}
+
+func (r *outboxRepo) Add(ctx context.Context, events ...*model.Outbox) error {
+	return dao.TranslateError(r.q.Outbox.WithContext(ctx).Create(events...))
+}
+
+func (r *outboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.Outbox, error) {
+	var events []*model.Outbox
+	err := r.q.Transaction(func(tx *dao.Query) error {
+		ob := tx.Outbox
+		now := time.Now().UTC()
+		var err error
+		events, err = ob.WithContext(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(ob.Status.Eq(constants.EventPending), ob.NextAttemptAt.Lte(now)).
+			Order(ob.ID).Limit(limit).Find()
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		ids := make([]int64, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+			event.Attempts++
+		}
+		_, err = ob.WithContext(ctx).Where(ob.ID.In(ids...)).
+			UpdateSimple(ob.Attempts.Add(1), ob.NextAttemptAt.Value(now.Add(lease)))
+		return err
+	})
+	return events, dao.TranslateError(err)
+}
+
+func (r *outboxRepo) MarkDelivered(ctx context.Context, id int64) error {
+	ob := r.q.Outbox
+	_, err := ob.WithContext(ctx).Where(ob.ID.Eq(id)).
+		UpdateSimple(ob.Status.Value(constants.EventDelivered), ob.DeliveredAt.Value(time.Now().UTC()), ob.LastError.Value(""))
+	return dao.TranslateError(err)
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, id int64, lastErr string, retryAt time.Time, dead bool) error {
+	ob := r.q.Outbox
+	status := constants.EventPending
+	if dead {
+		status = constants.EventDead
+	}
+	_, err := ob.WithContext(ctx).Where(ob.ID.Eq(id)).
+		UpdateSimple(ob.Status.Value(status), ob.LastError.Value(lastErr), ob.NextAttemptAt.Value(retryAt.UTC()))
+	return dao.TranslateError(err)
+}
+
+func (r *outboxRepo) Prune(ctx context.Context, before time.Time) (int64, error) {
+	ob := r.q.Outbox
+	info, err := ob.WithContext(ctx).Where(ob.Status.Eq(constants.EventDelivered), ob.CreatedAt.Lt(before.UTC())).Delete()
+	return info.RowsAffected, dao.TranslateError(err)
+}
@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+)
+
+type propertyRepo struct {
+	q *dao.Query
+}
+
+func (r *propertyRepo) Create(ctx context.Context, properties ...*model.Property) error {
+	return dao.TranslateError(r.q.Property.WithContext(ctx).Create(properties...))
+}
+
+func (r *propertyRepo) GetByID(ctx context.Context, id int64, activeOnly bool) (*model.Property, error) {
+	prop, usr := r.q.Property, r.q.User
+	pr := prop.WithContext(ctx).WriteDB().Select(prop.ALL).
+		Join(usr, usr.Username.EqCol(prop.PartnerUsername)).
+		Where(prop.ID.Eq(id))
+	if activeOnly {
+		pr = pr.Where(prop.Deleted.Is(false), usr.Deleted.Is(false))
+	}
+	property, err := pr.First()
+	return property, dao.TranslateError(err)
+}
+
+func (r *propertyRepo) ListByPartner(ctx context.Context, userName string, activeOnly bool) ([]*model.Property, error) {
+	prop, usr := r.q.Property, r.q.User
+	pr := prop.WithContext(ctx).ReadDB().Select(prop.ALL).
+		Join(usr, usr.Username.EqCol(prop.PartnerUsername)).
+		Where(prop.PartnerUsername.Eq(userName))
+	if activeOnly {
+		pr = pr.Where(prop.Deleted.Is(false), usr.Deleted.Is(false))
+	}
+	properties, err := pr.Find()
+	return properties, dao.TranslateError(err)
+}
+
+func (r *propertyRepo) Filter(ctx context.Context, userName string, filterReq dto.PropertFilterReq, activeOnly bool) ([]*model.Property, int64, error) {
+	prop, usr := r.q.Property, r.q.User
+	pr := prop.WithContext(ctx).ReadDB().Select(prop.ALL).
+		LeftJoin(usr, usr.Username.EqCol(prop.PartnerUsername))
+
+	if filterReq.Id != 0 {
+		pr = pr.Where(prop.ID.Eq(filterReq.Id))
+	}
+	if filterReq.Title != "" {
+		pr = pr.Where(prop.Title.Like("%" + filterReq.Title + "%"))
+	}
+	if filterReq.PartnerName != "" {
+		pr = pr.Where(prop.PartnerUsername.Like("%" + filterReq.PartnerName + "%"))
+	}
+
+	if filterReq.From_Price != 0 && filterReq.To_Price != 0 {
+		pr = pr.Where(prop.Price.Between(filterReq.From_Price, filterReq.To_Price))
+	} else if filterReq.From_Price != 0 {
+		pr = pr.Where(prop.Price.Between(0, filterReq.From_Price))
+	} else if filterReq.To_Price != 0 {
+		pr = pr.Where(prop.Price.Between(0, filterReq.To_Price))
+	}
+
+	if filterReq.City != "" {
+		pr = pr.Where(prop.City.Like("%" + filterReq.City + "%"))
+	}
+	if filterReq.State != "" {
+		pr = pr.Where(prop.State.Like("%" + filterReq.State + "%"))
+	}
+	if filterReq.Status != "" {
+		pr = pr.Where(prop.Status.Eq(filterReq.Status))
+	}
+	if filterReq.ExcludeSelf {
+		pr = pr.Where(prop.PartnerUsername.Neq(userName))
+	}
+	if activeOnly {
+		pr = pr.Where(prop.Deleted.Is(false), usr.Deleted.Is(false))
+	}
+
+	pr = pr.Order(prop.CreatedAt.Desc())
+	if filterReq.Limit <= 0 {
+		properties, err := pr.Find()
+		return properties, int64(len(properties)), dao.TranslateError(err)
+	}
+	properties, total, err := pr.FindByPage(filterReq.Offset(), filterReq.Limit)
+	return properties, total, dao.TranslateError(err)
+}
+
+func (r *propertyRepo) Update(ctx context.Context, id int64, version int32, property *model.Property) error {
+	prop := r.q.Property
+	pr := prop.WithContext(ctx).Where(prop.ID.Eq(id), prop.Deleted.Is(false))
+	if version != 0 {
+		pr = pr.Where(prop.Version.Eq(version))
+	}
+	info, err := pr.Updates(property)
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if version != 0 && info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *propertyRepo) Patch(ctx context.Context, id int64, version int32, property *model.Property, columns ...string) error {
+	prop := r.q.Property
+	fields, err := selectColumns(prop.GetFieldByName, columns)
+	if err != nil {
+		return err
+	}
+	pr := prop.WithContext(ctx).Where(prop.ID.Eq(id), prop.Deleted.Is(false))
+	if version != 0 {
+		pr = pr.Where(prop.Version.Eq(version))
+	}
+	info, err := pr.Select(fields...).Updates(property)
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if version != 0 && info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *propertyRepo) SetDeleted(ctx context.Context, id int64, deleted bool) error {
+	prop := r.q.Property
+	info, err := prop.WithContext(ctx).Where(prop.ID.Eq(id)).
+		Select(prop.Deleted).Updates(&model.Property{Deleted: deleted})
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *propertyRepo) FavoritedBy(ctx context.Context, id int64) ([]string, error) {
+	fav, usr := r.q.Favorite, r.q.User
+	var userNames []string
+	err := fav.WithContext(ctx).Join(usr, usr.Username.EqCol(fav.UserUsername)).
+		Where(fav.PropertyID.Eq(id), fav.Deleted.Is(false), usr.Deleted.Is(false)).
+		Pluck(fav.UserUsername, &userNames)
+	return userNames, dao.TranslateError(err)
+}
@@ -0,0 +1,16 @@
+package repo
+
+import (
+	"context"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+)
+
+type ratingRepo struct {
+	q *dao.Query
+}
+
+func (r *ratingRepo) Create(ctx context.Context, rating *model.Rating) error {
+	return dao.TranslateError(r.q.Rating.WithContext(ctx).Create(rating))
+}
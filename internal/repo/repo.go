@@ -0,0 +1,217 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"gorm.io/gen/field"
+)
+
+// UserRepo stores users, lookups with activeOnly skip soft deleted users.
+// Implementations return typed errors, a missing row is dao.ErrNotFound.
+type UserRepo interface {
+	Create(ctx context.Context, user *model.User) error
+	GetByUserName(ctx context.Context, userName string, activeOnly bool) (*model.User, error)
+	GetByEmailOrPhone(ctx context.Context, email, phone string, activeOnly bool) (*model.User, error)
+	GetByEmailAndPhone(ctx context.Context, email, phone string, activeOnly bool) (*model.User, error)
+	Filter(ctx context.Context, userName, email, phone string, activeOnly bool) ([]*model.User, error)
+	List(ctx context.Context, offset, limit int) ([]*model.User, int64, error)
+	// Update writes the non zero fields of user, a non zero version must be the
+	// stored one or dao.ErrNotFound is returned
+	Update(ctx context.Context, userName string, version int32, user *model.User) error
+	// Patch writes the given columns of user, zero values included, with the
+	// same version check as Update
+	Patch(ctx context.Context, userName string, version int32, user *model.User, columns ...string) error
+	UpdateRefreshToken(ctx context.Context, userName, refreshToken string) error
+	SetDeleted(ctx context.Context, userName string, deleted bool) error
+	// AddNoShow counts a no-show of the user and returns the new count
+	AddNoShow(ctx context.Context, userName string) (int32, error)
+}
+
+// PropertyRepo stores properties, activeOnly skips deleted properties and
+// properties of deleted partners
+type PropertyRepo interface {
+	Create(ctx context.Context, properties ...*model.Property) error
+	GetByID(ctx context.Context, id int64, activeOnly bool) (*model.Property, error)
+	ListByPartner(ctx context.Context, userName string, activeOnly bool) ([]*model.Property, error)
+	Filter(ctx context.Context, userName string, filter dto.PropertFilterReq, activeOnly bool) ([]*model.Property, int64, error)
+	// Update writes the non zero fields of property, a non zero version must be
+	// the stored one or dao.ErrNotFound is returned
+	Update(ctx context.Context, id int64, version int32, property *model.Property) error
+	// Patch writes the given columns of property, zero values included, with
+	// the same version check as Update
+	Patch(ctx context.Context, id int64, version int32, property *model.Property, columns ...string) error
+	SetDeleted(ctx context.Context, id int64, deleted bool) error
+	// FavoritedBy returns the active users who saved the property as a favorite
+	FavoritedBy(ctx context.Context, id int64) ([]string, error)
+}
+
+// VisitRepo stores visits, soft deleted visits are never returned
+type VisitRepo interface {
+	Create(ctx context.Context, visit *model.Visit) error
+	GetByID(ctx context.Context, id int64) (*model.Visit, error)
+	// Filter lists visits, a non empty participant limits the result to visits
+	// booked by or to properties owned by that user
+	Filter(ctx context.Context, participant string, filter dto.VisitFilterReq) ([]*model.Visit, int64, error)
+	// Update writes the non zero fields of visit, a non zero version must be
+	// the stored one or dao.ErrNotFound is returned
+	Update(ctx context.Context, id int64, version int32, visit *model.Visit) error
+	SetDeleted(ctx context.Context, id int64) error
+}
+
+// OutboxRepo stores domain events until the dispatcher delivered them
+type OutboxRepo interface {
+	Add(ctx context.Context, events ...*model.Outbox) error
+	// Claim picks up to limit due pending events, skipping rows another dispatcher
+	// holds, counts the attempt and hides them from other dispatchers for lease
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.Outbox, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	// MarkFailed schedules the next attempt at retryAt, dead events are never retried
+	MarkFailed(ctx context.Context, id int64, lastErr string, retryAt time.Time, dead bool) error
+	// Prune deletes delivered events created before the given time
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// JobRepo stores background jobs, conditional updates return dao.ErrNotFound
+// when the job does not exist or is not in the expected status
+type JobRepo interface {
+	// Enqueue stores a job, it returns dao.ErrConflict when the unique key is taken
+	Enqueue(ctx context.Context, job *model.Job) error
+	// Claim picks up to limit due jobs of the given kinds, skipping rows another
+	// worker holds, and marks them running until the lease expires
+	Claim(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]*model.Job, error)
+	Complete(ctx context.Context, id int64) error
+	// Fail schedules the next attempt at retryAt, dead jobs are never retried
+	Fail(ctx context.Context, id int64, lastErr string, retryAt time.Time, dead bool) error
+	GetByID(ctx context.Context, id int64) (*model.Job, error)
+	Filter(ctx context.Context, filter dto.JobFilterReq) ([]*model.Job, int64, error)
+	// Retry makes a dead or cancelled job due now with a fresh attempt budget
+	Retry(ctx context.Context, id int64) error
+	// Cancel stops a pending job from running
+	Cancel(ctx context.Context, id int64) error
+	// Prune deletes succeeded and cancelled jobs finished before the given time
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NotificationRepo stores the in-app inbox and the channel preferences of users
+type NotificationRepo interface {
+	// Create adds a notification, it returns dao.ErrConflict when the user was
+	// already notified of the same event with the same type
+	Create(ctx context.Context, notification *model.Notification) error
+	// Filter lists the notifications of the user, newest first
+	Filter(ctx context.Context, userName string, filter dto.NotificationFilterReq) ([]*model.Notification, int64, error)
+	UnreadCount(ctx context.Context, userName string) (int64, error)
+	// MarkRead marks a notification read, it returns dao.ErrNotFound when the
+	// user has no such notification
+	MarkRead(ctx context.Context, userName string, id int64) error
+	MarkAllRead(ctx context.Context, userName string) (int64, error)
+	// Preferences returns the stored preferences, types without a row are left out
+	Preferences(ctx context.Context, userName string) ([]*model.NotificationPreference, error)
+	// SetPreferences inserts or replaces the preferences of the given types
+	SetPreferences(ctx context.Context, prefs ...*model.NotificationPreference) error
+}
+
+// WebhookRepo stores the webhooks of partners and the log of their deliveries
+type WebhookRepo interface {
+	Create(ctx context.Context, webhook *model.Webhook) error
+	GetByID(ctx context.Context, id int64) (*model.Webhook, error)
+	ListByUser(ctx context.Context, userName string) ([]*model.Webhook, error)
+	// Update writes the url, event types and active flag of the webhook
+	Update(ctx context.Context, webhook *model.Webhook) error
+	// Delete removes the webhook with its deliveries
+	Delete(ctx context.Context, id int64) error
+	AddDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	// Deliveries lists the deliveries of the webhook, newest first
+	Deliveries(ctx context.Context, webhookID int64, page dto.PageReq) ([]*model.WebhookDelivery, int64, error)
+	// PruneDeliveries deletes deliveries logged before the given time
+	PruneDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
+
+// CalendarRepo stores the feed token of every user, only its hash is kept
+type CalendarRepo interface {
+	// SetFeed inserts or replaces the token hash of the user
+	SetFeed(ctx context.Context, userName, tokenHash string) error
+	// DeleteFeed revokes the feed, it returns dao.ErrNotFound when the user has none
+	DeleteFeed(ctx context.Context, userName string) error
+	// UserByFeed returns the owner of the token hash or dao.ErrNotFound
+	UserByFeed(ctx context.Context, tokenHash string) (string, error)
+}
+
+// RatingRepo stores the reviews buyers leave on properties
+type RatingRepo interface {
+	// Create inserts the review, it returns dao.ErrConflict when the buyer
+	// already reviewed the property
+	Create(ctx context.Context, rating *model.Rating) error
+}
+
+// IdempotencyRepo stores the response of a request per user and idempotency
+// key, expired keys are treated as missing
+type IdempotencyRepo interface {
+	// Reserve stores a key without a response, it returns dao.ErrConflict when
+	// the user holds the key and it didn't expire
+	Reserve(ctx context.Context, key *model.IdempotencyKey) error
+	Get(ctx context.Context, userName, key string) (*model.IdempotencyKey, error)
+	// Complete stores the response of a reserved key
+	Complete(ctx context.Context, userName, key string, statusCode int32, contentType string, response []byte) error
+	// Release deletes the key so the request can run again
+	Release(ctx context.Context, userName, key string) error
+	// Prune deletes the keys that expired before the given time
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Repos groups the repositories injected into the services
+type Repos struct {
+	Users         UserRepo
+	Properties    PropertyRepo
+	Visits        VisitRepo
+	Outbox        OutboxRepo
+	Jobs          JobRepo
+	Notifications NotificationRepo
+	Webhooks      WebhookRepo
+	Calendar      CalendarRepo
+	Idempotency   IdempotencyRepo
+	Ratings       RatingRepo
+	// Transaction runs fn with repositories bound to a single transaction, it
+	// commits when fn returns nil and rolls back otherwise
+	Transaction func(ctx context.Context, fn func(tx *Repos) error) error
+}
+
+// NewGormRepos returns repositories backed by the generated gorm dao. Property
+// search and listing read from the replicas, everything else including auth
+// lookups stays on the primary so a user always reads their own writes
+func NewGormRepos(q *dao.Query) *Repos {
+	return &Repos{
+		Users:         &userRepo{q: q},
+		Properties:    &propertyRepo{q: q},
+		Visits:        &visitRepo{q: q},
+		Outbox:        &outboxRepo{q: q},
+		Jobs:          &jobRepo{q: q},
+		Notifications: &notificationRepo{q: q},
+		Webhooks:      &webhookRepo{q: q},
+		Calendar:      &calendarRepo{q: q},
+		Idempotency:   &idempotencyRepo{q: q},
+		Ratings:       &ratingRepo{q: q},
+		Transaction: func(ctx context.Context, fn func(tx *Repos) error) error {
+			return q.Transaction(func(tx *dao.Query) error {
+				return fn(NewGormRepos(tx))
+			})
+		},
+	}
+}
+
+// selectColumns resolves column names to the fields of a generated dao
+func selectColumns(fieldByName func(string) (field.OrderExpr, bool), columns []string) ([]field.Expr, error) {
+	fields := make([]field.Expr, 0, len(columns))
+	for _, column := range columns {
+		f, ok := fieldByName(column)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"context"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"gorm.io/gen/field"
+)
+
+type userRepo struct {
+	q *dao.Query
+}
+
+func (r *userRepo) Create(ctx context.Context, user *model.User) error {
+	return dao.TranslateError(r.q.User.WithContext(ctx).Save(user))
+}
+
+func (r *userRepo) GetByUserName(ctx context.Context, userName string, activeOnly bool) (*model.User, error) {
+	usr := r.q.User
+	q := usr.WithContext(ctx).WriteDB().Where(usr.Username.Eq(userName))
+	if activeOnly {
+		q = q.Where(usr.Deleted.Is(false))
+	}
+	user, err := q.First()
+	return user, dao.TranslateError(err)
+}
+
+func (r *userRepo) GetByEmailOrPhone(ctx context.Context, email, phone string, activeOnly bool) (*model.User, error) {
+	usr := r.q.User
+	q := usr.WithContext(ctx).WriteDB().Where(field.Or(usr.Email.Eq(email), usr.Phone.Eq(phone)))
+	if activeOnly {
+		q = q.Where(usr.Deleted.Is(false))
+	}
+	user, err := q.First()
+	return user, dao.TranslateError(err)
+}
+
+func (r *userRepo) GetByEmailAndPhone(ctx context.Context, email, phone string, activeOnly bool) (*model.User, error) {
+	usr := r.q.User
+	q := usr.WithContext(ctx).WriteDB().Where(usr.Email.Eq(email), usr.Phone.Eq(phone))
+	if activeOnly {
+		q = q.Where(usr.Deleted.Is(false))
+	}
+	user, err := q.First()
+	return user, dao.TranslateError(err)
+}
+
+func (r *userRepo) Filter(ctx context.Context, userName, email, phone string, activeOnly bool) ([]*model.User, error) {
+	usr := r.q.User
+	q := usr.WithContext(ctx).WriteDB()
+	if activeOnly {
+		q = q.Where(usr.Deleted.Is(false))
+	}
+	if userName != "" {
+		q = q.Where(usr.Username.Eq(userName))
+	}
+	if email != "" {
+		q = q.Where(usr.Email.Eq(email))
+	}
+	if phone != "" {
+		q = q.Where(usr.Phone.Eq(phone))
+	}
+	users, err := q.Find()
+	return users, dao.TranslateError(err)
+}
+
+func (r *userRepo) List(ctx context.Context, offset, limit int) ([]*model.User, int64, error) {
+	usr := r.q.User
+	users, total, err := usr.WithContext(ctx).WriteDB().Where(usr.Deleted.Is(false)).Order(usr.CreatedAt).FindByPage(offset, limit)
+	return users, total, dao.TranslateError(err)
+}
+
+func (r *userRepo) Update(ctx context.Context, userName string, version int32, user *model.User) error {
+	usr := r.q.User
+	us := usr.WithContext(ctx).Where(usr.Username.Eq(userName))
+	if version != 0 {
+		us = us.Where(usr.Version.Eq(version))
+	}
+	info, err := us.Updates(user)
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if version != 0 && info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepo) Patch(ctx context.Context, userName string, version int32, user *model.User, columns ...string) error {
+	usr := r.q.User
+	fields, err := selectColumns(usr.GetFieldByName, columns)
+	if err != nil {
+		return err
+	}
+	us := usr.WithContext(ctx).Where(usr.Username.Eq(userName))
+	if version != 0 {
+		us = us.Where(usr.Version.Eq(version))
+	}
+	info, err := us.Select(fields...).Updates(user)
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if version != 0 && info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepo) UpdateRefreshToken(ctx context.Context, userName, refreshToken string) error {
+	usr := r.q.User
+	_, err := usr.WithContext(ctx).Where(usr.Deleted.Is(false), usr.Username.Eq(userName)).
+		Select(usr.RefreshToken).Updates(&model.User{RefreshToken: refreshToken})
+	return dao.TranslateError(err)
+}
+
+func (r *userRepo) SetDeleted(ctx context.Context, userName string, deleted bool) error {
+	usr := r.q.User
+	_, err := usr.WithContext(ctx).Where(usr.Username.Eq(userName)).
+		Select(usr.Deleted).Updates(&model.User{Deleted: deleted})
+	return dao.TranslateError(err)
+}
+
+func (r *userRepo) AddNoShow(ctx context.Context, userName string) (int32, error) {
+	usr := r.q.User
+	if _, err := usr.WithContext(ctx).Where(usr.Username.Eq(userName)).UpdateSimple(usr.NoShowCount.Add(1)); err != nil {
+		return 0, dao.TranslateError(err)
+	}
+	user, err := usr.WithContext(ctx).Select(usr.NoShowCount).Where(usr.Username.Eq(userName)).First()
+	if err != nil {
+		return 0, dao.TranslateError(err)
+	}
+	return user.NoShowCount, nil
+}
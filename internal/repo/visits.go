@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"gorm.io/gen/field"
+)
+
+type visitRepo struct {
+	q *dao.Query
+}
+
+func (r *visitRepo) Create(ctx context.Context, visit *model.Visit) error {
+	return dao.TranslateError(r.q.Visit.WithContext(ctx).Save(visit))
+}
+
+func (r *visitRepo) GetByID(ctx context.Context, id int64) (*model.Visit, error) {
+	vst := r.q.Visit
+	visit, err := vst.WithContext(ctx).WriteDB().Where(vst.ID.Eq(id), vst.Deleted.Is(false)).First()
+	return visit, dao.TranslateError(err)
+}
+
+func (r *visitRepo) Filter(ctx context.Context, participant string, filterReq dto.VisitFilterReq) ([]*model.Visit, int64, error) {
+	vst, prop := r.q.Visit, r.q.Property
+	q := vst.WithContext(ctx).WriteDB().Select(vst.ALL).
+		Join(prop, prop.ID.EqCol(vst.PropertyID)).
+		Where(vst.Deleted.Is(false))
+	if filterReq.Status != "" {
+		q = q.Where(vst.Status.Eq(filterReq.Status))
+	}
+	if filterReq.PropertyID != 0 {
+		q = q.Where(vst.PropertyID.Eq(filterReq.PropertyID))
+	}
+	if filterReq.BuyersUserName != "" {
+		q = q.Where(vst.BuyerUsername.Eq(filterReq.BuyersUserName))
+	}
+	if filterReq.PartnerUserName != "" {
+		q = q.Where(prop.PartnerUsername.Eq(filterReq.PartnerUserName))
+	}
+	if participant != "" {
+		q = q.Where(field.Or(
+			vst.BuyerUsername.Eq(participant),
+			prop.PartnerUsername.Eq(participant),
+		))
+	}
+	q = q.Order(vst.ScheduledTime)
+	if filterReq.Limit <= 0 {
+		visits, err := q.Find()
+		return visits, int64(len(visits)), dao.TranslateError(err)
+	}
+	visits, total, err := q.FindByPage(filterReq.Offset(), filterReq.Limit)
+	return visits, total, dao.TranslateError(err)
+}
+
+func (r *visitRepo) Update(ctx context.Context, id int64, version int32, visit *model.Visit) error {
+	vst := r.q.Visit
+	vs := vst.WithContext(ctx).Where(vst.ID.Eq(id))
+	if version != 0 {
+		vs = vs.Where(vst.Version.Eq(version))
+	}
+	info, err := vs.Updates(visit)
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if version != 0 && info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *visitRepo) SetDeleted(ctx context.Context, id int64) error {
+	vst := r.q.Visit
+	_, err := vst.WithContext(ctx).Where(vst.ID.Eq(id)).
+		Select(vst.Deleted).Updates(&model.Visit{Deleted: true})
+	return dao.TranslateError(err)
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+)
+
+type webhookRepo struct {
+	q *dao.Query
+}
+
+func (r *webhookRepo) Create(ctx context.Context, webhook *model.Webhook) error {
+	return dao.TranslateError(r.q.Webhook.WithContext(ctx).Create(webhook))
+}
+
+func (r *webhookRepo) GetByID(ctx context.Context, id int64) (*model.Webhook, error) {
+	wh := r.q.Webhook
+	webhook, err := wh.WithContext(ctx).WriteDB().Where(wh.ID.Eq(id)).First()
+	return webhook, dao.TranslateError(err)
+}
+
+func (r *webhookRepo) ListByUser(ctx context.Context, userName string) ([]*model.Webhook, error) {
+	wh := r.q.Webhook
+	webhooks, err := wh.WithContext(ctx).WriteDB().Where(wh.Username.Eq(userName)).Order(wh.ID).Find()
+	return webhooks, dao.TranslateError(err)
+}
+
+func (r *webhookRepo) Update(ctx context.Context, webhook *model.Webhook) error {
+	wh := r.q.Webhook
+	// the columns are selected so deactivating isn't skipped as a zero value
+	info, err := wh.WithContext(ctx).Where(wh.ID.Eq(webhook.ID)).
+		Select(wh.URL, wh.EventTypes, wh.Active).Updates(webhook)
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepo) Delete(ctx context.Context, id int64) error {
+	wh := r.q.Webhook
+	info, err := wh.WithContext(ctx).Where(wh.ID.Eq(id)).Delete()
+	if err != nil {
+		return dao.TranslateError(err)
+	}
+	if info.RowsAffected == 0 {
+		return dao.ErrNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepo) AddDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return dao.TranslateError(r.q.WebhookDelivery.WithContext(ctx).Create(delivery))
+}
+
+func (r *webhookRepo) Deliveries(ctx context.Context, webhookID int64, page dto.PageReq) ([]*model.WebhookDelivery, int64, error) {
+	dlv := r.q.WebhookDelivery
+	q := dlv.WithContext(ctx).WriteDB().Where(dlv.WebhookID.Eq(webhookID)).Order(dlv.ID.Desc())
+	if page.Limit <= 0 {
+		deliveries, err := q.Find()
+		return deliveries, int64(len(deliveries)), dao.TranslateError(err)
+	}
+	deliveries, total, err := q.FindByPage(page.Offset(), page.Limit)
+	return deliveries, total, dao.TranslateError(err)
+}
+
+func (r *webhookRepo) PruneDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	dlv := r.q.WebhookDelivery
+	info, err := dlv.WithContext(ctx).Where(dlv.CreatedAt.Lt(before.UTC())).Delete()
+	return info.RowsAffected, dao.TranslateError(err)
+}
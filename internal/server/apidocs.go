@@ -0,0 +1,176 @@
+package server
+
+import (
+	"net/http"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/dto"
+	"booking.com/internal/health"
+	"booking.com/internal/server/middleware"
+	"booking.com/internal/server/openapi"
+	"booking.com/pkg/constants"
+)
+
+// apiOperations documents every route registered in server.go,
+// TestSpecCoversRoutes fails when a route is added without an entry here
+var apiOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/health", Tag: "system", Summary: "Liveness check", Unversioned: true},
+	{Method: http.MethodGet, Path: "/health/db", Tag: "system", Summary: "Ping the primary and every read replica, only the role and state of each node are shown", Unversioned: true,
+		Response: []middleware.NodeState{}, Errors: []int{http.StatusServiceUnavailable}},
+	{Method: http.MethodGet, Path: "/livez", Tag: "system", Summary: "Liveness probe", Unversioned: true,
+		RawResponse: probeResponse("process is serving requests")},
+	{Method: http.MethodGet, Path: "/readyz", Tag: "system", Summary: "Readiness probe with a breakdown per dependency", Unversioned: true,
+		RawResponse: probeResponse("all dependencies are up, 503 with the same body when one is down or the server is draining")},
+	{Method: http.MethodGet, Path: "/openapi.json", Tag: "system", Summary: "OpenAPI document", Unversioned: true,
+		RawResponse: &openapi.Response{Description: "OpenAPI 3.1 document", Content: map[string]*openapi.MediaType{constants.ContentTypeJson: {Schema: &openapi.Schema{Type: "object"}}}}},
+	{Method: http.MethodGet, Path: "/docs", Tag: "system", Summary: "Swagger UI", Unversioned: true,
+		RawResponse: &openapi.Response{Description: "Swagger UI page", Content: map[string]*openapi.MediaType{"text/html": {Schema: &openapi.Schema{Type: "string"}}}}},
+	{Method: http.MethodGet, Path: "/docs/assets/*filepath", Tag: "system", Summary: "Swagger UI scripts and styles, served from the binary", Unversioned: true,
+		RawResponse: &openapi.Response{Description: "asset file, 404 when it does not exist"}},
+
+	{Method: http.MethodPost, Path: "/auth/register", Tag: "auth", Summary: "Register a new user",
+		Body: dto.CreateUser{}, Status: http.StatusCreated, Errors: []int{http.StatusConflict}},
+	{Method: http.MethodPost, Path: "/auth/login", Tag: "auth", Summary: "Login with email or phone, native clients get the refresh token in the response instead of a cookie",
+		Body: dto.Login{}, Response: dto.TokenRsp{}, Errors: []int{http.StatusUnauthorized}},
+	{Method: http.MethodPost, Path: "/auth/refresh", Tag: "auth", Summary: "Rotate the access and refresh tokens, native clients send the refresh token in the Authorization header or the body",
+		Auth: openapi.RefreshCookieAuth, Body: dto.RefreshReq{}, OptionalBody: true, Response: dto.TokenRsp{}, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodPost, Path: "/auth/logout", Tag: "auth", Summary: "Revoke the refresh token, native clients send it in the Authorization header or the body",
+		Auth: openapi.RefreshCookieAuth, Body: dto.RefreshReq{}, OptionalBody: true, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodPatch, Path: "/auth/activate", Tag: "auth", Summary: "Re-activate a user who deleted their account, users deactivated by an admin are refused",
+		Body: dto.Activate{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+
+	{Method: http.MethodGet, Path: "/user/profile", Tag: "users", Summary: "Current user profile",
+		Auth: openapi.BearerAuth, Response: dto.UserRsp{}, Errors: []int{http.StatusNotFound}, Conditional: true},
+	{Method: http.MethodPut, Path: "/user/update", Tag: "users", Summary: "Update the current user",
+		Auth: openapi.BearerAuth, Body: dto.UpdateUser{}, Status: http.StatusAccepted, Errors: []int{http.StatusNotFound}, Conditional: true},
+	{Method: http.MethodPatch, Path: "/user/profile", Tag: "users", Summary: "Change some fields of the current user, null clears the address",
+		Auth: openapi.BearerAuth, Body: dto.PatchUser{}, Response: dto.UserRsp{}, Errors: []int{http.StatusNotFound}, Conditional: true, MergePatch: true},
+	{Method: http.MethodGet, Path: "/user/list", Tag: "users", Summary: "List users (admin)",
+		Auth: openapi.BearerAuth, Query: dto.PageReq{}, Response: []dto.UserRsp{}, Paged: true, Errors: []int{http.StatusForbidden, http.StatusNotFound}, Conditional: true},
+	{Method: http.MethodPatch, Path: "/user/update-role", Tag: "users", Summary: "Change the role of a user (admin)",
+		Auth: openapi.BearerAuth, Body: dto.UserRoleReq{}, Status: http.StatusAccepted, Errors: []int{http.StatusForbidden, http.StatusNotFound}, Conditional: true},
+	{Method: http.MethodDelete, Path: "/user/profile", Tag: "users", Summary: "Delete the current user",
+		Auth: openapi.BearerAuth, Status: http.StatusAccepted, Errors: []int{http.StatusConflict}},
+
+	{Method: http.MethodGet, Path: "/properties/all", Tag: "properties", Summary: "List all listed properties",
+		Query: dto.PageReq{}, Response: []dto.PropertyRsp{}, Paged: true, Errors: []int{http.StatusNotFound}, Conditional: true},
+	{Method: http.MethodPost, Path: "/properties", Tag: "properties", Summary: "List new properties",
+		Auth: openapi.BearerAuth, Body: []dto.AddPropertyReq{}, Status: http.StatusCreated, Idempotent: true},
+	{Method: http.MethodPut, Path: "/properties", Tag: "properties", Summary: "Update a property",
+		Auth: openapi.BearerAuth, Body: dto.UpdatePropertyReq{}, Status: http.StatusCreated, Errors: []int{http.StatusForbidden, http.StatusNotFound}, Conditional: true},
+	{Method: http.MethodGet, Path: "/properties", Tag: "properties", Summary: "Search properties",
+		Auth: openapi.BearerAuth, Query: dto.PropertFilterReq{}, Response: []dto.PropertyRsp{}, Paged: true, Errors: []int{http.StatusNotFound}, Conditional: true},
+	{Method: http.MethodGet, Path: "/properties/:id", Tag: "properties", Summary: "Get a property",
+		Auth: openapi.BearerAuth, Params: dto.GetProperty{}, Response: dto.PropertyRsp{}, Errors: []int{http.StatusNotFound}, Conditional: true},
+	{Method: http.MethodPatch, Path: "/properties/:id", Tag: "properties", Summary: "Change some fields of a property, null clears the optional ones",
+		Auth: openapi.BearerAuth, Params: dto.GetProperty{}, Body: dto.PatchPropertyReq{}, Response: dto.PropertyRsp{},
+		Errors: []int{http.StatusForbidden, http.StatusNotFound}, Conditional: true, MergePatch: true},
+	{Method: http.MethodDelete, Path: "/properties/:id", Tag: "properties", Summary: "Delete a property",
+		Auth: openapi.BearerAuth, Params: dto.GetProperty{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodPost, Path: "/properties/:id/reviews", Tag: "properties", Summary: "Review a property, only buyers whose visit to it was completed can review it once",
+		Auth: openapi.BearerAuth, Params: dto.GetProperty{}, Body: dto.CreateReviewReq{}, Response: dto.ReviewRsp{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}, Idempotent: true},
+
+	{Method: http.MethodPost, Path: "/visits", Tag: "visits", Summary: "Schedule a visit, buyers with repeated no-shows are temporarily restricted",
+		Auth: openapi.BearerAuth, Body: dto.ScheduleReq{}, Status: http.StatusCreated, Errors: []int{http.StatusForbidden, http.StatusNotFound}, Idempotent: true},
+	{Method: http.MethodPut, Path: "/visits", Tag: "visits", Summary: "Change the status of a visit, partners mark visits that took place completed or no_show",
+		Auth: openapi.BearerAuth, Body: dto.UpdateVisitReq{}, Status: http.StatusAccepted, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}, Conditional: true},
+	{Method: http.MethodGet, Path: "/visits", Tag: "visits", Summary: "List visits of the current user",
+		Auth: openapi.BearerAuth, Query: dto.VisitFilterReq{}, Response: []dto.VisitRsp{}, Paged: true, Conditional: true},
+	{Method: http.MethodDelete, Path: "/visits/:id", Tag: "visits", Summary: "Delete a visit",
+		Auth: openapi.BearerAuth, Params: dto.GetVisit{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+
+	{Method: http.MethodGet, Path: "/notifications", Tag: "notifications", Summary: "List the inbox of the current user, newest first",
+		Auth: openapi.BearerAuth, Query: dto.NotificationFilterReq{}, Response: []dto.NotificationRsp{}, Paged: true},
+	{Method: http.MethodGet, Path: "/notifications/unread-count", Tag: "notifications", Summary: "Count unread notifications",
+		Auth: openapi.BearerAuth, Response: dto.UnreadCountRsp{}},
+	{Method: http.MethodPatch, Path: "/notifications/:id/read", Tag: "notifications", Summary: "Mark a notification read",
+		Auth: openapi.BearerAuth, Params: dto.GetNotification{}, Status: http.StatusAccepted, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodPatch, Path: "/notifications/read-all", Tag: "notifications", Summary: "Mark every notification read",
+		Auth: openapi.BearerAuth, Response: dto.MarkAllReadRsp{}, Status: http.StatusAccepted},
+	{Method: http.MethodGet, Path: "/notifications/preferences", Tag: "notifications", Summary: "Get the channels of every notification type",
+		Auth: openapi.BearerAuth, Response: []dto.NotificationPreference{}},
+	{Method: http.MethodPut, Path: "/notifications/preferences", Tag: "notifications", Summary: "Choose inbox, email and sms per notification type",
+		Auth: openapi.BearerAuth, Body: dto.UpdatePreferencesReq{}, Response: []dto.NotificationPreference{}, Status: http.StatusAccepted},
+
+	{Method: http.MethodPost, Path: "/webhooks", Tag: "webhooks", Summary: "Subscribe an endpoint to listing and visit events (partner only), the response carries the signing secret",
+		Auth: openapi.BearerAuth, Body: dto.CreateWebhookReq{}, Response: dto.WebhookRsp{}, Status: http.StatusCreated, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodGet, Path: "/webhooks", Tag: "webhooks", Summary: "List the webhooks of the current partner",
+		Auth: openapi.BearerAuth, Response: []dto.WebhookRsp{}, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodPut, Path: "/webhooks/:id", Tag: "webhooks", Summary: "Replace the url, event types and active flag of a webhook",
+		Auth: openapi.BearerAuth, Params: dto.GetWebhook{}, Body: dto.UpdateWebhookReq{}, Response: dto.WebhookRsp{}, Status: http.StatusAccepted,
+		Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodDelete, Path: "/webhooks/:id", Tag: "webhooks", Summary: "Delete a webhook and its delivery log",
+		Auth: openapi.BearerAuth, Params: dto.GetWebhook{}, Status: http.StatusAccepted, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/webhooks/:id/deliveries", Tag: "webhooks", Summary: "List the delivery attempts of a webhook, newest first",
+		Auth: openapi.BearerAuth, Params: dto.GetWebhook{}, Query: dto.PageReq{}, Response: []dto.WebhookDeliveryRsp{}, Paged: true,
+		Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodPost, Path: "/webhooks/:id/test", Tag: "webhooks", Summary: "Send a signed webhook.test event and return the logged delivery",
+		Auth: openapi.BearerAuth, Params: dto.GetWebhook{}, Response: dto.WebhookDeliveryRsp{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+
+	{Method: http.MethodPost, Path: "/calendar/feed", Tag: "calendar", Summary: "Create or replace the private calendar feed url of the current user",
+		Auth: openapi.BearerAuth, Response: dto.CalendarFeedRsp{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/calendar/feed", Tag: "calendar", Summary: "Revoke the calendar feed of the current user",
+		Auth: openapi.BearerAuth, Status: http.StatusAccepted, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/calendar/feeds/:token", Tag: "calendar", Summary: "Subscribable iCalendar feed of upcoming visits, the token authenticates it",
+		Params: dto.GetCalendarFeed{}, Errors: []int{http.StatusNotFound},
+		RawResponse: &openapi.Response{Description: "RFC 5545 calendar", Content: map[string]*openapi.MediaType{"text/calendar": {Schema: &openapi.Schema{Type: "string"}}}}},
+
+	{Method: http.MethodGet, Path: "/events", Tag: "realtime", Summary: "Stream the events of the current user as Server-Sent Events",
+		Auth: openapi.BearerAuth, Query: dto.StreamReq{},
+		RawResponse: &openapi.Response{Description: "event stream, each event carries the message id, its type and the json message, resume with Last-Event-ID",
+			Content: map[string]*openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}}}},
+	{Method: http.MethodGet, Path: "/events/ws", Tag: "realtime", Summary: "Stream the events of the current user over a WebSocket",
+		Auth: openapi.BearerAuth, Query: dto.StreamReq{}, Status: http.StatusSwitchingProtocols,
+		RawResponse: &openapi.Response{Description: "websocket upgrade, every text frame is a json message and heartbeat messages keep it open"}},
+
+	{Method: http.MethodGet, Path: "/admin/health/db", Tag: "system", Summary: "Address, latency and pool stats of every database node (admin only)",
+		Auth: openapi.BearerAuth, Response: []dao.NodeStatus{}, Errors: []int{http.StatusForbidden, http.StatusServiceUnavailable}},
+	{Method: http.MethodGet, Path: "/admin/jobs", Tag: "jobs", Summary: "List background jobs (admin only)",
+		Auth: openapi.BearerAuth, Query: dto.JobFilterReq{}, Response: []dto.JobRsp{}, Paged: true, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodGet, Path: "/admin/jobs/:id", Tag: "jobs", Summary: "Get a background job (admin only)",
+		Auth: openapi.BearerAuth, Params: dto.GetJob{}, Response: dto.JobRsp{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodPost, Path: "/admin/jobs/:id/retry", Tag: "jobs", Summary: "Retry a dead or cancelled job (admin only)",
+		Auth: openapi.BearerAuth, Params: dto.GetJob{}, Response: dto.JobRsp{}, Status: http.StatusAccepted,
+		Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+	{Method: http.MethodPost, Path: "/admin/jobs/:id/cancel", Tag: "jobs", Summary: "Cancel a pending job (admin only)",
+		Auth: openapi.BearerAuth, Params: dto.GetJob{}, Response: dto.JobRsp{}, Status: http.StatusAccepted,
+		Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+}
+
+// APISpec builds the OpenAPI document of the api
+func APISpec() *openapi.Document {
+	gen := openapi.NewGenerator(openapi.Info{
+		Title:       "BookMyLab API",
+		Description: "Property listing and visit booking api. /v1 returns the legacy envelope, /v2 returns nested data and RFC 7807 problem details.",
+		Version:     "2.0.0",
+	},
+		openapi.Tag{Name: "auth", Description: "Registration and sessions"},
+		openapi.Tag{Name: "users", Description: "User profiles and roles"},
+		openapi.Tag{Name: "properties", Description: "Property listings"},
+		openapi.Tag{Name: "visits", Description: "Property visits"},
+		openapi.Tag{Name: "notifications", Description: "In-app inbox and notification preferences"},
+		openapi.Tag{Name: "webhooks", Description: "Signed event deliveries to partner endpoints"},
+		openapi.Tag{Name: "calendar", Description: "Visit invites and calendar feeds"},
+		openapi.Tag{Name: "realtime", Description: "Live event streams"},
+		openapi.Tag{Name: "jobs", Description: "Background job queue"},
+		openapi.Tag{Name: "system", Description: "Health and documentation"},
+	)
+	return gen.Build(apiVersions, apiOperations)
+}
+
+// probeResponse documents the health.Report body of the probes
+func probeResponse(description string) *openapi.Response {
+	result := &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+		"status":     {Type: "string", Enum: []any{health.StatusUp, health.StatusDown, health.StatusSkipped}},
+		"latency_ms": {Type: "integer"},
+		"error":      {Type: "string"},
+		"details":    {},
+	}}
+	return &openapi.Response{Description: description, Content: map[string]*openapi.MediaType{
+		constants.ContentTypeJson: {Schema: &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+			"status": {Type: "string", Enum: []any{health.StatusUp, health.StatusReady, health.StatusNotReady, health.StatusDraining}},
+			"checks": {Type: "object", AdditionalProperties: result},
+		}}},
+	}}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/health"
+	"booking.com/internal/repo/memrepo"
+	"booking.com/internal/server/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSpecCoversRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := NewRouter(&config.AppConfig{}, &Deps{Repos: memrepo.New(), DB: &dao.Cluster{}, Probe: health.NewProbe(0)})
+	spec := APISpec()
+
+	for _, route := range router.Routes() {
+		if !spec.Has(route.Method, openapi.OpenAPIPath(route.Path)) {
+			t.Errorf("route %s %s is not documented in apiOperations", route.Method, route.Path)
+		}
+	}
+}
+
+func TestSpecHasNoStaleOperations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := NewRouter(&config.AppConfig{}, &Deps{Repos: memrepo.New(), DB: &dao.Cluster{}, Probe: health.NewProbe(0)})
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+openapi.OpenAPIPath(route.Path)] = true
+	}
+
+	for path, item := range APISpec().Paths {
+		for method, op := range map[string]*openapi.OperationObject{
+			"GET": item.Get, "PUT": item.Put, "POST": item.Post, "DELETE": item.Delete, "PATCH": item.Patch,
+		} {
+			if op != nil && !registered[method+" "+path] {
+				t.Errorf("operation %s %s is documented but not registered", method, path)
+			}
+		}
+	}
+}
+
+func TestSpecIsValidJSON(t *testing.T) {
+	data, err := json.Marshal(APISpec())
+	if err != nil {
+		t.Fatalf("marshal spec: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal spec: %v", err)
+	}
+	if doc["openapi"] != "3.1.0" {
+		t.Errorf("openapi version = %v, want 3.1.0", doc["openapi"])
+	}
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	for _, name := range []string{"CreateUser", "AddPropertyReq", "PropertyRsp", "Problem", "APIResponse"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("schema %s missing from components", name)
+		}
+	}
+}
+
+func TestDocsContentSecurityPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.AppConfig{Security: config.Security{ContentSecurityPolicy: "default-src 'none'"}}
+	router := NewRouter(cfg, &Deps{Repos: memrepo.New(), DB: &dao.Cluster{}, Probe: health.NewProbe(0)})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	csp := rec.Header().Get("Content-Security-Policy")
+	if csp != openapi.UIContentSecurityPolicy || !strings.Contains(csp, "'sha256-") {
+		t.Errorf("docs got policy %q, want the Swagger UI policy with the inline script hash", csp)
+	}
+	if strings.Contains(csp, "https:") || strings.Contains(rec.Body.String(), "https:") {
+		t.Errorf("docs load assets from another origin, policy %q", csp)
+	}
+
+	for path, want := range map[string]int{"/docs/assets/README.md": http.StatusOK, "/docs/assets/": http.StatusNotFound} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("%s got status %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if csp := rec.Header().Get("Content-Security-Policy"); csp != "default-src 'none'" {
+		t.Errorf("spec got policy %q, want the api policy", csp)
+	}
+}
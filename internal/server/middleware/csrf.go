@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"booking.com/internal/config"
+	"booking.com/internal/svcs"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// CSRF guards the endpoints the refresh token cookie authenticates, the
+// request must carry the csrf token issued with that cookie in X-CSRF-Token.
+// Requests without the cookie are left to the handler, that is the path of
+// native clients which log in with native and send the refresh token in the
+// Authorization header or the body
+func CSRF(cfg config.Csrf) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		refreshToken, err := c.Cookie(constants.RefreshToken)
+		if !cfg.Enabled || err != nil || refreshToken == "" {
+			c.Next()
+			return
+		}
+		if !svcs.ValidCsrfToken(refreshToken, c.GetHeader(constants.CsrfHeader)) {
+			utils.AbortWithError(c, utils.ErrCsrfTokenInvalid)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"booking.com/internal/config"
+	"booking.com/internal/svcs"
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSRF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.POST("/auth/refresh", CSRF(config.Csrf{Enabled: true}),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+	disabled := gin.New()
+	disabled.POST("/auth/refresh", CSRF(config.Csrf{}), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	valid := svcs.CsrfToken("refresh")
+	tests := []struct {
+		name      string
+		router    *gin.Engine
+		cookie    string
+		header    string
+		userAgent string
+		want      int
+	}{
+		{name: "valid token", router: router, cookie: "refresh", header: valid, want: http.StatusOK},
+		{name: "missing token", router: router, cookie: "refresh", want: http.StatusForbidden},
+		{name: "token of another session", router: router, cookie: "other", header: valid, want: http.StatusForbidden},
+		{name: "no cookie", router: router, want: http.StatusOK},
+		// a User-Agent is no proof the request isn't cross-site
+		{name: "native app user agent", router: router, cookie: "refresh", userAgent: "BookMyLab-iOS/2.1", want: http.StatusForbidden},
+		{name: "disabled", router: disabled, cookie: "refresh", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: constants.RefreshToken, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(constants.CsrfHeader, tt.header)
+			}
+			req.Header.Set("User-Agent", tt.userAgent)
+			rec := httptest.NewRecorder()
+			tt.router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("got %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// ConditionalGet answers a read with 304 Not Modified when If-None-Match holds
+// the ETag of the response. Handlers returning a single resource tag it with
+// its version, other responses like the listings are tagged with a hash of the body
+func ConditionalGet() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := c.Writer
+		buf := &bufferedWriter{ResponseWriter: writer}
+		c.Writer = buf
+		c.Next()
+		c.Writer = writer
+		// errors are rendered by the error handler once we return
+		if len(c.Errors) > 0 {
+			return
+		}
+		// v1 answers reads with 302
+		if status := writer.Status(); status != http.StatusOK && status != http.StatusFound {
+			writer.WriteHeaderNow()
+			_, _ = writer.Write(buf.body.Bytes())
+			return
+		}
+		etag := writer.Header().Get(constants.ETag)
+		if etag == "" {
+			sum := sha256.Sum256(buf.body.Bytes())
+			etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			writer.Header().Set(constants.ETag, etag)
+		}
+		if noneMatch(c.GetHeader(constants.IfNoneMatch), etag) {
+			writer.Header().Del(constants.ContentType)
+			writer.WriteHeader(http.StatusNotModified)
+			writer.WriteHeaderNow()
+			return
+		}
+		_, _ = writer.Write(buf.body.Bytes())
+	}
+}
+
+// noneMatch reports whether an If-None-Match header matches etag, the
+// comparison is weak so W/"x" matches "x"
+func noneMatch(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || (tag != "" && strings.TrimPrefix(tag, "W/") == etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter holds the response body back until the ETag is known
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
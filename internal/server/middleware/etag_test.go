@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+func TestConditionalGet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/properties", ConditionalGet(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": []string{"Hill house"}})
+	})
+	router.GET("/properties/1", ConditionalGet(), func(c *gin.Context) {
+		utils.SetETag(c, 3)
+		c.JSON(http.StatusOK, gin.H{"id": 1})
+	})
+	router.GET("/properties/2", ConditionalGet(), func(c *gin.Context) {
+		utils.AbortWithError(c, utils.ErrPropertyNotFound)
+	})
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(constants.IfNoneMatch, ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	list := get("/properties", "")
+	etag := list.Header().Get(constants.ETag)
+	if list.Code != http.StatusOK || etag == "" || list.Body.Len() == 0 {
+		t.Fatalf("got %d etag %q body %q", list.Code, etag, list.Body)
+	}
+	if rec := get("/properties", `"stale", `+etag); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("got %d with %d bytes, want 304 for a cached listing", rec.Code, rec.Body.Len())
+	}
+	if rec := get("/properties/1", `"3"`); rec.Code != http.StatusNotModified || rec.Header().Get(constants.ETag) != `"3"` {
+		t.Errorf("got %d etag %q, want 304 with the version tag", rec.Code, rec.Header().Get(constants.ETag))
+	}
+	if rec := get("/properties/1", `"2"`); rec.Code != http.StatusOK || rec.Body.String() != `{"id":1}` {
+		t.Errorf("got %d %s, want the property for an old version", rec.Code, rec.Body)
+	}
+	if rec := get("/properties/2", "*"); rec.Code != http.StatusNotFound || rec.Header().Get(constants.ETag) != "" {
+		t.Errorf("got %d etag %q, want the error untagged", rec.Code, rec.Header().Get(constants.ETag))
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+func TestDBHealthHidesNodeDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	pool, err := sql.Open("pgx", "postgres://bookmylab@127.0.0.1:1/bookmylab?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	db := &dao.Cluster{Nodes: []dao.Node{{Name: "127.0.0.1:1", Role: dao.RolePrimary, Pool: pool}}}
+
+	tests := []struct {
+		name       string
+		role       string
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantDetail bool
+	}{
+		{name: "public", handler: DBHealth(db), wantStatus: http.StatusServiceUnavailable},
+		{name: "details for a user", role: constants.UserRole, handler: DBHealthDetails(db), wantStatus: http.StatusForbidden},
+		{name: "details for an admin", role: constants.AdminRole, handler: DBHealthDetails(db), wantStatus: http.StatusServiceUnavailable, wantDetail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.GET("/health/db", func(c *gin.Context) { c.Set(constants.Role, tt.role) }, tt.handler)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/db", nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := strings.Contains(rec.Body.String(), "127.0.0.1"); got != tt.wantDetail {
+				t.Errorf("got node address in the body %v, want %v: %s", got, tt.wantDetail, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+
+	"booking.com/internal/svcs"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotency replays the stored response when a client retries a request
+// with the same Idempotency-Key, the key is scoped to the current user. Only
+// successful responses are stored, a failed request releases its key
+func Idempotency(idemSvc *svcs.IdempotencySvc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(constants.IdempotencyKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		userName, err := utils.CurrentUserName(c)
+		if err != nil {
+			utils.AbortWithError(c, err)
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			utils.AbortWithError(c, utils.ErrInvalidRequest.WithMsg("unable to read the request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, err := idemSvc.Begin(userName, key, svcs.Fingerprint(c.Request.Method, c.Request.URL.Path, body))
+		if err != nil {
+			utils.AbortWithError(c, err)
+			return
+		}
+		if stored != nil {
+			c.Header(constants.IdempotentReplayed, "true")
+			c.Data(int(stored.StatusCode), stored.ContentType, stored.Response)
+			c.Abort()
+			return
+		}
+
+		rec := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = rec
+		completed := false
+		// a panicking handler releases the key too
+		defer func() {
+			if !completed {
+				if err := idemSvc.Release(userName, key); err != nil {
+					log.Printf("releasing idempotency key of %s failed: %v", userName, err)
+				}
+			}
+		}()
+		c.Next()
+		if len(c.Errors) > 0 || rec.Status() >= http.StatusBadRequest {
+			return
+		}
+		if err := idemSvc.Complete(userName, key, rec.Status(), rec.Header().Get(constants.ContentType), rec.body.Bytes()); err != nil {
+			log.Printf("storing idempotent response of %s failed: %v", userName, err)
+			return
+		}
+		completed = true
+	}
+}
+
+// recordingWriter keeps a copy of the response body
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/repo/memrepo"
+	"booking.com/internal/svcs"
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repos := memrepo.New()
+	if err := repos.Users.Create(t.Context(), &model.User{Username: "jane", FirstName: "Jane", LastName: "Test"}); err != nil {
+		t.Fatal(err)
+	}
+	idem := svcs.NewIdempotencySvc(&config.AppConfig{Idempotency: config.Idempotency{TTL: time.Hour}}, repos)
+	created := 0
+	router := gin.New()
+	router.Use(ErrorHandler(), func(c *gin.Context) { c.Set(constants.CurrentUserName, "jane") })
+	router.POST("/v1/visits", Idempotency(idem), func(c *gin.Context) {
+		if strings.Contains(c.GetHeader("X-Test"), "fail") {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		created++
+		c.JSON(http.StatusCreated, gin.H{"created": created})
+	})
+	post := func(key, body, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/visits", strings.NewReader(body))
+		req.Header.Set(constants.IdempotencyKey, key)
+		req.Header.Set("X-Test", header)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := post("key-1", `{"property_id":1}`, "")
+	retry := post("key-1", `{"property_id":1}`, "")
+	if first.Code != http.StatusCreated || retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Fatalf("got %d %s and %d %s, want the retry to replay the response", first.Code, first.Body, retry.Code, retry.Body)
+	}
+	if created != 1 || retry.Header().Get(constants.IdempotentReplayed) != "true" {
+		t.Errorf("handler ran %d times, replayed header %q", created, retry.Header().Get(constants.IdempotentReplayed))
+	}
+	if rec := post("key-1", `{"property_id":2}`, ""); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("got %d, want 422 for a different body", rec.Code)
+	}
+	if rec := post("key-2", `{"property_id":1}`, "fail"); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("got %d", rec.Code)
+	}
+	if rec := post("key-2", `{"property_id":1}`, ""); rec.Code != http.StatusCreated || created != 2 {
+		t.Errorf("got %d, want a failed request to run again", rec.Code)
+	}
+	post("", `{"property_id":1}`, "")
+	if created != 3 {
+		t.Errorf("requests without a key ran %d times", created)
+	}
+}
@@ -1,79 +1,147 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/health"
 	"booking.com/internal/svcs"
 	"booking.com/internal/utils"
 	jwtauth "booking.com/pkg/auth/jwt-auth"
 	"booking.com/pkg/constants"
-	"github.com/gin-contrib/cors"
+	customerrors "booking.com/pkg/custom_errors"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 func Health(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.WriteAppResponse("Sever is Up and Running", nil, nil))
 }
-func CommonChain() gin.HandlersChain {
+
+// NodeState is the public view of a database node, without its address or
+// pool stats
+type NodeState struct {
+	Role string `json:"role"`
+	Up   bool   `json:"up"`
+}
+
+// DBHealth pings the primary and every replica, it fails when any node is
+// down. Anyone can call it, so it only tells the role and state of each node
+func DBHealth(db *dao.Cluster) gin.HandlerFunc {
+	return checkNodes(db, func(nodes []dao.NodeStatus) (string, any) {
+		states := make([]NodeState, 0, len(nodes))
+		down := ""
+		for _, node := range nodes {
+			states = append(states, NodeState{Role: node.Role, Up: node.Up})
+			if !node.Up {
+				down = "a database " + node.Role + " is down"
+			}
+		}
+		return down, states
+	})
+}
+
+// DBHealthDetails reports the address, latency and pool stats of every node,
+// only to admins
+func DBHealthDetails(db *dao.Cluster) gin.HandlerFunc {
+	check := checkNodes(db, func(nodes []dao.NodeStatus) (string, any) {
+		down := ""
+		for _, node := range nodes {
+			if !node.Up {
+				down = "database node " + node.Name + " is down"
+			}
+		}
+		return down, nodes
+	})
+	return func(c *gin.Context) {
+		if !utils.IsAdmin(c) {
+			utils.AbortWithError(c, utils.ErrAdminOnly.WithMsg("user don't have access to the database breakdown"))
+			return
+		}
+		check(c)
+	}
+}
+
+// checkNodes pings the nodes and renders what view returns, view names a
+// node that is down or returns an empty message when all are up
+func checkNodes(db *dao.Cluster, view func(nodes []dao.NodeStatus) (string, any)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+		down, data := view(db.Check(ctx))
+		if down != "" {
+			rsp := utils.WriteAppResponse(down, nil, data)
+			rsp.Status = constants.Failed
+			c.JSON(http.StatusServiceUnavailable, rsp)
+			return
+		}
+		c.JSON(http.StatusOK, utils.WriteAppResponse("all database nodes are up", nil, data))
+	}
+}
+
+// Livez only reports that the process serves requests, it stays up while draining
+func Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{Status: health.StatusUp})
+}
+
+// Readyz runs the dependency checks and fails once graceful shutdown started
+func Readyz(probe *health.Probe) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ready, report := probe.Ready(c.Request.Context())
+		status := http.StatusOK
+		if !ready {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
+func CommonChain(cfg *config.AppConfig) gin.HandlersChain {
 	return []gin.HandlerFunc{
 		gin.Recovery(),
-		corsMiddleware(),
+		Cors(cfg.Cors),
+		SecurityHeaders(cfg.HttpServer.Tls, cfg.Security),
 		logFormatMiddleWare(),
+		ErrorHandler(),
+		RateLimit(cfg.RateLimit),
 	}
 }
 
-func corsMiddleware() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins: []string{
-			"http://localhost:8080",
-		},
-		AllowCredentials: true,
-	})
-}
-
-func AuthMiddleWare() gin.HandlerFunc {
+func AuthMiddleWare(usrSvc *svcs.UserSvc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.Request.Header.Get(constants.Authorization)
 		if authHeader == "" || !strings.HasPrefix(authHeader, constants.Bearer) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", errors.New("bearer token not provided"), nil))
+			utils.AbortWithError(c, utils.ErrMissingToken)
 			return
 		}
 		token := authHeader[len(constants.Bearer):]
 		if token == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", errors.New("access token not provided"), nil))
+			utils.AbortWithError(c, utils.ErrMissingToken.WithMsg("access token not provided"))
 			return
 		}
 		userName, err := jwtauth.GetUnVerifiedJwtClaims(token, constants.UserName)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", err, nil))
-			return
-		}
-		if userName == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", errors.New("invalid token claims"), nil))
+		if err != nil || userName == "" {
+			utils.AbortWithError(c, utils.ErrInvalidToken.WithMsg("invalid token claims"))
 			return
 		}
-		usrSvc := &svcs.UserSvc{}
 		user, err := usrSvc.GetUserByUserName(userName, true)
 		if err != nil {
-			if err == gorm.ErrRecordNotFound {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", utils.ErrUserNotFound, nil))
+			if errors.Is(err, utils.ErrUserNotFound) {
+				utils.AbortWithError(c, customerrors.Unauthorized(utils.ErrUserNotFound.Code, utils.ErrUserNotFound.Msg))
 				return
 			}
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", err, nil))
-			return
-		}
-		if user == nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", utils.ErrUserNotFound, nil))
+			utils.AbortWithError(c, err)
 			return
 		}
 		validToken, err := jwtauth.IsTokenValid(token, user.Salt, nil)
 		if err != nil || !validToken || user.RefreshToken == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utils.WriteAppResponse("", errors.New("session expired"), nil))
+			utils.AbortWithError(c, utils.ErrSessionExpired)
 			return
 		}
 		c.Set(constants.Role, user.Role)
@@ -81,13 +149,59 @@ func AuthMiddleWare() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// QueryToken accepts the access token as the access_token query parameter for
+// the clients that can't set headers, EventSource and browser websockets. The
+// parameter is removed from the url once it is copied to the Authorization header
+func QueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		token := query.Get(constants.AccessToken)
+		if token != "" && c.Request.Header.Get(constants.Authorization) == "" {
+			c.Request.Header.Set(constants.Authorization, constants.Bearer+token)
+		}
+		if query.Has(constants.AccessToken) {
+			query.Del(constants.AccessToken)
+			c.Request.URL.RawQuery = query.Encode()
+		}
+		c.Next()
+	}
+}
+
+// ErrorHandler renders the last error recorded by a handler, AppErrors are
+// mapped to their status code and anything else becomes a 500. The cause of
+// an internal error is only logged, clients get the generic message whatever
+// the mode so SQL and driver errors never leave the server
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		appErr := customerrors.From(c.Errors.Last().Err)
+		if appErr.Kind == customerrors.KindInternal && appErr.Err != nil {
+			log.Printf("%s %s failed, error: %v", c.Request.Method, c.FullPath(), appErr.Err)
+		}
+		utils.RespondError(c, appErr)
+	}
+}
+
+// APIVersion tags the request with the api version of its route group,
+// responses and errors are rendered according to it
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(constants.APIVersion, version)
+		c.Next()
+	}
+}
+
 func logFormatMiddleWare() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
 			param.ClientIP,
 			param.TimeStamp.Format(time.RFC1123),
 			param.Method,
-			param.Path,
+			redactQuery(param.Path),
 			param.Request.Proto,
 			param.StatusCode,
 			param.Latency,
@@ -96,3 +210,19 @@ func logFormatMiddleWare() gin.HandlerFunc {
 		)
 	})
 }
+
+// redactQuery hides the access token of QueryToken routes from the access log
+func redactQuery(path string) string {
+	before, rawQuery, found := strings.Cut(path, "?")
+	if !found || !strings.Contains(rawQuery, constants.AccessToken) {
+		return path
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return before
+	}
+	if query.Has(constants.AccessToken) {
+		query.Set(constants.AccessToken, "REDACTED")
+	}
+	return before + "?" + query.Encode()
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorHandlerHidesInternalErrors(t *testing.T) {
+	// debug is what the shipped .env runs with
+	gin.SetMode(gin.DebugMode)
+	defer gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	for _, version := range []string{constants.APIv1, constants.APIv2} {
+		router.GET("/"+version+"/fail", APIVersion(version), func(c *gin.Context) {
+			utils.AbortWithError(c, errors.New(`pq: relation "users" does not exist`))
+		})
+	}
+	for _, version := range []string{constants.APIv1, constants.APIv2} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+version+"/fail", nil))
+		if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "relation") ||
+			!strings.Contains(rec.Body.String(), "internal server error") {
+			t.Errorf("%s got %d %s, want the generic internal error", version, rec.Code, rec.Body)
+		}
+	}
+}
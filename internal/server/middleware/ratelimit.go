@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// bucket is the token bucket of one client ip
+type bucket struct {
+	tokens float64
+	seen   time.Time
+}
+
+// rateLimiter refills every bucket at Requests per Window up to Burst tokens
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens per second
+	burst   float64
+	buckets map[string]*bucket
+	swept   time.Time
+	idle    time.Duration
+	now     func() time.Time
+}
+
+func newRateLimiter(cfg config.RateLimit) *rateLimiter {
+	burst := cfg.Burst
+	if burst == 0 {
+		burst = cfg.Requests
+	}
+	rate := float64(cfg.Requests) / cfg.Window.Seconds()
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: map[string]*bucket{},
+		// a bucket idle this long is full again, dropping it changes nothing
+		idle: time.Duration(float64(burst) / rate * float64(time.Second)),
+		now:  time.Now,
+	}
+}
+
+// allow takes a token of key, when none is left it returns how long until one is
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.Sub(l.swept) > l.idle {
+		for k, b := range l.buckets {
+			if now.Sub(b.seen) > l.idle {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, seen: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.seen).Seconds()*l.rate)
+	b.seen = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// RateLimit limits every client ip to cfg.Requests per cfg.Window, a zero
+// Requests disables it. Rejected requests get 429 with Retry-After
+func RateLimit(cfg config.RateLimit) gin.HandlerFunc {
+	if cfg.Requests <= 0 || cfg.Window <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	limiter := newRateLimiter(cfg)
+	return func(c *gin.Context) {
+		ok, wait := limiter.allow(c.ClientIP())
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			utils.AbortWithError(c, utils.ErrRateLimited)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(config.RateLimit{Requests: 60, Window: time.Minute, Burst: 2})
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow("10.0.0.1"); !ok {
+			t.Fatalf("request %d within the burst was rejected", i+1)
+		}
+	}
+	ok, wait := limiter.allow("10.0.0.1")
+	if ok || wait != time.Second {
+		t.Fatalf("got ok %t wait %v, want a rejection for a second", ok, wait)
+	}
+	if ok, _ := limiter.allow("10.0.0.2"); !ok {
+		t.Error("another client shares the bucket")
+	}
+	now = now.Add(time.Second)
+	if ok, _ := limiter.allow("10.0.0.1"); !ok {
+		t.Error("the bucket did not refill")
+	}
+	now = now.Add(time.Hour)
+	limiter.allow("10.0.0.3")
+	if _, ok := limiter.buckets["10.0.0.2"]; ok {
+		t.Error("idle buckets are kept")
+	}
+}
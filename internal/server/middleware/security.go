@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"booking.com/internal/config"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// Cors answers the preflights of the configured origins, without any origin
+// the browser's same origin policy applies
+func Cors(cfg config.Cors) gin.HandlerFunc {
+	if len(cfg.AllowedOrigins) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	corsCfg := cors.Config{
+		AllowMethods:     cfg.AllowedMethods,
+		AllowHeaders:     cfg.AllowedHeaders,
+		ExposeHeaders:    cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			corsCfg.AllowAllOrigins = true
+		}
+	}
+	if !corsCfg.AllowAllOrigins {
+		corsCfg.AllowOriginFunc = originMatcher(cfg.AllowedOrigins)
+	}
+	return cors.New(corsCfg)
+}
+
+// originMatcher matches origins exactly, a pattern like https://*.example.com
+// matches the subdomains of example.com over https but not example.com itself
+func originMatcher(patterns []string) func(origin string) bool {
+	type wildcard struct{ scheme, domain string }
+	exact := map[string]bool{}
+	var wildcards []wildcard
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.ToLower(pattern), "/")
+		if scheme, domain, ok := strings.Cut(pattern, "://*."); ok {
+			wildcards = append(wildcards, wildcard{scheme: scheme + "://", domain: "." + domain})
+			continue
+		}
+		exact[pattern] = true
+	}
+	return func(origin string) bool {
+		origin = strings.ToLower(origin)
+		if exact[origin] {
+			return true
+		}
+		for _, w := range wildcards {
+			host, ok := strings.CutPrefix(origin, w.scheme)
+			if !ok {
+				continue
+			}
+			sub, ok := strings.CutSuffix(host, w.domain)
+			if ok && sub != "" && !strings.ContainsAny(sub, "/:@?#") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SecurityHeaders sets the headers browsers enforce, HSTS only goes out when
+// the server or the proxy in front of it terminates TLS
+func SecurityHeaders(tls bool, cfg config.Security) gin.HandlerFunc {
+	hsts := ""
+	if (tls || cfg.BehindTlsProxy) && cfg.HstsMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int64(cfg.HstsMaxAge.Seconds()))
+		if cfg.HstsIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		if cfg.FrameOptions != "" {
+			header.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		if cfg.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+		if cfg.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		c.Next()
+	}
+}
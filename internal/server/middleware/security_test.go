@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	match := originMatcher([]string{"http://localhost:8080", "https://*.bookmylab.com", "https://*.example.com:8443/"})
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"http://localhost:8080", true},
+		{"http://localhost:3000", false},
+		{"https://app.bookmylab.com", true},
+		{"https://a.b.bookmylab.com", true},
+		{"HTTPS://App.BookMyLab.com", true},
+		{"https://bookmylab.com", false},
+		{"http://app.bookmylab.com", false},
+		{"https://evilbookmylab.com", false},
+		{"https://app.bookmylab.com.evil.com", false},
+		{"https://app.bookmylab.com:8443", false},
+		{"https://app.example.com:8443", true},
+		{"https://app.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := match(tt.origin); got != tt.want {
+			t.Errorf("origin %s: got %t, want %t", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCorsPreflight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Cors(config.Cors{
+		AllowedOrigins:   []string{"https://*.bookmylab.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	router.POST("/v1/visits", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/visits", nil)
+	req.Header.Set("Origin", "https://app.bookmylab.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Authorization")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	header := rec.Header()
+	if header.Get("Access-Control-Allow-Origin") != "https://app.bookmylab.com" || header.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("preflight of an allowed origin got %d %v", rec.Code, header)
+	}
+	if header.Get("Access-Control-Allow-Headers") != "Authorization,Content-Type" || header.Get("Access-Control-Max-Age") != "3600" {
+		t.Errorf("got allowed headers %q max age %q", header.Get("Access-Control-Allow-Headers"), header.Get("Access-Control-Max-Age"))
+	}
+
+	req.Header.Set("Origin", "https://evil.com")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("preflight of another origin was allowed")
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	cfg := config.Security{
+		HstsMaxAge: 24 * time.Hour, HstsIncludeSubdomains: true, FrameOptions: "DENY",
+		ReferrerPolicy: "no-referrer", ContentSecurityPolicy: "default-src 'none'",
+	}
+	tests := []struct {
+		name     string
+		tls      bool
+		proxy    bool
+		wantHsts string
+	}{
+		{name: "plain http", wantHsts: ""},
+		{name: "tls", tls: true, wantHsts: "max-age=86400; includeSubDomains"},
+		{name: "behind a tls proxy", proxy: true, wantHsts: "max-age=86400; includeSubDomains"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			cfg := cfg
+			cfg.BehindTlsProxy = tt.proxy
+			router := gin.New()
+			router.Use(SecurityHeaders(tt.tls, cfg))
+			router.GET("/livez", Livez)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+			for name, want := range map[string]string{
+				"Strict-Transport-Security": tt.wantHsts,
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Referrer-Policy":           "no-referrer",
+				"Content-Security-Policy":   "default-src 'none'",
+			} {
+				if got := rec.Header().Get(name); got != want {
+					t.Errorf("%s: got %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"booking.com/internal/dto"
+	"booking.com/pkg/constants"
+)
+
+// Auth is the authentication an operation expects
+type Auth int
+
+const (
+	NoAuth Auth = iota
+	BearerAuth
+	// RefreshCookieAuth is the refresh token cookie with its csrf token, or
+	// the refresh token of a native client in the Authorization header
+	RefreshCookieAuth
+)
+
+const (
+	bearerScheme = "bearerAuth"
+	cookieScheme = "refreshCookie"
+	csrfScheme   = "csrfToken"
+	nativeScheme = "refreshBearer"
+)
+
+// Operation documents a single route, Path uses gin syntax relative to the api version
+type Operation struct {
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Auth     Auth
+	Body     any
+	Query    any
+	Params   any
+	Response any
+	Paged    bool
+	Status   int
+	Errors   []int
+	// Idempotent operations accept an Idempotency-Key header and replay the response of a retry
+	Idempotent bool
+	// Conditional reads return an ETag and answer a matching If-None-Match with
+	// 304, conditional writes require the ETag the client read in If-Match
+	Conditional bool
+	// MergePatch bodies are JSON Merge Patch documents, members left out keep
+	// their value and null clears it
+	MergePatch bool
+	// OptionalBody operations also accept a request without the body
+	OptionalBody bool
+	// Unversioned operations are mounted at the root instead of under /v1 and /v2
+	Unversioned bool
+	RawResponse *Response
+}
+
+type Generator struct {
+	doc *Document
+}
+
+func NewGenerator(info Info, tags ...Tag) *Generator {
+	return &Generator{doc: &Document{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Tags:    tags,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{
+				bearerScheme: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "access token returned by the login and refresh endpoints",
+				},
+				cookieScheme: {
+					Type:        "apiKey",
+					In:          "cookie",
+					Name:        constants.RefreshToken,
+					Description: "http only refresh token cookie set at login",
+				},
+				csrfScheme: {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        constants.CsrfHeader,
+					Description: "csrf token returned at login, sent back with the refresh token cookie",
+				},
+				nativeScheme: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "refresh token returned to native clients in the login and refresh responses",
+				},
+			},
+		},
+	}}
+}
+
+// Build describes the operations under every api version and returns the document
+func (g *Generator) Build(versions []string, ops []Operation) *Document {
+	for _, op := range ops {
+		if op.Unversioned {
+			g.add("", op)
+			continue
+		}
+		for _, version := range versions {
+			g.add(version, op)
+		}
+	}
+	return g.doc
+}
+
+func (g *Generator) add(version string, op Operation) {
+	path := OpenAPIPath(op.Path)
+	if version != "" {
+		path = "/" + version + path
+	}
+	item, ok := g.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		g.doc.Paths[path] = item
+	}
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	obj := &OperationObject{
+		Summary:     op.Summary,
+		OperationID: operationID(version, op),
+		Responses:   map[string]*Response{},
+	}
+	if op.Tag != "" {
+		obj.Tags = []string{op.Tag}
+	}
+	obj.Parameters = append(obj.Parameters, g.parameters(op.Params, "path", "uri")...)
+	obj.Parameters = append(obj.Parameters, g.parameters(op.Query, "query", "form")...)
+	if op.Idempotent {
+		maxLength := 255
+		obj.Parameters = append(obj.Parameters, Parameter{
+			Name:        constants.IdempotencyKey,
+			In:          "header",
+			Description: "retries with the same key and body replay the first response",
+			Schema:      &Schema{Type: "string", MaxLength: &maxLength},
+		})
+	}
+	if op.Conditional {
+		obj.Parameters = append(obj.Parameters, conditionalHeader(op.Method))
+	}
+	if op.Body != nil {
+		body := &MediaType{Schema: g.schemaFor(reflect.TypeOf(op.Body))}
+		obj.RequestBody = &RequestBody{Required: !op.OptionalBody, Content: map[string]*MediaType{constants.ContentTypeJson: body}}
+		if op.MergePatch {
+			obj.RequestBody.Content[constants.ContentTypeMergePatch] = body
+		}
+	}
+	switch op.Auth {
+	case BearerAuth:
+		obj.Security = []map[string][]string{{bearerScheme: {}}}
+	case RefreshCookieAuth:
+		obj.Security = []map[string][]string{{cookieScheme: {}, csrfScheme: {}}, {nativeScheme: {}}}
+	}
+
+	if op.RawResponse != nil {
+		obj.Responses[strconv.Itoa(status)] = op.RawResponse
+	} else {
+		obj.Responses[successStatus(version, op.Method, status)] = g.successResponse(version, op)
+	}
+	etag := map[string]*Header{constants.ETag: {Description: "send it back in If-None-Match, or in If-Match to update the resource", Schema: &Schema{Type: "string"}}}
+	if op.Conditional && (op.Method == http.MethodGet || op.Response != nil) {
+		obj.Responses[successStatus(version, op.Method, status)].Headers = etag
+	}
+	if op.Conditional && op.Method == http.MethodGet {
+		obj.Responses[strconv.Itoa(http.StatusNotModified)] = &Response{Description: "the resource still has the ETag given in If-None-Match", Headers: etag}
+	}
+	errStatuses := []int{http.StatusBadRequest, http.StatusInternalServerError}
+	if op.Auth != NoAuth {
+		errStatuses = append(errStatuses, http.StatusUnauthorized)
+	}
+	if op.Idempotent {
+		errStatuses = append(errStatuses, http.StatusConflict, http.StatusUnprocessableEntity)
+	}
+	if op.Conditional && op.Method != http.MethodGet {
+		errStatuses = append(errStatuses, http.StatusPreconditionFailed, http.StatusPreconditionRequired)
+	}
+	for _, errStatus := range append(errStatuses, op.Errors...) {
+		obj.Responses[strconv.Itoa(errStatus)] = g.errorResponse(version, errStatus)
+	}
+	*item.operation(op.Method) = obj
+}
+
+// conditionalHeader documents If-None-Match on reads and the required If-Match on writes
+func conditionalHeader(method string) Parameter {
+	if method == http.MethodGet {
+		return Parameter{
+			Name:        constants.IfNoneMatch,
+			In:          "header",
+			Description: "ETag of a cached response, 304 is returned while it is current",
+			Schema:      &Schema{Type: "string"},
+		}
+	}
+	return Parameter{
+		Name:        constants.IfMatch,
+		In:          "header",
+		Description: "ETag of the resource as read, or * to update any version",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+// successStatus mirrors the /v1 renderer which answers reads with 302
+func successStatus(version, method string, status int) string {
+	if version == constants.APIv1 && method == http.MethodGet && status == http.StatusOK {
+		status = http.StatusFound
+	}
+	return strconv.Itoa(status)
+}
+
+func (g *Generator) successResponse(version string, op Operation) *Response {
+	rsp := &Response{Description: "success"}
+	if version != constants.APIv2 {
+		rsp.Content = map[string]*MediaType{
+			constants.ContentTypeJson: {Schema: g.schemaFor(reflect.TypeOf(dto.APIResponse{}))},
+		}
+		return rsp
+	}
+	envelope := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "string", Enum: []any{constants.Success}},
+			"message": {Type: "string"},
+		},
+		Required: []string{"status"},
+	}
+	if op.Response != nil {
+		envelope.Properties["data"] = g.schemaFor(reflect.TypeOf(op.Response))
+	}
+	if op.Paged {
+		envelope.Properties["meta"] = g.schemaFor(reflect.TypeOf(dto.PageMeta{}))
+	}
+	rsp.Content = map[string]*MediaType{constants.ContentTypeJson: {Schema: envelope}}
+	return rsp
+}
+
+func (g *Generator) errorResponse(version string, status int) *Response {
+	if version != constants.APIv2 {
+		return &Response{
+			Description: http.StatusText(status),
+			Content: map[string]*MediaType{
+				constants.ContentTypeJson: {Schema: g.schemaFor(reflect.TypeOf(dto.APIResponse{}))},
+			},
+		}
+	}
+	return &Response{
+		Description: http.StatusText(status),
+		Content: map[string]*MediaType{
+			constants.ContentTypeProblem: {Schema: g.schemaFor(reflect.TypeOf(dto.Problem{}))},
+		},
+	}
+}
+
+// OpenAPIPath converts a gin route path into OpenAPI syntax, /properties/:id -> /properties/{id}
+func OpenAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func operationID(version string, op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	if version != "" {
+		b.WriteString(strings.ToUpper(version[:1]) + version[1:])
+	}
+	for _, segment := range strings.FieldsFunc(op.Path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '_' || r == ':' || r == '{' || r == '}'
+	}) {
+		b.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+	}
+	return b.String()
+}
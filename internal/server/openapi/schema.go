@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"booking.com/internal/validation"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	memberType = reflect.TypeOf((*validation.Member)(nil)).Elem()
+)
+
+// schemaFor returns the schema of t, named structs are registered as components and referenced
+func (g *Generator) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := g.doc.Components.Schemas[name]; !ok {
+			// register before walking the fields so recursive types terminate
+			g.doc.Components.Schemas[name] = &Schema{}
+			*g.doc.Components.Schemas[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	// interfaces and anything else accept any JSON value
+	return &Schema{}
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.walkFields(t, "json", func(name string, field reflect.StructField) {
+		if field.Type.Implements(memberType) {
+			schema.Properties[name] = g.memberSchema(field)
+			return
+		}
+		fieldSchema := g.schemaFor(field.Type)
+		if applyBinding(fieldSchema, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = fieldSchema
+	})
+	return schema
+}
+
+// memberSchema describes a member of a merge patch, members are never required
+// and the ones without a required rule accept null to clear the value
+func (g *Generator) memberSchema(field reflect.StructField) *Schema {
+	value, _ := field.Type.FieldByName("value")
+	schema := g.schemaFor(value.Type)
+	if applyBinding(schema, field.Tag.Get(validation.TagPatch)) {
+		return schema
+	}
+	return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+}
+
+// parameters describes the fields of a query or uri binding struct as parameters
+func (g *Generator) parameters(v any, in, tag string) []Parameter {
+	if v == nil {
+		return nil
+	}
+	var params []Parameter
+	g.walkFields(reflect.TypeOf(v), tag, func(name string, field reflect.StructField) {
+		schema := g.schemaFor(field.Type)
+		required := applyBinding(schema, field.Tag.Get("binding"))
+		params = append(params, Parameter{
+			Name:     name,
+			In:       in,
+			Required: required || in == "path",
+			Schema:   schema,
+		})
+	})
+	return params
+}
+
+// walkFields calls fn for every exported field with a name under tag, embedded structs are flattened
+func (g *Generator) walkFields(t reflect.Type, tag string, fn func(name string, field reflect.StructField)) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			g.walkFields(field.Type, tag, fn)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if tag != "json" {
+				continue
+			}
+			name = field.Name
+		}
+		fn(name, field)
+	}
+}
+
+// applyBinding copies the validator rules of a binding tag into the schema and
+// reports whether the field is required
+func applyBinding(schema *Schema, binding string) bool {
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email", validation.TagEmail:
+			schema.Format = "email"
+		case validation.TagPhone:
+			schema.Pattern = validation.E164Pattern
+		case validation.TagPassword:
+			schema.Format = "password"
+			setBound(schema, strconv.Itoa(validation.MinPasswordLength), true)
+		case validation.TagPropertyType:
+			for _, v := range validation.PropertyTypes() {
+				schema.Enum = append(schema.Enum, v)
+			}
+		case validation.TagMoney:
+			setBound(schema, "0", true)
+		case validation.TagFuture:
+			schema.Description = "must be in the future"
+		case "unique":
+			schema.Description = "entries must be unique"
+			if param != "" {
+				schema.Description = "entries must not repeat a " + strings.ToLower(param)
+			}
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, v)
+			}
+		case "min", "gte":
+			setBound(schema, param, true)
+		case "max", "lte":
+			setBound(schema, param, false)
+		}
+	}
+	return required
+}
+
+func setBound(schema *Schema, param string, lower bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	if schema.Type == "string" {
+		length := int(n)
+		if lower {
+			schema.MinLength = &length
+		} else {
+			schema.MaxLength = &length
+		}
+		return
+	}
+	if lower {
+		schema.Minimum = &n
+	} else {
+		schema.Maximum = &n
+	}
+}
@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate sh -c "curl -sSfL https://registry.npmjs.org/swagger-ui-dist/-/swagger-ui-dist-5.17.14.tgz | tar -xz -C assets --strip-components=1 package/swagger-ui-bundle.js package/swagger-ui.css package/LICENSE"
+
+//go:embed swagger.html
+var swaggerHTML []byte
+
+// assets holds the vendored swagger-ui-dist files the page loads
+//
+//go:embed assets
+var assets embed.FS
+
+// UIContentSecurityPolicy lets the Swagger UI page load its assets from our
+// origin only and run its inline script, which is pinned by hash
+var UIContentSecurityPolicy = uiPolicy(swaggerHTML)
+
+var inlineScript = regexp.MustCompile(`(?s)<script>(.*?)</script>`)
+
+func uiPolicy(page []byte) string {
+	scripts := []string{"'self'"}
+	for _, m := range inlineScript.FindAllSubmatch(page, -1) {
+		sum := sha256.Sum256(m[1])
+		scripts = append(scripts, "'sha256-"+base64.StdEncoding.EncodeToString(sum[:])+"'")
+	}
+	return strings.Join([]string{
+		"default-src 'none'",
+		"script-src " + strings.Join(scripts, " "),
+		// swagger ui sets inline styles on the elements it renders
+		"style-src 'self' 'unsafe-inline'",
+		"img-src 'self' data:",
+		"connect-src 'self'",
+		"frame-ancestors 'none'",
+	}, "; ")
+}
+
+// SpecHandler serves the document as JSON
+func SpecHandler(doc *Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// UIHandler serves the Swagger UI page, it loads the spec from /openapi.json
+func UIHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Security-Policy", UIContentSecurityPolicy)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerHTML)
+	}
+}
+
+// AssetsHandler serves the vendored Swagger UI files under /docs/assets,
+// directories are never listed
+func AssetsHandler() gin.HandlerFunc {
+	files, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err)
+	}
+	return func(c *gin.Context) {
+		name := strings.TrimPrefix(c.Param("filepath"), "/")
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		c.Data(http.StatusOK, contentType, data)
+	}
+}
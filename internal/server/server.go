@@ -1,80 +1,282 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/events"
 	"booking.com/internal/handlers/auth"
+	"booking.com/internal/handlers/calendar"
+	"booking.com/internal/handlers/jobs"
+	"booking.com/internal/handlers/notifications"
 	"booking.com/internal/handlers/properties"
+	"booking.com/internal/handlers/reviews"
+	"booking.com/internal/handlers/stream"
 	"booking.com/internal/handlers/user"
 	"booking.com/internal/handlers/visits"
+	"booking.com/internal/handlers/webhooks"
+	"booking.com/internal/health"
+	"booking.com/internal/realtime"
+	"booking.com/internal/repo"
 	"booking.com/internal/server/middleware"
+	"booking.com/internal/server/openapi"
 	"booking.com/internal/svcs"
+	"booking.com/internal/validation"
+	"booking.com/internal/worker"
+	"booking.com/pkg/constants"
 	"github.com/gin-gonic/gin"
 )
 
-func StartHttpTlsServer(cfg *config.AppConfig) error {
+var apiVersions = []string{constants.APIv1, constants.APIv2}
+
+// Deps are the shared resources the routes are built on
+type Deps struct {
+	Repos *repo.Repos
+	DB    *dao.Cluster
+	Probe *health.Probe
+	// Bus delivers the outbox events to in-process subscribers
+	Bus *events.Bus
+	// Hub holds the open event streams of this instance
+	Hub *realtime.Hub
+}
+
+// StartHttpTlsServer serves until SIGINT or SIGTERM, then fails readiness for
+// the drain delay before shutting the listener down gracefully
+func StartHttpTlsServer(cfg *config.AppConfig, db *dao.Cluster) error {
 	gin.SetMode(cfg.HttpServer.Mode)
 
-	router := gin.New()
+	probe := health.NewProbe(cfg.HttpServer.ProbeTimeout).
+		Add("database", health.Database(db)).
+		Add("migrations", health.Migrations(db)).
+		Add("mailer", health.Mailer(cfg.Mail)).
+		Add("blob_store", health.BlobStore(cfg.BlobStore, &http.Client{Timeout: cfg.HttpServer.ProbeTimeout}))
+	repos := repo.NewGormRepos(dao.Q)
+	pubsub, err := realtime.NewPubSub(cfg.Realtime, db)
+	if err != nil {
+		return err
+	}
+	wrk, err := worker.New(cfg, repos, pubsub)
+	if err != nil {
+		return err
+	}
+	hub := realtime.NewHub(cfg.Realtime)
+	deps := &Deps{Repos: repos, DB: db, Probe: probe, Bus: wrk.Bus, Hub: hub}
+	router := NewRouter(cfg, deps)
 
-	router.Use(middleware.CommonChain()...)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	router.GET("/health", middleware.Health)
-	// EndPoints withoutAuth
-	{
-		v1NoAuth := router.Group("/v1")
-		registerNoAuthApis(v1NoAuth, cfg)
+	go func() {
+		if err := hub.Run(ctx, pubsub); err != nil && !errors.Is(err, context.Canceled) {
+			log.Println("realtime hub stopped, error: ", err)
+		}
+	}()
+
+	// the workers stop with ctx, jobs still running get the shutdown timeout to finish
+	workerDone := make(chan struct{})
+	if cfg.Jobs.InProcess {
+		go func() {
+			defer close(workerDone)
+			wrk.Run(ctx)
+		}()
+	} else {
+		close(workerDone)
 	}
-	// EndPoints withAuth
-	{
-		v1Auth := router.Group("/v1")
-		v1Auth.Use(middleware.AuthMiddleWare())
-
-		registerUserApp(v1Auth, cfg)
-		registerPropertyApp(v1Auth, cfg)
-		registerVisitsApp(v1Auth, cfg)
+
+	srv := &http.Server{Addr: cfg.HttpServer.Address, Handler: router}
+	// Shutdown doesn't wait for hijacked websockets but it does wait for the sse streams
+	srv.RegisterOnShutdown(hub.Close)
+	errCh := make(chan error, 1)
+	go func() {
+		if cfg.HttpServer.Tls {
+			errCh <- srv.ListenAndServeTLS(cfg.HttpServer.CertPath, cfg.HttpServer.KeyPath)
+			return
+		}
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
 	}
 
-	if err := router.Run(cfg.HttpServer.Address); err != nil {
+	log.Printf("shutdown requested, draining for %v", cfg.HttpServer.DrainDelay)
+	probe.Drain()
+	time.Sleep(cfg.HttpServer.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HttpServer.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
 		return err
 	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	select {
+	case <-workerDone:
+	case <-shutdownCtx.Done():
+		log.Println("workers did not stop before the shutdown timeout")
+	}
 	return nil
 }
-func registerNoAuthApis(router *gin.RouterGroup, cfg *config.AppConfig) {
-	authHandler := auth.NewAuthHandler(&svcs.AuthSvc{AppCfg: cfg}, &svcs.UserSvc{AppCfg: cfg})
+
+// NewRouter registers every route of the api on a new gin engine, the services
+// are built on top of the given repositories
+func NewRouter(cfg *config.AppConfig, deps *Deps) *gin.Engine {
+	repos := deps.Repos
+	validation.Register()
+	router := gin.New()
+	// the rate limits and logs key on the client ip, X-Forwarded-For only
+	// counts when a trusted proxy sent it
+	if err := router.SetTrustedProxies(cfg.HttpServer.TrustedProxies); err != nil {
+		log.Printf("trusting no proxy, invalid HTTP_SERVER_TRUSTED_PROXIES: %v", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+
+	router.Use(middleware.CommonChain(cfg)...)
+
+	router.GET("/health", middleware.Health)
+	router.GET("/health/db", middleware.DBHealth(deps.DB))
+	router.GET("/livez", middleware.Livez)
+	router.GET("/readyz", middleware.Readyz(deps.Probe))
+	router.GET("/openapi.json", openapi.SpecHandler(APISpec()))
+	router.GET("/docs", openapi.UIHandler())
+	router.GET("/docs/assets/*filepath", openapi.AssetsHandler())
+	// v1 keeps the legacy envelope, v2 returns nested data and problem details
+	for _, version := range apiVersions {
+		// EndPoints withoutAuth
+		{
+			noAuth := router.Group("/"+version, middleware.APIVersion(version))
+			registerNoAuthApis(noAuth, cfg, repos)
+		}
+		// EndPoints withAuth
+		{
+			withAuth := router.Group("/"+version, middleware.APIVersion(version))
+			withAuth.Use(middleware.AuthMiddleWare(svcs.NewUserSvc(cfg, repos)))
+
+			registerUserApp(withAuth, cfg, repos)
+			registerPropertyApp(withAuth, cfg, repos)
+			registerVisitsApp(withAuth, cfg, repos)
+			registerNotificationsApp(withAuth, cfg, repos)
+			registerJobsApp(withAuth, cfg, repos)
+			registerWebhooksApp(withAuth, cfg, repos)
+			registerCalendarApp(withAuth, cfg, repos)
+			withAuth.GET("/admin/health/db", middleware.DBHealthDetails(deps.DB))
+		}
+		// EndPoints streaming events, the token may come from the query
+		{
+			stream := router.Group("/"+version, middleware.APIVersion(version), middleware.QueryToken())
+			stream.Use(middleware.AuthMiddleWare(svcs.NewUserSvc(cfg, repos)))
+
+			registerStreamApp(stream, cfg, deps.Hub)
+		}
+	}
+	return router
+}
+func registerNoAuthApis(router *gin.RouterGroup, cfg *config.AppConfig, repos *repo.Repos) {
+	authHandler := auth.NewAuthHandler(svcs.NewAuthSvc(cfg), svcs.NewUserSvc(cfg, repos))
 
 	router.POST("/auth/register", authHandler.Register)
 	router.POST("/auth/login", authHandler.Login)
-	router.POST("/auth/refresh", authHandler.Refresh)
-	router.POST("/auth/logout", authHandler.LogOut)
+	// the refresh token cookie authenticates these, the csrf token proves the request comes from our pages.
+	// Native clients send the refresh token in the Authorization header or the body instead
+	csrf := middleware.CSRF(cfg.Csrf)
+	router.POST("/auth/refresh", csrf, authHandler.Refresh)
+	router.POST("/auth/logout", csrf, authHandler.LogOut)
 	router.PATCH("/auth/activate", authHandler.ActivateUser)
 
-	prptyHandler := properties.NewPropertyHandler(&svcs.PropertySvc{AppCfg: cfg})
-	router.GET("/properties/all", prptyHandler.GetAllProperties)
+	prptyHandler := properties.NewPropertyHandler(svcs.NewPropertySvc(cfg, repos))
+	router.GET("/properties/all", middleware.ConditionalGet(), prptyHandler.GetAllProperties)
+
+	calendarHandler := calendar.NewCalendarHandler(svcs.NewCalendarSvc(cfg, repos))
+	router.GET("/calendar/feeds/:token", calendarHandler.Feed)
 }
 
-func registerUserApp(router *gin.RouterGroup, cfg *config.AppConfig) {
-	usrHandler := user.NewUserHandler(&svcs.UserSvc{AppCfg: cfg})
+func registerUserApp(router *gin.RouterGroup, cfg *config.AppConfig, repos *repo.Repos) {
+	usrHandler := user.NewUserHandler(svcs.NewUserSvc(cfg, repos))
 
-	router.GET("/user/profile", usrHandler.GetProfile)
+	router.GET("/user/profile", middleware.ConditionalGet(), usrHandler.GetProfile)
 	router.PUT("/user/update", usrHandler.UpdateUser)
-	router.GET("/user/list", usrHandler.ListUsers)
+	router.PATCH("/user/profile", usrHandler.PatchProfile)
+	router.GET("/user/list", middleware.ConditionalGet(), usrHandler.ListUsers)
 	router.PATCH("/user/update-role", usrHandler.UpdateRole)
 	router.DELETE("/user/profile", usrHandler.DeleteUser)
 }
-func registerPropertyApp(router *gin.RouterGroup, cfg *config.AppConfig) {
-	prptyHandler := properties.NewPropertyHandler(&svcs.PropertySvc{AppCfg: cfg})
+func registerPropertyApp(router *gin.RouterGroup, cfg *config.AppConfig, repos *repo.Repos) {
+	prptyHandler := properties.NewPropertyHandler(svcs.NewPropertySvc(cfg, repos))
+	idempotent := middleware.Idempotency(svcs.NewIdempotencySvc(cfg, repos))
 
-	router.POST("/properties", prptyHandler.AddProperties)
+	router.POST("/properties", idempotent, prptyHandler.AddProperties)
 	router.PUT("/properties", prptyHandler.UpdateProperty)
-	router.GET("/properties", prptyHandler.GetFilteredProperties)
-	router.DELETE("/properties/:id", prptyHandler.GetAllProperties)
+	router.GET("/properties", middleware.ConditionalGet(), prptyHandler.GetFilteredProperties)
+	router.GET("/properties/:id", middleware.ConditionalGet(), prptyHandler.GetProperty)
+	router.PATCH("/properties/:id", prptyHandler.PatchProperty)
+	router.DELETE("/properties/:id", prptyHandler.DeleteProperty)
+
+	reviewHandler := reviews.NewReviewsHandler(svcs.NewReviewSvc(cfg, repos), svcs.NewPropertySvc(cfg, repos))
+	router.POST("/properties/:id/reviews", idempotent, reviewHandler.AddReview)
 }
 
-func registerVisitsApp(router *gin.RouterGroup, cfg *config.AppConfig) {
-	visitHandler := visits.NewVisitsHandler(&svcs.VisitsSvc{AppCfg: cfg})
-	
-	router.POST("/visits", visitHandler.ScheduleVisit)
+func registerVisitsApp(router *gin.RouterGroup, cfg *config.AppConfig, repos *repo.Repos) {
+	visitHandler := visits.NewVisitsHandler(svcs.NewVisitsSvc(cfg, repos), svcs.NewPropertySvc(cfg, repos))
+	idempotent := middleware.Idempotency(svcs.NewIdempotencySvc(cfg, repos))
+
+	router.POST("/visits", idempotent, visitHandler.ScheduleVisit)
 	router.PUT("/visits", visitHandler.UpdateVisit)
-	router.GET("/visits", visitHandler.FilterVisits)
+	router.GET("/visits", middleware.ConditionalGet(), visitHandler.FilterVisits)
 	router.DELETE("/visits/:id", visitHandler.DeleteVisit)
 }
+
+func registerNotificationsApp(router *gin.RouterGroup, cfg *config.AppConfig, repos *repo.Repos) {
+	notificationsHandler := notifications.NewNotificationsHandler(svcs.NewNotificationSvc(cfg, repos))
+
+	router.GET("/notifications", notificationsHandler.FilterNotifications)
+	router.GET("/notifications/unread-count", notificationsHandler.UnreadCount)
+	router.PATCH("/notifications/:id/read", notificationsHandler.MarkRead)
+	router.PATCH("/notifications/read-all", notificationsHandler.MarkAllRead)
+	router.GET("/notifications/preferences", notificationsHandler.GetPreferences)
+	router.PUT("/notifications/preferences", notificationsHandler.UpdatePreferences)
+}
+
+func registerJobsApp(router *gin.RouterGroup, cfg *config.AppConfig, repos *repo.Repos) {
+	jobsHandler := jobs.NewJobsHandler(svcs.NewJobsSvc(cfg, repos))
+
+	router.GET("/admin/jobs", jobsHandler.FilterJobs)
+	router.GET("/admin/jobs/:id", jobsHandler.GetJob)
+	router.POST("/admin/jobs/:id/retry", jobsHandler.RetryJob)
+	router.POST("/admin/jobs/:id/cancel", jobsHandler.CancelJob)
+}
+
+func registerWebhooksApp(router *gin.RouterGroup, cfg *config.AppConfig, repos *repo.Repos) {
+	webhooksHandler := webhooks.NewWebhooksHandler(svcs.NewWebhookSvc(cfg, repos))
+
+	router.POST("/webhooks", webhooksHandler.CreateWebhook)
+	router.GET("/webhooks", webhooksHandler.ListWebhooks)
+	router.PUT("/webhooks/:id", webhooksHandler.UpdateWebhook)
+	router.DELETE("/webhooks/:id", webhooksHandler.DeleteWebhook)
+	router.GET("/webhooks/:id/deliveries", webhooksHandler.Deliveries)
+	router.POST("/webhooks/:id/test", webhooksHandler.SendTest)
+}
+
+func registerCalendarApp(router *gin.RouterGroup, cfg *config.AppConfig, repos *repo.Repos) {
+	calendarHandler := calendar.NewCalendarHandler(svcs.NewCalendarSvc(cfg, repos))
+
+	router.POST("/calendar/feed", calendarHandler.CreateFeed)
+	router.DELETE("/calendar/feed", calendarHandler.RevokeFeed)
+}
+
+func registerStreamApp(router *gin.RouterGroup, cfg *config.AppConfig, hub *realtime.Hub) {
+	streamHandler := stream.NewStreamHandler(hub, cfg.Realtime)
+
+	router.GET("/events", streamHandler.Events)
+	router.GET("/events/ws", streamHandler.WebSocket)
+}
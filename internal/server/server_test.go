@@ -0,0 +1,112 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/dto"
+	"booking.com/internal/health"
+	"booking.com/internal/repo/memrepo"
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitTrustsConfiguredProxiesOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		proxies []string
+		want    int
+	}{
+		// without trusted proxies a client can't get a fresh bucket by forging the header
+		{name: "no trusted proxy", want: http.StatusTooManyRequests},
+		{name: "peer is a trusted proxy", proxies: []string{"192.0.2.0/24"}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.AppConfig{
+				HttpServer: config.Server{TrustedProxies: tt.proxies},
+				RateLimit:  config.RateLimit{Requests: 1, Window: time.Minute, Burst: 1},
+			}
+			router := NewRouter(cfg, &Deps{Repos: memrepo.New(), DB: &dao.Cluster{}, Probe: health.NewProbe(0)})
+			var rec *httptest.ResponseRecorder
+			for _, client := range []string{"203.0.113.1", "203.0.113.2"} {
+				req := httptest.NewRequest(http.MethodGet, "/health", nil)
+				req.Header.Set("X-Forwarded-For", client)
+				rec = httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+			}
+			if rec.Code != tt.want {
+				t.Errorf("second client got status %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+// TestNativeClientSession walks the native path, the refresh token travels in
+// the Authorization header or the body and never as a cookie
+func TestNativeClientSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.AppConfig{
+		Jwt:  config.Jwt{AccessTokenExpiry: 15, RefreshTokenExpiry: 60},
+		Csrf: config.Csrf{Enabled: true},
+	}
+	router := NewRouter(cfg, &Deps{Repos: memrepo.New(), DB: &dao.Cluster{}, Probe: health.NewProbe(0)})
+	send := func(path, body, refreshToken string) (*httptest.ResponseRecorder, dto.TokenRsp) {
+		t.Helper()
+		var reader io.Reader
+		if body != "" {
+			reader = strings.NewReader(body)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/v2"+path, reader)
+		req.Header.Set("Content-Type", constants.ContentTypeJson)
+		if refreshToken != "" {
+			req.Header.Set(constants.Authorization, constants.Bearer+refreshToken)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if cookies := rec.Result().Cookies(); len(cookies) != 0 {
+			t.Errorf("%s set cookies %v on the native path", path, cookies)
+		}
+		var rsp struct {
+			Data dto.TokenRsp `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &rsp)
+		return rec, rsp.Data
+	}
+
+	rec, _ := send("/auth/register", `{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","phone":"+910000000001","password":"Secret#123"}`, "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register: got %d %s", rec.Code, rec.Body)
+	}
+	rec, login := send("/auth/login", `{"username":"jane@example.com","password":"Secret#123","native":true}`, "")
+	if rec.Code != http.StatusOK || login.RefreshToken == "" || login.CsrfToken != "" {
+		t.Fatalf("login: got %d %s, want the refresh token without a csrf token", rec.Code, rec.Body)
+	}
+
+	rec, byHeader := send("/auth/refresh", "", login.RefreshToken)
+	if rec.Code != http.StatusOK || byHeader.RefreshToken == "" {
+		t.Fatalf("refresh with the Authorization header: got %d %s", rec.Code, rec.Body)
+	}
+	rec, byBody := send("/auth/refresh", `{"refresh_token":"`+byHeader.RefreshToken+`"}`, "")
+	if rec.Code != http.StatusOK || byBody.RefreshToken == "" {
+		t.Fatalf("refresh with the body: got %d %s", rec.Code, rec.Body)
+	}
+
+	if rec, _ := send("/auth/logout", `{"refresh_token":"`+byBody.RefreshToken+`"}`, ""); rec.Code != http.StatusOK {
+		t.Fatalf("logout: got %d %s", rec.Code, rec.Body)
+	}
+	if rec, _ := send("/auth/refresh", "", byBody.RefreshToken); rec.Code == http.StatusOK {
+		t.Error("refresh works after logout")
+	}
+	if rec, _ := send("/auth/refresh", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh without a token: got %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
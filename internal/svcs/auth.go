@@ -1,20 +1,24 @@
 package svcs
 
 import (
+	"context"
+	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
-	"fmt"
 	"time"
 
 	"booking.com/internal/config"
-	"booking.com/internal/db/postgresql/dao"
 	"booking.com/internal/db/postgresql/model"
 	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/notify"
+	"booking.com/internal/repo"
 	"booking.com/internal/utils"
+	"booking.com/internal/validation"
 	jwtauth "booking.com/pkg/auth/jwt-auth"
 	"booking.com/pkg/constants"
-	"gorm.io/gorm"
 )
 
 type AuthSvc struct {
@@ -25,46 +29,58 @@ func NewAuthSvc(cfg *config.AppConfig) *AuthSvc {
 	return &AuthSvc{AppCfg: cfg}
 }
 func (a *AuthSvc) RegisterUser(userReq *dto.CreateUser, userSvc *UserSvc) error {
+	_, err := a.register(userReq, constants.UserRole, userSvc)
+	return err
+}
+
+// CreateAdmin registers an admin, it is how the first admin of a deployment is made
+func (a *AuthSvc) CreateAdmin(userReq *dto.CreateUser, userSvc *UserSvc) (*model.User, error) {
+	return a.register(userReq, constants.AdminRole, userSvc)
+}
+
+// register creates a user with role and returns it with its generated username
+func (a *AuthSvc) register(userReq *dto.CreateUser, role string, userSvc *UserSvc) (*model.User, error) {
 	usr, err := userSvc.GetUserWithEmailOrPhone(userReq.Email, userReq.Phone, false)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		return err
+	if err != nil && !errors.Is(err, utils.ErrUserNotFound) {
+		return nil, err
 	}
 	if usr != nil {
 		var usrE []*model.User
 		if userReq.Email != "" {
 			usrE, err = userSvc.FilterUsers("", userReq.Email, "", false)
-			if err != nil && err != gorm.ErrRecordNotFound {
-				return err
+			if err != nil {
+				return nil, err
 			}
 		}
 		usrP, err := userSvc.FilterUsers("", "", userReq.Phone, false)
-		if err != nil && err != gorm.ErrRecordNotFound {
-			return err
+		if err != nil {
+			return nil, err
 		}
 		if len(usrE) > 0 && len(usrP) > 0 {
 			if usrE[0].Deleted && usrP[0].Deleted {
-				return errors.New(utils.ErrUserAlreadyExistsWithEmailAndPhone.Error() + ". Please activate")
+				return nil, utils.ErrUserAlreadyExistsWithEmailAndPhone.WithMsg(utils.ErrUserAlreadyExistsWithEmailAndPhone.Msg + ". Please activate")
 			}
-			return utils.ErrUserAlreadyExistsWithEmailAndPhone
+			return nil, utils.ErrUserAlreadyExistsWithEmailAndPhone
 		}
 		if len(usrE) > 0 && len(usrP) == 0 {
 			if usrE[0].Deleted {
-				return errors.New(utils.ErrUserAlreadyExistsWithEmail.Error() + ". Please activate")
+				return nil, utils.ErrUserAlreadyExistsWithEmail.WithMsg(utils.ErrUserAlreadyExistsWithEmail.Msg + ". Please activate")
 			}
-			return utils.ErrUserAlreadyExistsWithEmail
-		} else {
+			return nil, utils.ErrUserAlreadyExistsWithEmail
+		} else if len(usrP) > 0 {
 			if usrP[0].Deleted {
-				return errors.New(utils.ErrUserAlreadyExistsWithPhone.Error() + ". Please activate")
+				return nil, utils.ErrUserAlreadyExistsWithPhone.WithMsg(utils.ErrUserAlreadyExistsWithPhone.Msg + ". Please activate")
 			}
-			return utils.ErrUserAlreadyExistsWithPhone
+			return nil, utils.ErrUserAlreadyExistsWithPhone
 		}
+		return nil, utils.ErrUserAlreadyExistsWithEmailOrPhone
 	}
 	salt := utils.GetUUID()
 	hashedPass, err := utils.HashPassword(userReq.Password + salt)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = userSvc.CreateUser(&model.User{
+	user := &model.User{
 		FirstName:    userReq.FirstName,
 		LastName:     userReq.LastName,
 		Email:        userReq.Email,
@@ -72,23 +88,37 @@ func (a *AuthSvc) RegisterUser(userReq *dto.CreateUser, userSvc *UserSvc) error
 		PasswordHash: hashedPass,
 		Salt:         salt,
 		Address:      userReq.Address,
-		Role:         constants.UserRole,
+		Role:         role,
+		Locale:       notify.PreferredLocale(userReq.Locale),
 		Deleted:      false,
 		UpdatedAt:    time.Now(),
+	}
+	ctx := context.Background()
+	var created *model.User
+	err = userSvc.Repos.Transaction(ctx, func(tx *repo.Repos) error {
+		if err := tx.Users.Create(ctx, user); err != nil {
+			return err
+		}
+		// the username is generated by a trigger, read it back for the event
+		created, err = tx.Users.GetByEmailAndPhone(ctx, user.Email, user.Phone, false)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, tx, events.UserRegistered, events.AggregateUser, created.Username, events.NewUserPayload(created))
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return created, nil
 }
 func (a *AuthSvc) Login(reqUser dto.Login, userSvc *UserSvc) (string, string, error) {
 	user, err := userSvc.GetUserWithEmailOrPhone(reqUser.UserName, reqUser.UserName, true)
 	if err != nil {
+		if errors.Is(err, utils.ErrUserNotFound) {
+			return "", "", utils.ErrInvalidUserOrPass
+		}
 		return "", "", err
 	}
-	if user == nil {
-		return "", "", utils.ErrUserNotFound
-	}
 	if validPassword := utils.CheckPassword(user.PasswordHash, reqUser.Password+user.Salt); !validPassword {
 		return "", "", utils.ErrInvalidUserOrPass
 	}
@@ -97,38 +127,42 @@ func (a *AuthSvc) Login(reqUser dto.Login, userSvc *UserSvc) (string, string, er
 
 func (a *AuthSvc) Refresh(userName, refreshToken string, userSvc *UserSvc) (string, string, error) {
 	validateFunc := func(userName ...string) bool {
-		q := dao.User
-		user, _ := q.Where(q.Username.Eq(userName[0])).Where(q.Deleted.Is(false)).First()
+		user, _ := userSvc.GetUserByUserName(userName[0], true)
 		return user != nil
 	}
 	user, err := userSvc.GetUserByUserName(userName, true)
 	if err != nil {
 		return "", "", err
 	}
-	if user == nil {
-		return "", "", utils.ErrUserNotFound
-	}
 	validRefreshToken, err := jwtauth.IsTokenValid(refreshToken, user.Salt, validateFunc)
-	if err != nil {
-		return "", "", err
-	}
-	if !validRefreshToken {
-		return "", "", errors.New("invalid refresh_token")
+	if err != nil || !validRefreshToken {
+		return "", "", utils.ErrInvalidToken.WithMsg("invalid refresh_token").Wrap(err)
 	}
 	hash := sha256.Sum256([]byte(refreshToken))
 	if user.RefreshToken != hex.EncodeToString(hash[:]) {
-		return "", "", fmt.Errorf("refresh_token revoked")
+		return "", "", utils.ErrRefreshTokenRevoked
 	}
 	return a.getAccessAndRefreshTokens(user, userSvc)
 }
+
+// CsrfToken derives the csrf token of a session from its refresh token, a
+// page on another site can neither read the http only cookie nor compute it
+func CsrfToken(refreshToken string) string {
+	mac := hmac.New(sha256.New, []byte(refreshToken))
+	mac.Write([]byte(constants.CsrfToken))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ValidCsrfToken compares in constant time so the token can't be guessed byte by byte
+func ValidCsrfToken(refreshToken, csrfToken string) bool {
+	return hmac.Equal([]byte(CsrfToken(refreshToken)), []byte(csrfToken))
+}
+
 func (a *AuthSvc) LogOut(userName string, usrSvc *UserSvc) error {
 	user, err := usrSvc.GetUserByUserName(userName, true)
 	if err != nil {
 		return err
 	}
-	if user == nil {
-		return utils.ErrUserNotFound
-	}
 	if user.RefreshToken == "" {
 		return utils.ErrUserAlreadyLoggedOut
 	}
@@ -154,16 +188,42 @@ func (a *AuthSvc) getAccessAndRefreshTokens(user *model.User, userSvc *UserSvc)
 	}
 	return token, refreshToken, nil
 }
+
+// ActivateUser lets a user who deleted their account activate it again, users
+// deactivated by an admin are refused
 func (a *AuthSvc) ActivateUser(userName string, usrSvc *UserSvc) error {
 	user, err := usrSvc.GetUserWithEmailOrPhone(userName, userName, false)
 	if err != nil {
 		return err
 	}
-	if user == nil {
-		return utils.ErrUserNotFound
-	}
 	if !user.Deleted {
 		return utils.ErrUserAlreadyActivated
 	}
+	if user.DeactivatedByAdmin {
+		return utils.ErrUserDeactivatedByAdmin
+	}
 	return usrSvc.UpdateDelFlag(user.Username, false)
 }
+
+// ResetPassword sets a new password with a new salt, the salt signs the
+// tokens so every session of the user ends with it
+func (a *AuthSvc) ResetPassword(userName, password string, usrSvc *UserSvc) error {
+	if msg := validation.PasswordStrengthError(password); msg != "" {
+		return utils.ErrInvalidRequest.WithMsg("password " + msg)
+	}
+	if _, err := usrSvc.GetUserByUserName(userName, false); err != nil {
+		return err
+	}
+	salt := utils.GetUUID()
+	hashedPass, err := utils.HashPassword(password + salt)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	return usrSvc.Repos.Transaction(ctx, func(tx *repo.Repos) error {
+		if err := tx.Users.Update(ctx, userName, 0, &model.User{PasswordHash: hashedPass, Salt: salt}); err != nil {
+			return err
+		}
+		return tx.Users.UpdateRefreshToken(ctx, userName, "")
+	})
+}
@@ -0,0 +1,259 @@
+package svcs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"booking.com/internal/dto"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+)
+
+func TestRegisterUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		phone   string
+		deleted bool
+		wantErr error
+		wantMsg string
+	}{
+		{name: "new user", email: "new@example.com", phone: "+910000000002"},
+		{name: "email taken", email: "jane@example.com", phone: "+910000000002", wantErr: utils.ErrUserAlreadyExistsWithEmail},
+		{name: "phone taken", email: "new@example.com", phone: "+910000000001", wantErr: utils.ErrUserAlreadyExistsWithPhone},
+		{name: "email and phone taken", email: "jane@example.com", phone: "+910000000001", wantErr: utils.ErrUserAlreadyExistsWithEmailAndPhone},
+		{
+			name: "deleted user", email: "jane@example.com", phone: "+910000000001", deleted: true,
+			wantErr: utils.ErrUserAlreadyExistsWithEmailAndPhone, wantMsg: utils.ErrUserAlreadyExistsWithEmailAndPhone.Msg + ". Please activate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSvcs()
+			jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+			if tt.deleted {
+				if err := s.users.DelUser(jane); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := s.auth.RegisterUser(&dto.CreateUser{
+				FirstName: "John", LastName: "Doe", Email: tt.email, Phone: tt.phone, Password: "Secret#123",
+			}, s.users)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("got message %q, want %q", err.Error(), tt.wantMsg)
+			}
+			if tt.wantErr == nil {
+				user, err := s.users.GetUserWithEmailAndPhone(tt.email, tt.phone, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasPrefix(user.Username, "john_doe_") || len(user.Username) != len("john_doe_xxx") {
+					t.Errorf("unexpected generated username %q", user.Username)
+				}
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName string
+		password string
+		deleted  bool
+		wantErr  error
+	}{
+		{name: "by email", userName: "jane@example.com", password: "Secret#123"},
+		{name: "by phone", userName: "+910000000001", password: "Secret#123"},
+		{name: "wrong password", userName: "jane@example.com", password: "Secret#124", wantErr: utils.ErrInvalidUserOrPass},
+		{name: "unknown user", userName: "john@example.com", password: "Secret#123", wantErr: utils.ErrInvalidUserOrPass},
+		{name: "deleted user", userName: "jane@example.com", password: "Secret#123", deleted: true, wantErr: utils.ErrInvalidUserOrPass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSvcs()
+			jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+			if tt.deleted {
+				if err := s.users.DelUser(jane); err != nil {
+					t.Fatal(err)
+				}
+			}
+			token, refreshToken, err := s.auth.Login(dto.Login{UserName: tt.userName, Password: tt.password}, s.users)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (token == "" || refreshToken == "") {
+				t.Error("expected access and refresh tokens")
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(s *testSvcs, userName, refreshToken string) string
+		wantErr error
+	}{
+		{
+			name:    "valid token",
+			prepare: func(_ *testSvcs, _, refreshToken string) string { return refreshToken },
+		},
+		{
+			name: "revoked after logout",
+			prepare: func(s *testSvcs, userName, refreshToken string) string {
+				_ = s.auth.LogOut(userName, s.users)
+				return refreshToken
+			},
+			wantErr: utils.ErrRefreshTokenRevoked,
+		},
+		{
+			name:    "tampered token",
+			prepare: func(_ *testSvcs, _, refreshToken string) string { return refreshToken + "x" },
+			wantErr: utils.ErrInvalidToken,
+		},
+		{
+			name: "deleted user",
+			prepare: func(s *testSvcs, userName, refreshToken string) string {
+				_ = s.users.DelUser(userName)
+				return refreshToken
+			},
+			wantErr: utils.ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSvcs()
+			jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+			_, refreshToken, err := s.auth.Login(dto.Login{UserName: "jane@example.com", Password: "Secret#123"}, s.users)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token := tt.prepare(s, jane, refreshToken)
+			_, newRefreshToken, err := s.auth.Refresh(jane, token, s.users)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && newRefreshToken == "" {
+				t.Error("expected a new refresh token")
+			}
+		})
+	}
+}
+
+func TestCreateAdmin(t *testing.T) {
+	s := newTestSvcs()
+	admin, err := s.auth.CreateAdmin(&dto.CreateUser{
+		FirstName: "Ada", LastName: "Admin", Email: "ada@example.com", Phone: "+910000000009", Password: "Secret#123",
+	}, s.users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.Role != constants.AdminRole || admin.Username == "" {
+		t.Errorf("got role %q username %q, want an admin with a generated username", admin.Role, admin.Username)
+	}
+	if _, _, err := s.auth.Login(dto.Login{UserName: "ada@example.com", Password: "Secret#123"}, s.users); err != nil {
+		t.Errorf("admin login: %v", err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	_, refreshToken, err := s.auth.Login(dto.Login{UserName: "jane@example.com", Password: "Secret#123"}, s.users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.auth.ResetPassword(jane, "weak", s.users); !errors.Is(err, utils.ErrInvalidRequest) {
+		t.Fatalf("weak password: got %v, want %v", err, utils.ErrInvalidRequest)
+	}
+	if err := s.auth.ResetPassword("nobody_x_123", "Changed#456", s.users); !errors.Is(err, utils.ErrUserNotFound) {
+		t.Fatalf("unknown user: got %v, want %v", err, utils.ErrUserNotFound)
+	}
+	if err := s.auth.ResetPassword(jane, "Changed#456", s.users); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.auth.Login(dto.Login{UserName: "jane@example.com", Password: "Secret#123"}, s.users); !errors.Is(err, utils.ErrInvalidUserOrPass) {
+		t.Errorf("old password: got %v, want %v", err, utils.ErrInvalidUserOrPass)
+	}
+	if _, _, err := s.auth.Refresh(jane, refreshToken, s.users); err == nil {
+		t.Error("refresh token issued before the reset still works")
+	}
+	if _, _, err := s.auth.Login(dto.Login{UserName: "jane@example.com", Password: "Changed#456"}, s.users); err != nil {
+		t.Errorf("new password: %v", err)
+	}
+}
+
+func TestSetRole(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	if err := s.users.SetRole(jane, 0, "owner"); !errors.Is(err, utils.ErrInvalidRequest) {
+		t.Fatalf("unknown role: got %v, want %v", err, utils.ErrInvalidRequest)
+	}
+	if err := s.users.SetRole(jane, 0, constants.PartnerRole); err != nil {
+		t.Fatal(err)
+	}
+	user, err := s.users.GetUserByUserName(jane, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != constants.PartnerRole {
+		t.Errorf("got role %q, want %q", user.Role, constants.PartnerRole)
+	}
+}
+
+func TestActivateUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		byAdmin bool
+		wantErr error
+	}{
+		{name: "deleted by the user"},
+		{name: "deactivated by an admin", byAdmin: true, wantErr: utils.ErrUserDeactivatedByAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSvcs()
+			jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+			_, refreshToken, err := s.auth.Login(dto.Login{UserName: "jane@example.com", Password: "Secret#123"}, s.users)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.byAdmin {
+				err = s.users.Deactivate(jane)
+			} else {
+				err = s.users.DelUser(jane)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.auth.ActivateUser("jane@example.com", s.users); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			if _, err := s.users.GetUserByUserName(jane, true); !errors.Is(err, utils.ErrUserNotFound) {
+				t.Fatalf("refused activation left the user active: %v", err)
+			}
+			// only the admin reactivation lifts it, the old session stays revoked
+			if err := s.users.Reactivate(jane); err != nil {
+				t.Fatal(err)
+			}
+			user, err := s.users.GetUserByUserName(jane, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.DeactivatedByAdmin {
+				t.Error("reactivated user is still marked deactivated by an admin")
+			}
+			if _, _, err := s.auth.Refresh(jane, refreshToken, s.users); !errors.Is(err, utils.ErrRefreshTokenRevoked) {
+				t.Errorf("refresh with the token from before the deactivation: got %v, want %v", err, utils.ErrRefreshTokenRevoked)
+			}
+		})
+	}
+}
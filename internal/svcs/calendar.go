@@ -0,0 +1,201 @@
+package svcs
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"booking.com/internal/calendar"
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/repo"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+)
+
+// feedStatuses are the visits listed in a feed, the others are over
+var feedStatuses = []string{constants.Pending, constants.Accepted, constants.Rescheduled}
+
+type CalendarSvc struct {
+	AppCfg *config.AppConfig
+	Repos  *repo.Repos
+}
+
+func NewCalendarSvc(cfg *config.AppConfig, repos *repo.Repos) *CalendarSvc {
+	return &CalendarSvc{AppCfg: cfg, Repos: repos}
+}
+
+// CreateFeed issues a new feed token for the user, a previous token stops working
+func (c *CalendarSvc) CreateFeed(userName string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	if err := c.Repos.Calendar.SetFeed(context.Background(), userName, hashFeedToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (c *CalendarSvc) RevokeFeed(userName string) error {
+	err := c.Repos.Calendar.DeleteFeed(context.Background(), userName)
+	return dao.NotFoundAs(err, utils.ErrCalendarFeedNotFound)
+}
+
+// Feed renders the upcoming visits of the token's owner, the visits they
+// booked and the visits to their properties
+func (c *CalendarSvc) Feed(token string) ([]byte, error) {
+	ctx := context.Background()
+	userName, err := c.Repos.Calendar.UserByFeed(ctx, hashFeedToken(token))
+	if err != nil {
+		return nil, dao.NotFoundAs(err, utils.ErrCalendarFeedNotFound)
+	}
+	if _, err := c.Repos.Users.GetByUserName(ctx, userName, true); err != nil {
+		return nil, dao.NotFoundAs(err, utils.ErrCalendarFeedNotFound)
+	}
+	visits, _, err := c.Repos.Visits.Filter(ctx, userName, dto.VisitFilterReq{})
+	if err != nil {
+		return nil, err
+	}
+	rows := newVisitRows(c.Repos)
+	now := time.Now().UTC()
+	cal := &calendar.Calendar{
+		Method:          calendar.MethodPublish,
+		Name:            "BookMyLab visits",
+		RefreshInterval: c.AppCfg.Calendar.FeedRefresh,
+	}
+	for _, visit := range visits {
+		if !slices.Contains(feedStatuses, visit.Status) {
+			continue
+		}
+		start := visit.ScheduledTime
+		if visit.Status == constants.Rescheduled && !visit.RescheduleTime.IsZero() {
+			start = visit.RescheduleTime
+		}
+		if start.Add(c.AppCfg.Calendar.VisitDuration).Before(now) {
+			continue
+		}
+		calVisit, err := rows.visit(ctx, visit.ID, visit.PropertyID, visit.BuyerUsername)
+		if err != nil {
+			return nil, err
+		}
+		calVisit.Sequence = visit.Sequence
+		calVisit.Status = visit.Status
+		calVisit.Start = start
+		calVisit.Duration = c.AppCfg.Calendar.VisitDuration
+		cal.Events = append(cal.Events, calendar.VisitEvent(calVisit, visit.UpdatedAt))
+	}
+	return cal.Bytes(), nil
+}
+
+// FeedURLs returns the https and webcal urls of a feed token under the api version
+func (c *CalendarSvc) FeedURLs(version, token string) dto.CalendarFeedRsp {
+	path := "/" + version + "/calendar/feeds/" + token + ".ics"
+	base := c.AppCfg.Calendar.FeedBaseURL
+	rsp := dto.CalendarFeedRsp{URL: base + path, WebcalURL: base + path}
+	if _, host, ok := strings.Cut(base, "://"); ok {
+		rsp.WebcalURL = "webcal://" + host + path
+	}
+	return rsp
+}
+
+// Invite is the calendar mailed to both sides of a visit after a change
+type Invite struct {
+	Calendar *calendar.Calendar
+	Visit    calendar.Visit
+}
+
+// Invite builds the invite for a visit event, accepting or rescheduling
+// requests the event and cancelling or rejecting cancels it. It returns nil
+// when the change needs no invite, a visit cancelled while pending never had one
+func (c *CalendarSvc) Invite(ctx context.Context, payload events.VisitPayload) (*Invite, error) {
+	method := calendar.MethodRequest
+	switch payload.Status {
+	case constants.Accepted, constants.Rescheduled:
+	case constants.Cancelled, constants.Rejected:
+		if payload.PreviousStatus == constants.Pending {
+			return nil, nil
+		}
+		method = calendar.MethodCancel
+	default:
+		return nil, nil
+	}
+	visit, err := newVisitRows(c.Repos).visit(ctx, payload.ID, payload.PropertyID, payload.BuyerUsername)
+	if err != nil {
+		return nil, err
+	}
+	visit.Sequence = payload.Sequence
+	visit.Status = payload.Status
+	visit.Start = payload.ScheduledTime
+	if payload.Status == constants.Rescheduled && payload.RescheduleTime != nil {
+		visit.Start = *payload.RescheduleTime
+	}
+	visit.Duration = c.AppCfg.Calendar.VisitDuration
+	cal := &calendar.Calendar{Method: method, Events: []calendar.Event{calendar.VisitEvent(visit, time.Now().UTC())}}
+	return &Invite{Calendar: cal, Visit: visit}, nil
+}
+
+// visitRows loads the property and the users of visits, caching them as a
+// feed lists many visits of the same people
+type visitRows struct {
+	repos      *repo.Repos
+	properties map[int64]*model.Property
+	users      map[string]*model.User
+}
+
+func newVisitRows(repos *repo.Repos) *visitRows {
+	return &visitRows{repos: repos, properties: map[int64]*model.Property{}, users: map[string]*model.User{}}
+}
+
+// visit returns the calendar visit with its rows, the caller fills in the
+// status, times and sequence
+func (r *visitRows) visit(ctx context.Context, id, propertyID int64, buyerName string) (calendar.Visit, error) {
+	property, ok := r.properties[propertyID]
+	if !ok {
+		var err error
+		property, err = r.repos.Properties.GetByID(ctx, propertyID, false)
+		if err != nil {
+			return calendar.Visit{}, err
+		}
+		r.properties[propertyID] = property
+	}
+	partner, err := r.user(ctx, property.PartnerUsername)
+	if err != nil {
+		return calendar.Visit{}, err
+	}
+	buyer, err := r.user(ctx, buyerName)
+	if err != nil {
+		return calendar.Visit{}, err
+	}
+	return calendar.Visit{ID: id, Property: property, Partner: partner, Buyer: buyer}, nil
+}
+
+func (r *visitRows) user(ctx context.Context, userName string) (*model.User, error) {
+	if user, ok := r.users[userName]; ok {
+		return user, nil
+	}
+	user, err := r.repos.Users.GetByUserName(ctx, userName, false)
+	if errors.Is(err, dao.ErrNotFound) {
+		// a purged user still shows up by name
+		user, err = &model.User{Username: userName, FirstName: userName}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.users[userName] = user
+	return user, nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,104 @@
+package svcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"booking.com/internal/calendar"
+	"booking.com/internal/config"
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+)
+
+func TestCalendarInvitesAndFeed(t *testing.T) {
+	s := newTestSvcs()
+	partner := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	buyer := s.register(t, "John", "john@example.com", "+910000000002")
+	if err := s.properties.AddProperties(partner, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300, City: "Pune"}); err != nil {
+		t.Fatal(err)
+	}
+	properties, _ := s.properties.GetPropertiesByUserName(partner, true)
+	scheduled := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	if err := s.visits.ScheduleVisit(&dto.ScheduleReq{PropertyID: properties[0].ID, BuyerUsername: buyer, ScheduledTime: scheduled}, s.properties); err != nil {
+		t.Fatal(err)
+	}
+	visits, _, _ := s.visits.FilterVisits(buyer, &dto.VisitFilterReq{}, false)
+	id := visits[0].ID
+	later := scheduled.Add(24 * time.Hour)
+	for _, update := range []struct {
+		by  string
+		req dto.UpdateVisitReq
+	}{
+		{by: partner, req: dto.UpdateVisitReq{ID: id, Status: constants.Accepted}},
+		{by: partner, req: dto.UpdateVisitReq{ID: id, Status: constants.Rescheduled, RescheduleTime: &later}},
+	} {
+		if err := s.visits.UpdateVisit(update.by, 0, &update.req, s.properties); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cal := NewCalendarSvc(&config.AppConfig{Calendar: config.Calendar{FeedBaseURL: "https://api.example.com", VisitDuration: time.Hour}}, s.repos)
+	var invites []string
+	for _, row := range s.outboxEvents() {
+		if !strings.HasPrefix(row.EventType, events.VisitPrefix) {
+			continue
+		}
+		var payload events.VisitPayload
+		if err := json.Unmarshal([]byte(row.Payload), &payload); err != nil {
+			t.Fatal(err)
+		}
+		invite, err := cal.Invite(context.Background(), payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if invite != nil {
+			invites = append(invites, string(invite.Calendar.Bytes()))
+		}
+	}
+	// the request has no invite, then the acceptance and the reschedule update it
+	if len(invites) != 2 {
+		t.Fatalf("got %d invites, want 2", len(invites))
+	}
+	uid := "UID:" + calendar.VisitUID(id)
+	for i, want := range []string{"SEQUENCE:1", "SEQUENCE:2"} {
+		if !strings.Contains(invites[i], "METHOD:REQUEST") || !strings.Contains(invites[i], uid) || !strings.Contains(invites[i], want) {
+			t.Errorf("invite %d = %q, want METHOD:REQUEST, %s and %s", i, invites[i], uid, want)
+		}
+	}
+	if !strings.Contains(invites[1], "DTSTART:"+later.UTC().Format("20060102T150405Z")) {
+		t.Errorf("the update must move the event to the new time: %q", invites[1])
+	}
+	cancel, err := cal.Invite(context.Background(), events.VisitPayload{ID: id, PropertyID: properties[0].ID, BuyerUsername: buyer,
+		Status: constants.Cancelled, PreviousStatus: constants.Accepted, ScheduledTime: scheduled, Sequence: 3})
+	if err != nil || cancel.Calendar.Method != calendar.MethodCancel {
+		t.Fatalf("cancel invite = %+v, %v", cancel, err)
+	}
+
+	token, err := cal.CreateFeed(buyer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if urls := cal.FeedURLs(constants.APIv2, token); urls.WebcalURL != "webcal://api.example.com/v2/calendar/feeds/"+token+".ics" {
+		t.Errorf("feed urls = %+v", urls)
+	}
+	feed, err := cal.Feed(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(feed), uid) || !strings.Contains(string(feed), "STATUS:TENTATIVE") {
+		t.Errorf("feed is missing the rescheduled visit: %q", feed)
+	}
+	// a new token replaces the old one
+	if _, err := cal.CreateFeed(buyer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cal.Feed(token); !errors.Is(err, utils.ErrCalendarFeedNotFound) {
+		t.Errorf("Feed(old token) error = %v, want %v", err, utils.ErrCalendarFeedNotFound)
+	}
+}
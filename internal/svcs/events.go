@@ -0,0 +1,17 @@
+package svcs
+
+import (
+	"context"
+
+	"booking.com/internal/events"
+	"booking.com/internal/repo"
+)
+
+// publish adds a domain event to the outbox, tx must be the transaction of the state change
+func publish(ctx context.Context, tx *repo.Repos, eventType, aggregateType string, aggregateID, payload any) error {
+	event, err := events.New(eventType, aggregateType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	return tx.Outbox.Add(ctx, event)
+}
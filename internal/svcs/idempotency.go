@@ -0,0 +1,79 @@
+package svcs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/repo"
+	"booking.com/internal/utils"
+)
+
+// maxIdempotencyKey is the length of the idempotency_key column
+const maxIdempotencyKey = 255
+
+type IdempotencySvc struct {
+	AppCfg *config.AppConfig
+	Repos  *repo.Repos
+}
+
+func NewIdempotencySvc(cfg *config.AppConfig, repos *repo.Repos) *IdempotencySvc {
+	return &IdempotencySvc{AppCfg: cfg, Repos: repos}
+}
+
+// Fingerprint identifies a request, a retry must repeat the method, path and body
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin reserves the key for the request with the given fingerprint. It
+// returns the stored response when the same request already completed, and
+// nil when the caller runs the request and then completes or releases the key
+func (s *IdempotencySvc) Begin(userName, key, fingerprint string) (*model.IdempotencyKey, error) {
+	if len(key) > maxIdempotencyKey {
+		return nil, utils.ErrInvalidRequest.WithMsg("idempotency key is longer than 255 characters")
+	}
+	ctx := context.Background()
+	err := s.Repos.Idempotency.Reserve(ctx, &model.IdempotencyKey{
+		Username:       userName,
+		IdempotencyKey: key,
+		Fingerprint:    fingerprint,
+		ExpiresAt:      time.Now().UTC().Add(s.AppCfg.Idempotency.TTL),
+	})
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, dao.ErrConflict) {
+		return nil, err
+	}
+	stored, err := s.Repos.Idempotency.Get(ctx, userName, key)
+	if err != nil {
+		// the key expired or was released in between
+		return nil, dao.NotFoundAs(err, utils.ErrIdempotencyKeyInUse)
+	}
+	switch {
+	case stored.Fingerprint != fingerprint:
+		return nil, utils.ErrIdempotencyKeyReused
+	case stored.StatusCode == 0:
+		return nil, utils.ErrIdempotencyKeyInUse
+	}
+	return stored, nil
+}
+
+// Complete stores the response replayed for the retries of the request
+func (s *IdempotencySvc) Complete(userName, key string, statusCode int, contentType string, response []byte) error {
+	return s.Repos.Idempotency.Complete(context.Background(), userName, key, int32(statusCode), contentType, response)
+}
+
+// Release frees the key of a failed request so a retry runs it again
+func (s *IdempotencySvc) Release(userName, key string) error {
+	return s.Repos.Idempotency.Release(context.Background(), userName, key)
+}
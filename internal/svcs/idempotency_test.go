@@ -0,0 +1,45 @@
+package svcs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/utils"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	s := newTestSvcs()
+	user := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	idem := NewIdempotencySvc(&config.AppConfig{Idempotency: config.Idempotency{TTL: time.Hour}}, s.repos)
+	fingerprint := Fingerprint("POST", "/v1/visits", []byte(`{"property_id":1}`))
+
+	if stored, err := idem.Begin(user, "key-1", fingerprint); err != nil || stored != nil {
+		t.Fatalf("got %v %v, want the first request to run", stored, err)
+	}
+	if _, err := idem.Begin(user, "key-1", fingerprint); !errors.Is(err, utils.ErrIdempotencyKeyInUse) {
+		t.Fatalf("got %v, want the key in use while the first request runs", err)
+	}
+	if err := idem.Complete(user, "key-1", 201, "application/json", []byte(`{"status":"success"}`)); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := idem.Begin(user, "key-1", fingerprint)
+	if err != nil || stored == nil || stored.StatusCode != 201 || string(stored.Response) != `{"status":"success"}` {
+		t.Fatalf("got %+v %v, want the stored response", stored, err)
+	}
+	other := Fingerprint("POST", "/v1/visits", []byte(`{"property_id":2}`))
+	if _, err := idem.Begin(user, "key-1", other); !errors.Is(err, utils.ErrIdempotencyKeyReused) {
+		t.Fatalf("got %v, want the key rejected for a different body", err)
+	}
+
+	if _, err := idem.Begin(user, "key-2", fingerprint); err != nil {
+		t.Fatal(err)
+	}
+	if err := idem.Release(user, "key-2"); err != nil {
+		t.Fatal(err)
+	}
+	if stored, err := idem.Begin(user, "key-2", other); err != nil || stored != nil {
+		t.Fatalf("got %v %v, want a released key to run again", stored, err)
+	}
+}
@@ -0,0 +1,60 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/repo"
+	"booking.com/internal/utils"
+)
+
+type JobsSvc struct {
+	AppCfg *config.AppConfig
+	Repos  *repo.Repos
+}
+
+func NewJobsSvc(cfg *config.AppConfig, repos *repo.Repos) *JobsSvc {
+	return &JobsSvc{AppCfg: cfg, Repos: repos}
+}
+
+func (j *JobsSvc) FilterJobs(filterReq *dto.JobFilterReq) ([]*model.Job, int64, error) {
+	return j.Repos.Jobs.Filter(context.Background(), *filterReq)
+}
+
+func (j *JobsSvc) GetJob(id int64) (*model.Job, error) {
+	job, err := j.Repos.Jobs.GetByID(context.Background(), id)
+	if err != nil {
+		return nil, dao.NotFoundAs(err, utils.ErrJobNotFound)
+	}
+	return job, nil
+}
+
+// RetryJob queues a dead or cancelled job again with a fresh set of attempts
+func (j *JobsSvc) RetryJob(id int64) (*model.Job, error) {
+	return j.transition(id, "retried", j.Repos.Jobs.Retry)
+}
+
+// CancelJob stops a pending job from running
+func (j *JobsSvc) CancelJob(id int64) (*model.Job, error) {
+	return j.transition(id, "cancelled", j.Repos.Jobs.Cancel)
+}
+
+func (j *JobsSvc) transition(id int64, action string, apply func(ctx context.Context, id int64) error) (*model.Job, error) {
+	job, err := j.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	// the repository only matches jobs in a status the change is allowed from
+	if err := apply(ctx, id); err != nil {
+		if errors.Is(err, dao.ErrNotFound) {
+			return nil, utils.ErrInvalidJobTransition.WithMsg(job.Status + " job can't be " + action)
+		}
+		return nil, err
+	}
+	return j.GetJob(id)
+}
@@ -0,0 +1,180 @@
+package svcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/jobs"
+	"booking.com/internal/notify"
+	"booking.com/internal/realtime"
+	"booking.com/internal/repo"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+)
+
+type NotificationSvc struct {
+	AppCfg *config.AppConfig
+	Repos  *repo.Repos
+	// Realtime pushes new inbox entries to the open streams of the user when set
+	Realtime realtime.Publisher
+}
+
+func NewNotificationSvc(cfg *config.AppConfig, repos *repo.Repos) *NotificationSvc {
+	return &NotificationSvc{AppCfg: cfg, Repos: repos}
+}
+
+// Notice is a notification for one user, it is delivered on the channels the
+// user enabled for its type
+type Notice struct {
+	User  *model.User
+	Type  string
+	Title string
+	Body  string
+	Data  any
+	// EventID is the outbox event the notice comes from, a redelivered event notifies only once
+	EventID int64
+	// Template and TemplateData build the email, a notice without a template is never mailed
+	Template     string
+	TemplateData any
+}
+
+func (n *NotificationSvc) FilterNotifications(userName string, filterReq *dto.NotificationFilterReq) ([]*model.Notification, int64, error) {
+	return n.Repos.Notifications.Filter(context.Background(), userName, *filterReq)
+}
+
+func (n *NotificationSvc) UnreadCount(userName string) (int64, error) {
+	return n.Repos.Notifications.UnreadCount(context.Background(), userName)
+}
+
+func (n *NotificationSvc) MarkRead(userName string, id int64) error {
+	err := n.Repos.Notifications.MarkRead(context.Background(), userName, id)
+	return dao.NotFoundAs(err, utils.ErrNotificationNotFound)
+}
+
+func (n *NotificationSvc) MarkAllRead(userName string) (int64, error) {
+	return n.Repos.Notifications.MarkAllRead(context.Background(), userName)
+}
+
+// Preferences returns the preference of every notification type, types the
+// user never changed get the defaults of inbox and email on, sms off
+func (n *NotificationSvc) Preferences(userName string) ([]*model.NotificationPreference, error) {
+	stored, err := n.Repos.Notifications.Preferences(context.Background(), userName)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string]*model.NotificationPreference, len(stored))
+	for _, pref := range stored {
+		byType[pref.Type] = pref
+	}
+	prefs := make([]*model.NotificationPreference, 0, len(constants.NotificationTypes))
+	for _, notificationType := range constants.NotificationTypes {
+		pref, ok := byType[notificationType]
+		if !ok {
+			pref = &model.NotificationPreference{Username: userName, Type: notificationType, Inbox: true, Email: true}
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, nil
+}
+
+func (n *NotificationSvc) UpdatePreferences(userName string, updateReq *dto.UpdatePreferencesReq) ([]*model.NotificationPreference, error) {
+	prefs := make([]*model.NotificationPreference, 0, len(updateReq.Preferences))
+	for _, pref := range updateReq.Preferences {
+		prefs = append(prefs, &model.NotificationPreference{
+			Username: userName,
+			Type:     pref.Type,
+			Inbox:    pref.Inbox,
+			Email:    pref.Email,
+			Sms:      pref.Sms,
+		})
+	}
+	if err := n.Repos.Notifications.SetPreferences(context.Background(), prefs...); err != nil {
+		return nil, err
+	}
+	return n.Preferences(userName)
+}
+
+// Deliver stores the notice in the inbox and queues the email and sms the user
+// asked for, every step is keyed by the event so a retry never duplicates it
+func (n *NotificationSvc) Deliver(ctx context.Context, notice Notice) error {
+	user := notice.User
+	pref, err := n.preference(user.Username, notice.Type)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s:%d:%s", notice.Type, notice.EventID, user.Username)
+
+	if pref.Inbox {
+		data, err := json.Marshal(notice.Data)
+		if err != nil {
+			return fmt.Errorf("marshal %s data: %w", notice.Type, err)
+		}
+		notification := &model.Notification{
+			Username: user.Username,
+			Type:     notice.Type,
+			Title:    notice.Title,
+			Body:     notice.Body,
+			Data:     string(data),
+			EventID:  notice.EventID,
+		}
+		err = n.Repos.Notifications.Create(ctx, notification)
+		if err != nil && !errors.Is(err, dao.ErrConflict) {
+			return err
+		}
+		if err == nil && n.Realtime != nil {
+			msg, err := realtime.NewMessage(user.Username, realtime.TypeNotification, dto.NewNotificationRsp(notification))
+			if err != nil {
+				return err
+			}
+			// the inbox is the source of truth, a missed push is caught up on the next list
+			if err := n.Realtime.Publish(ctx, msg); err != nil {
+				log.Printf("realtime push of notification %d failed, error: %v", notification.ID, err)
+			}
+		}
+	}
+	if pref.Email && notice.Template != "" && user.Email != "" {
+		email, err := notify.NewEmail([]string{user.Email}, notice.Template, user.Locale, notice.TemplateData)
+		if err != nil {
+			return err
+		}
+		if err := jobs.Enqueue(ctx, n.Repos.Jobs, notify.KindSendMail, email, jobs.UniqueKey("mail:"+key)); err != nil {
+			return err
+		}
+	}
+	if pref.Sms && user.Phone != "" {
+		sms := notify.SMS{To: user.Phone, Body: notice.Title + ": " + notice.Body}
+		if err := jobs.Enqueue(ctx, n.Repos.Jobs, notify.KindSendSMS, sms, jobs.UniqueKey("sms:"+key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EmailEnabled reports whether the user gets notifications of the type by email
+func (n *NotificationSvc) EmailEnabled(userName, notificationType string) (bool, error) {
+	pref, err := n.preference(userName, notificationType)
+	if err != nil {
+		return false, err
+	}
+	return pref.Email, nil
+}
+
+func (n *NotificationSvc) preference(userName, notificationType string) (*model.NotificationPreference, error) {
+	prefs, err := n.Preferences(userName)
+	if err != nil {
+		return nil, err
+	}
+	for _, pref := range prefs {
+		if pref.Type == notificationType {
+			return pref, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown notification type %q", notificationType)
+}
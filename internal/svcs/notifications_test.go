@@ -0,0 +1,114 @@
+package svcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"booking.com/internal/dto"
+	"booking.com/internal/notify"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+)
+
+func TestDeliverFollowsPreferences(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	john := s.register(t, "John", "john@example.com", "+910000000002")
+	_, err := s.notices.UpdatePreferences(jane, &dto.UpdatePreferencesReq{Preferences: []dto.NotificationPreference{
+		{Type: constants.NotifyVisitRequested, Inbox: true, Sms: true},
+		{Type: constants.NotifyPriceDrop},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	janeUser, _ := s.users.GetUserByUserName(jane, true)
+	johnUser, _ := s.users.GetUserByUserName(john, true)
+
+	ctx := context.Background()
+	notices := []Notice{
+		{User: janeUser, Type: constants.NotifyVisitRequested, Title: "New visit request", EventID: 1, Template: notify.TemplateVisitStatus},
+		// a redelivered event must not notify twice
+		{User: janeUser, Type: constants.NotifyVisitRequested, Title: "New visit request", EventID: 1, Template: notify.TemplateVisitStatus},
+		{User: janeUser, Type: constants.NotifyPriceDrop, Title: "Price drop", EventID: 2, Template: notify.TemplatePriceDrop},
+		{User: johnUser, Type: constants.NotifyVisitAccepted, Title: "Visit accepted", EventID: 3, Template: notify.TemplateVisitStatus},
+	}
+	for _, notice := range notices {
+		if err := s.notices.Deliver(ctx, notice); err != nil {
+			t.Fatalf("Deliver(%s) error = %v", notice.Type, err)
+		}
+	}
+
+	queued := map[string]int{}
+	rows, _, _ := s.repos.Jobs.Filter(ctx, dto.JobFilterReq{})
+	for _, job := range rows {
+		queued[job.Kind]++
+	}
+	// jane only gets the sms of the visit request, john gets the default email
+	if queued[notify.KindSendSMS] != 1 || queued[notify.KindSendMail] != 1 {
+		t.Errorf("queued jobs = %v, want one sms and one mail", queued)
+	}
+
+	inbox, total, err := s.notices.FilterNotifications(jane, &dto.NotificationFilterReq{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || inbox[0].Type != constants.NotifyVisitRequested {
+		t.Fatalf("jane's inbox = %+v, want the visit request only", inbox)
+	}
+
+	if err := s.notices.MarkRead(john, inbox[0].ID); !errors.Is(err, utils.ErrNotificationNotFound) {
+		t.Errorf("marking another user's notification read, error = %v", err)
+	}
+	if err := s.notices.MarkRead(jane, inbox[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if unread, _ := s.notices.UnreadCount(jane); unread != 0 {
+		t.Errorf("jane unread = %d, want 0", unread)
+	}
+	if updated, _ := s.notices.MarkAllRead(john); updated != 1 {
+		t.Errorf("john marked %d read, want 1", updated)
+	}
+
+	prefs, err := s.notices.Preferences(john)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prefs) != len(constants.NotificationTypes) || !prefs[0].Inbox || !prefs[0].Email || prefs[0].Sms {
+		t.Errorf("default preferences = %+v", prefs)
+	}
+}
+
+func TestDeliverMailsInUserLocale(t *testing.T) {
+	s := newTestSvcs()
+	req := &dto.CreateUser{FirstName: "Jane", LastName: "Test", Email: "jane@example.com", Phone: "+910000000001",
+		Password: "Secret#123", Locale: "fr-FR,fr;q=0.9,en;q=0.8"}
+	if err := s.auth.RegisterUser(req, s.users); err != nil {
+		t.Fatal(err)
+	}
+	jane, err := s.users.GetUserWithEmailAndPhone(req.Email, req.Phone, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jane.Locale != "fr-FR" {
+		t.Fatalf("locale = %q, want fr-FR", jane.Locale)
+	}
+
+	ctx := context.Background()
+	notice := Notice{User: jane, Type: constants.NotifyVisitAccepted, Title: "Visit accepted", EventID: 1, Template: notify.TemplateVisitStatus}
+	if err := s.notices.Deliver(ctx, notice); err != nil {
+		t.Fatal(err)
+	}
+	rows, _, _ := s.repos.Jobs.Filter(ctx, dto.JobFilterReq{Kind: notify.KindSendMail})
+	if len(rows) != 1 {
+		t.Fatalf("queued %d mails, want 1", len(rows))
+	}
+	var email notify.Email
+	if err := json.Unmarshal([]byte(rows[0].Payload), &email); err != nil {
+		t.Fatal(err)
+	}
+	if email.Locale != "fr-FR" {
+		t.Errorf("mail locale = %q, want fr-FR", email.Locale)
+	}
+}
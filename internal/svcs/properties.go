@@ -2,29 +2,34 @@ package svcs
 
 import (
 	"context"
+	"strings"
 
 	"booking.com/internal/config"
 	"booking.com/internal/db/postgresql/dao"
 	"booking.com/internal/db/postgresql/model"
 	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/repo"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
 )
 
 type PropertySvc struct {
 	AppCfg *config.AppConfig
+	Repos  *repo.Repos
 }
 
-func NewPropertySvc(cfg *config.AppConfig) *PropertySvc {
-	return &PropertySvc{AppCfg: cfg}
+func NewPropertySvc(cfg *config.AppConfig, repos *repo.Repos) *PropertySvc {
+	return &PropertySvc{AppCfg: cfg, Repos: repos}
 }
 func (p *PropertySvc) AddProperties(userName string, properties ...dto.AddPropertyReq) error {
-	pr := dao.Property
 	daoProperties := make([]*model.Property, 0)
 	for _, property := range properties {
 		daoProperty := &model.Property{
 			PartnerUsername: userName,
 			Title:           property.Title,
 			Description:     property.Description,
-			PropertyType:    property.PropertyType,
+			PropertyType:    strings.ToLower(property.PropertyType),
 			Bedrooms:        property.Bedrooms,
 			Bathrooms:       property.Bathrooms,
 			AreaSqft:        property.AreaSqft,
@@ -32,23 +37,41 @@ func (p *PropertySvc) AddProperties(userName string, properties ...dto.AddProper
 			City:            property.City,
 			State:           property.State,
 			Address:         property.Address,
+			Status:          constants.Listed,
 		}
 		daoProperties = append(daoProperties, daoProperty)
 	}
-	return pr.Create(daoProperties...)
+	ctx := context.Background()
+	return p.Repos.Transaction(ctx, func(tx *repo.Repos) error {
+		if err := tx.Properties.Create(ctx, daoProperties...); err != nil {
+			return err
+		}
+		for _, property := range daoProperties {
+			if err := publish(ctx, tx, events.PropertyListed, events.AggregateProperty, property.ID, events.NewPropertyPayload(property)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-func (p *PropertySvc) UpdateProperty(property dto.UpdatePropertyReq) error {
-	_, err := p.GetPropertyByID(property.ID, true)
+// UpdateProperty writes the changed fields of an owned property, lowering the
+// price publishes a price drop for the users who saved the listing. A non zero
+// version must be the one the client read
+func (p *PropertySvc) UpdateProperty(userName string, version int32, property dto.UpdatePropertyReq) error {
+	current, err := p.GetOwnedProperty(userName, property.ID)
 	if err != nil {
 		return err
 	}
+	if version != 0 && version != current.Version {
+		return utils.ErrVersionMismatch
+	}
 
 	daoProperty := &model.Property{
 		// ID:           prop.ID,
 		Title:        property.Title,
 		Description:  property.Description,
-		PropertyType: property.PropertyType,
+		PropertyType: strings.ToLower(property.PropertyType),
 		Bedrooms:     property.Bedrooms,
 		Bathrooms:    property.Bathrooms,
 		AreaSqft:     property.AreaSqft,
@@ -59,95 +82,95 @@ func (p *PropertySvc) UpdateProperty(property dto.UpdatePropertyReq) error {
 	}
 
 	ctx := context.Background()
-	pr := dao.Property.WithContext(ctx)
-
-	_, err = pr.Where(dao.Property.ID.Eq(property.ID), dao.Property.Deleted.Is(false)).
-		Updates(daoProperty)
-	return err
+	return p.Repos.Transaction(ctx, func(tx *repo.Repos) error {
+		if err := tx.Properties.Update(ctx, property.ID, version, daoProperty); err != nil {
+			return dao.NotFoundAs(err, utils.ErrVersionMismatch)
+		}
+		if daoProperty.Price == 0 || daoProperty.Price >= current.Price {
+			return nil
+		}
+		updated, err := tx.Properties.GetByID(ctx, property.ID, true)
+		if err != nil {
+			return err
+		}
+		payload := events.NewPropertyPayload(updated)
+		payload.PreviousPrice = current.Price
+		return publish(ctx, tx, events.PropertyPriceDropped, events.AggregateProperty, updated.ID, payload)
+	})
 }
-func (p *PropertySvc) GetPropertyByID(id int64, withDelFlag bool) (*model.Property, error) {
-	pr := dao.Property.WithContext(context.Background())
-	usr := dao.User
-	pr = pr.Join(usr, usr.Username.EqCol(dao.Property.PartnerUsername))
-	if withDelFlag {
-		pr = pr.Where(dao.Property.Deleted.Is(false), usr.Deleted.Is(false))
+
+// PatchProperty applies a JSON Merge Patch to an owned property and returns
+// the stored result. Only the present members are written, so zero values and
+// cleared fields stick, lowering the price publishes a price drop
+func (p *PropertySvc) PatchProperty(userName string, id int64, version int32, patch dto.PatchPropertyReq) (*model.Property, error) {
+	current, err := p.GetOwnedProperty(userName, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != current.Version {
+		return nil, utils.ErrVersionMismatch
 	}
-	property, err := pr.First()
+	daoProperty := &model.Property{}
+	columns := patch.Apply(daoProperty)
+	if len(columns) == 0 {
+		return current, nil
+	}
+	daoProperty.PropertyType = strings.ToLower(daoProperty.PropertyType)
+
+	var updated *model.Property
+	ctx := context.Background()
+	err = p.Repos.Transaction(ctx, func(tx *repo.Repos) error {
+		if err := tx.Properties.Patch(ctx, id, version, daoProperty, columns...); err != nil {
+			return dao.NotFoundAs(err, utils.ErrVersionMismatch)
+		}
+		property, err := tx.Properties.GetByID(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		updated = property
+		if updated.Price >= current.Price {
+			return nil
+		}
+		payload := events.NewPropertyPayload(updated)
+		payload.PreviousPrice = current.Price
+		return publish(ctx, tx, events.PropertyPriceDropped, events.AggregateProperty, updated.ID, payload)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return property, nil
+	return updated, nil
 }
 
-func (p *PropertySvc) GetPropertiesByUserName(userName string, withDelFlag bool) ([]*model.Property, error) {
-	pr := dao.Property.WithContext(context.Background())
-	usr := dao.User
-	pr = pr.Join(usr, usr.Username.EqCol(dao.Property.PartnerUsername))
-	if withDelFlag {
-		pr = pr.Where(dao.Property.Deleted.Is(false), usr.Deleted.Is(false))
+func (p *PropertySvc) GetPropertyByID(id int64, withDelFlag bool) (*model.Property, error) {
+	property, err := p.Repos.Properties.GetByID(context.Background(), id, withDelFlag)
+	if err != nil {
+		return nil, dao.NotFoundAs(err, utils.ErrPropertyNotFound)
 	}
-	properties, err := pr.Find()
+	return property, nil
+}
+
+// GetOwnedProperty returns the property only when it is listed by the given partner
+func (p *PropertySvc) GetOwnedProperty(userName string, id int64) (*model.Property, error) {
+	property, err := p.GetPropertyByID(id, true)
 	if err != nil {
 		return nil, err
 	}
-	return properties, nil
+	if property.PartnerUsername != userName {
+		return nil, utils.ErrNotPropertyOwner
+	}
+	return property, nil
+}
+
+func (p *PropertySvc) GetPropertiesByUserName(userName string, withDelFlag bool) ([]*model.Property, error) {
+	return p.Repos.Properties.ListByPartner(context.Background(), userName, withDelFlag)
 }
 func (s *PropertySvc) GetFilteredProperties(
 	userName string,
 	filterReq dto.PropertFilterReq,
 	withDelFlag bool,
-) ([]*model.Property, error) {
-
-	ctx := context.Background()
-	pr := dao.Property.WithContext(ctx)
-	usr := dao.User.WithContext(ctx)
-
-	pr = pr.LeftJoin(usr, dao.User.Username.EqCol(dao.Property.PartnerUsername))
-
-	if filterReq.Id != 0 {
-		pr = pr.Where(dao.Property.ID.Eq(filterReq.Id))
-	}
-	if filterReq.Title != "" {
-		pr = pr.Where(dao.Property.Title.Like("%" + filterReq.Title + "%"))
-	}
-	if filterReq.PartnerName != "" {
-		pr = pr.Where(dao.Property.PartnerUsername.Like("%" + filterReq.PartnerName + "%"))
-	}
-
-	if filterReq.From_Price != 0 && filterReq.To_Price != 0 {
-		pr = pr.Where(dao.Property.Price.Between(filterReq.From_Price, filterReq.To_Price))
-	} else if filterReq.From_Price != 0 {
-		pr = pr.Where(dao.Property.Price.Between(0, filterReq.From_Price))
-	} else if filterReq.To_Price != 0 {
-		pr = pr.Where(dao.Property.Price.Between(0, filterReq.To_Price))
-	}
-
-	if filterReq.City != "" {
-		pr = pr.Where(dao.Property.City.Like("%" + filterReq.City + "%"))
-	}
-
-	if filterReq.State != "" {
-		pr = pr.Where(dao.Property.State.Like("%" + filterReq.State + "%"))
-	}
-
-	if filterReq.Status != "" {
-		pr = pr.Where(dao.Property.Status.Eq(filterReq.Status))
-	}
-
-	if filterReq.ExcludeSelf {
-		pr = pr.Where(dao.Property.PartnerUsername.Neq(userName))
-	}
-	if withDelFlag {
-		pr = pr.Where(
-			dao.Property.Deleted.Is(false),
-			dao.User.Deleted.Is(false), // safe now because join is already applied
-		)
-	}
-
-	return pr.Find()
+) ([]*model.Property, int64, error) {
+	return s.Repos.Properties.Filter(context.Background(), userName, filterReq, withDelFlag)
 }
 func (p *PropertySvc) DeletePropertyByID(id int64, deleteFlag bool) error {
-	pr := dao.Property.WithContext(context.Background())
-	_, err := pr.Where(dao.Property.ID.Eq(id)).Select(dao.Property.Deleted).Updates(&model.Property{Deleted: deleteFlag})
-	return err
+	return dao.NotFoundAs(p.Repos.Properties.SetDeleted(context.Background(), id, deleteFlag), utils.ErrPropertyNotFound)
 }
@@ -0,0 +1,192 @@
+package svcs
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/utils"
+)
+
+func TestGetFilteredProperties(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	john := s.register(t, "John", "john@example.com", "+910000000002")
+	if err := s.properties.AddProperties(jane,
+		dto.AddPropertyReq{Title: "Sea view flat", PropertyType: "Apartment", Price: 100, City: "Mumbai", State: "MH"},
+		dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300, City: "Pune", State: "MH"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.properties.AddProperties(john,
+		dto.AddPropertyReq{Title: "City condo", PropertyType: "Condo", Price: 200, City: "Bengaluru", State: "KA"},
+		dto.AddPropertyReq{Title: "Old flat", PropertyType: "Apartment", Price: 50, City: "Mumbai", State: "MH"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	johns, err := s.properties.GetPropertiesByUserName(john, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.properties.DeletePropertyByID(johns[1].ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		userName  string
+		filter    dto.PropertFilterReq
+		wantTitle []string
+		wantTotal int64
+	}{
+		{name: "all active, newest first", filter: dto.PropertFilterReq{}, wantTitle: []string{"City condo", "Hill house", "Sea view flat"}, wantTotal: 3},
+		{name: "by city", filter: dto.PropertFilterReq{City: "Mumbai"}, wantTitle: []string{"Sea view flat"}, wantTotal: 1},
+		{name: "by state", filter: dto.PropertFilterReq{State: "MH"}, wantTitle: []string{"Hill house", "Sea view flat"}, wantTotal: 2},
+		{name: "by title", filter: dto.PropertFilterReq{Title: "house"}, wantTitle: []string{"Hill house"}, wantTotal: 1},
+		{name: "price range", filter: dto.PropertFilterReq{From_Price: 150, To_Price: 300}, wantTitle: []string{"City condo", "Hill house"}, wantTotal: 2},
+		{name: "by partner", filter: dto.PropertFilterReq{PartnerName: john}, wantTitle: []string{"City condo"}, wantTotal: 1},
+		{name: "exclude self", userName: jane, filter: dto.PropertFilterReq{ExcludeSelf: true}, wantTitle: []string{"City condo"}, wantTotal: 1},
+		{
+			name:      "paginated",
+			filter:    dto.PropertFilterReq{PageReq: dto.PageReq{Page: 2, Limit: 2}},
+			wantTitle: []string{"Sea view flat"},
+			wantTotal: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			properties, total, err := s.properties.GetFilteredProperties(tt.userName, tt.filter, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			titles := make([]string, 0, len(properties))
+			for _, p := range properties {
+				titles = append(titles, p.Title)
+			}
+			if !slices.Equal(titles, tt.wantTitle) {
+				t.Errorf("got %v, want %v", titles, tt.wantTitle)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("got total %d, want %d", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestAddPropertiesPublishesInTransaction(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	if err := s.properties.AddProperties(jane, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300}); err != nil {
+		t.Fatal(err)
+	}
+	// an unknown partner violates the foreign key, neither the property nor its event may be stored
+	if err := s.properties.AddProperties("nobody", dto.AddPropertyReq{Title: "Ghost house", PropertyType: "House", Price: 300}); err == nil {
+		t.Fatal("expected an error for an unknown partner")
+	}
+	var types []string
+	for _, event := range s.outboxEvents() {
+		types = append(types, event.EventType)
+	}
+	want := []string{events.UserRegistered, events.PropertyListed}
+	if !slices.Equal(types, want) {
+		t.Errorf("got events %v, want %v", types, want)
+	}
+}
+
+func TestUpdatePropertyPublishesPriceDrop(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	if err := s.properties.AddProperties(jane, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300}); err != nil {
+		t.Fatal(err)
+	}
+	props, err := s.properties.GetPropertiesByUserName(jane, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := props[0].ID
+	for _, update := range []dto.UpdatePropertyReq{
+		{ID: id, Title: "Hill house with garden"},
+		{ID: id, Price: 350},
+		{ID: id, Price: 250},
+	} {
+		if err := s.properties.UpdateProperty(jane, 0, update); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outbox := s.outboxEvents()
+	last := outbox[len(outbox)-1]
+	if len(outbox) != 3 || last.EventType != events.PropertyPriceDropped {
+		t.Fatalf("got %d events, last %s, want a single price drop after the listing", len(outbox), last.EventType)
+	}
+	var payload events.PropertyPayload
+	if err := json.Unmarshal([]byte(last.Payload), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Price != 250 || payload.PreviousPrice != 350 {
+		t.Errorf("price drop payload = %+v", payload)
+	}
+}
+
+func TestUpdatePropertyChecksVersion(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	if err := s.properties.AddProperties(jane, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300}); err != nil {
+		t.Fatal(err)
+	}
+	props, _ := s.properties.GetPropertiesByUserName(jane, true)
+	id := props[0].ID
+	if props[0].Version != 1 {
+		t.Fatalf("got version %d, want new properties at 1", props[0].Version)
+	}
+	if err := s.properties.UpdateProperty(jane, 1, dto.UpdatePropertyReq{ID: id, Price: 280}); err != nil {
+		t.Fatal(err)
+	}
+	// the other agent still holds version 1
+	if err := s.properties.UpdateProperty(jane, 1, dto.UpdatePropertyReq{ID: id, Price: 260}); !errors.Is(err, utils.ErrVersionMismatch) {
+		t.Fatalf("got %v, want a version mismatch", err)
+	}
+	// writing the stored values changes nothing and keeps the version
+	if err := s.properties.UpdateProperty(jane, 2, dto.UpdatePropertyReq{ID: id, Price: 280}); err != nil {
+		t.Fatal(err)
+	}
+	property, _ := s.properties.GetPropertyByID(id, true)
+	if property.Price != 280 || property.Version != 2 {
+		t.Errorf("got price %v version %d, want 280 at version 2", property.Price, property.Version)
+	}
+}
+
+func TestPatchProperty(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	if err := s.properties.AddProperties(jane, dto.AddPropertyReq{
+		Title: "Hill house", Description: "Quiet", PropertyType: "House", Bedrooms: 3, Price: 300, City: "Pune",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	props, _ := s.properties.GetPropertiesByUserName(jane, true)
+	id := props[0].ID
+
+	var patch dto.PatchPropertyReq
+	if err := json.Unmarshal([]byte(`{"bedrooms": 0, "description": null, "price": 250}`), &patch); err != nil {
+		t.Fatal(err)
+	}
+	property, err := s.properties.PatchProperty(jane, id, 1, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if property.Bedrooms != 0 || property.Description != "" || property.Price != 250 {
+		t.Errorf("got bedrooms %d description %q price %v, want the patched values", property.Bedrooms, property.Description, property.Price)
+	}
+	if property.Title != "Hill house" || property.City != "Pune" || property.Version != 2 {
+		t.Errorf("got title %q city %q version %d, want the members left out kept at version 2", property.Title, property.City, property.Version)
+	}
+	if last := s.outboxEvents()[len(s.outboxEvents())-1]; last.EventType != events.PropertyPriceDropped {
+		t.Errorf("got last event %s, want a price drop", last.EventType)
+	}
+	if _, err := s.properties.PatchProperty(jane, id, 1, patch); !errors.Is(err, utils.ErrVersionMismatch) {
+		t.Fatalf("got %v, want a version mismatch", err)
+	}
+}
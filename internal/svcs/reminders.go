@@ -0,0 +1,68 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/events"
+	"booking.com/internal/jobs"
+	"booking.com/pkg/constants"
+)
+
+// Job kinds of accepted visits, reminders go to both sides before the visit
+// and the follow-up asks the partner to mark it completed or no-show
+const (
+	KindVisitReminder = "visit.reminder"
+	KindVisitFollowUp = "visit.follow_up"
+)
+
+// VisitReminder is the payload of the reminder and follow-up jobs, Sequence
+// ties it to the acceptance it was scheduled for
+type VisitReminder struct {
+	VisitID  int64 `json:"visit_id"`
+	Sequence int32 `json:"sequence"`
+	// Before is how long before the visit a reminder is due, it is zero for the follow-up
+	Before time.Duration `json:"before,omitempty"`
+}
+
+// ScheduleReminders enqueues the reminders and the follow-up of an accepted
+// visit, reminders whose time already passed are skipped
+func (v *VisitsSvc) ScheduleReminders(ctx context.Context, visit events.VisitPayload) error {
+	now := time.Now()
+	for _, before := range v.AppCfg.Visits.ReminderOffsets {
+		runAt := visit.ScheduledTime.Add(-before)
+		if before <= 0 || runAt.Before(now) {
+			continue
+		}
+		reminder := VisitReminder{VisitID: visit.ID, Sequence: visit.Sequence, Before: before}
+		err := jobs.Enqueue(ctx, v.Repos.Jobs, KindVisitReminder, reminder, jobs.RunAt(runAt),
+			jobs.UniqueKey(fmt.Sprintf("reminder:%d:%d:%s", visit.ID, visit.Sequence, before)))
+		if err != nil {
+			return err
+		}
+	}
+	followUp := VisitReminder{VisitID: visit.ID, Sequence: visit.Sequence}
+	return jobs.Enqueue(ctx, v.Repos.Jobs, KindVisitFollowUp, followUp,
+		jobs.RunAt(visit.ScheduledTime.Add(v.AppCfg.Visits.FollowUpDelay)),
+		jobs.UniqueKey(fmt.Sprintf("follow_up:%d:%d", visit.ID, visit.Sequence)))
+}
+
+// ReminderVisit returns the visit of a reminder, it is nil when the visit was
+// deleted or changed since the reminder was scheduled so nothing is due
+func (v *VisitsSvc) ReminderVisit(ctx context.Context, reminder VisitReminder) (*model.Visit, error) {
+	visit, err := v.Repos.Visits.GetByID(ctx, reminder.VisitID)
+	if errors.Is(err, dao.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if visit.Status != constants.Accepted || visit.Sequence != reminder.Sequence {
+		return nil, nil
+	}
+	return visit, nil
+}
@@ -0,0 +1,77 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/repo"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+)
+
+type ReviewSvc struct {
+	AppCfg *config.AppConfig
+	Repos  *repo.Repos
+}
+
+func NewReviewSvc(cfg *config.AppConfig, repos *repo.Repos) *ReviewSvc {
+	return &ReviewSvc{AppCfg: cfg, Repos: repos}
+}
+
+// AddReview rates the partner of a property, only a buyer whose visit to the
+// property was completed can review it and only once
+func (r *ReviewSvc) AddReview(userName string, propertyID int64, reviewReq *dto.CreateReviewReq, propertySvc *PropertySvc) (*model.Rating, error) {
+	property, err := propertySvc.GetPropertyByID(propertyID, true)
+	if err != nil {
+		return nil, err
+	}
+	if property.PartnerUsername == userName {
+		return nil, utils.ErrReviewNotAllowed.WithMsg("partner can't review own property")
+	}
+	ctx := context.Background()
+	_, visited, err := r.Repos.Visits.Filter(ctx, userName, dto.VisitFilterReq{
+		Status:         constants.Completed,
+		PropertyID:     propertyID,
+		BuyersUserName: userName,
+		PageReq:        dto.PageReq{Page: 1, Limit: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if visited == 0 {
+		return nil, utils.ErrReviewNotAllowed
+	}
+	rating := &model.Rating{
+		PropertyID:      propertyID,
+		BuyerUsername:   userName,
+		PartnerUsername: property.PartnerUsername,
+		Rating:          reviewReq.Rating,
+		ReviewText:      reviewReq.ReviewText,
+	}
+	err = r.Repos.Transaction(ctx, func(tx *repo.Repos) error {
+		if err := tx.Ratings.Create(ctx, rating); err != nil {
+			if errors.Is(err, dao.ErrConflict) {
+				return utils.ErrAlreadyReviewed
+			}
+			return err
+		}
+		payload := events.ReviewPayload{
+			ID:              rating.ID,
+			PropertyID:      rating.PropertyID,
+			PartnerUsername: rating.PartnerUsername,
+			BuyerUsername:   rating.BuyerUsername,
+			Rating:          rating.Rating,
+			ReviewText:      rating.ReviewText,
+		}
+		return publish(ctx, tx, events.ReviewReceived, events.AggregateReview, rating.ID, payload)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
@@ -0,0 +1,95 @@
+package svcs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+)
+
+func TestAddReview(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		byPartner bool
+		twice     bool
+		missing   bool
+		wantErr   error
+	}{
+		{name: "completed visit", status: constants.Completed},
+		{name: "visit not completed", status: constants.Accepted, wantErr: utils.ErrReviewNotAllowed},
+		{name: "partner reviews own property", status: constants.Completed, byPartner: true, wantErr: utils.ErrReviewNotAllowed},
+		{name: "second review", status: constants.Completed, twice: true, wantErr: utils.ErrAlreadyReviewed},
+		{name: "missing property", status: constants.Completed, missing: true, wantErr: utils.ErrPropertyNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSvcs()
+			reviews := NewReviewSvc(s.visits.AppCfg, s.repos)
+			partner := s.register(t, "Jane", "jane@example.com", "+910000000001")
+			buyer := s.register(t, "John", "john@example.com", "+910000000002")
+			if err := s.properties.AddProperties(partner, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300}); err != nil {
+				t.Fatal(err)
+			}
+			properties, err := s.properties.GetPropertiesByUserName(partner, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			propertyID := properties[0].ID
+			if err := s.visits.ScheduleVisit(&dto.ScheduleReq{PropertyID: propertyID, BuyerUsername: buyer, ScheduledTime: time.Now().Add(time.Hour)}, s.properties); err != nil {
+				t.Fatal(err)
+			}
+			visits, _, err := s.visits.FilterVisits(buyer, &dto.VisitFilterReq{}, false)
+			if err != nil || len(visits) != 1 {
+				t.Fatalf("expected one visit, got %d: %v", len(visits), err)
+			}
+			if err := s.repos.Visits.Update(t.Context(), visits[0].ID, 0, &model.Visit{Status: tt.status}); err != nil {
+				t.Fatal(err)
+			}
+
+			reviewer := buyer
+			if tt.byPartner {
+				reviewer = partner
+			}
+			if tt.missing {
+				propertyID++
+			}
+			if tt.twice {
+				if _, err := reviews.AddReview(reviewer, propertyID, &dto.CreateReviewReq{Rating: 2}, s.properties); err != nil {
+					t.Fatal(err)
+				}
+			}
+			published := len(s.outboxEvents())
+			rating, err := reviews.AddReview(reviewer, propertyID, &dto.CreateReviewReq{Rating: 4, ReviewText: "Bright and airy"}, s.properties)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			outbox := s.outboxEvents()
+			if tt.wantErr != nil {
+				if len(outbox) != published {
+					t.Errorf("rejected review published %s", outbox[len(outbox)-1].EventType)
+				}
+				return
+			}
+			last := outbox[len(outbox)-1]
+			if last.EventType != events.ReviewReceived || last.AggregateType != events.AggregateReview {
+				t.Fatalf("got last event %s of %s, want %s", last.EventType, last.AggregateType, events.ReviewReceived)
+			}
+			if rating.PartnerUsername != partner {
+				t.Errorf("review rates %s, want the partner %s", rating.PartnerUsername, partner)
+			}
+			user, err := s.users.GetUserByUserName(partner, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.Rating != 4 {
+				t.Errorf("partner rating %v, want 4", user.Rating)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package svcs
+
+import (
+	"testing"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/repo"
+	"booking.com/internal/repo/memrepo"
+)
+
+type testSvcs struct {
+	repos      *repo.Repos
+	auth       *AuthSvc
+	users      *UserSvc
+	properties *PropertySvc
+	visits     *VisitsSvc
+	notices    *NotificationSvc
+}
+
+func newTestSvcs() *testSvcs {
+	cfg := &config.AppConfig{Jwt: config.Jwt{AccessTokenExpiry: 15, RefreshTokenExpiry: 60}}
+	repos := memrepo.New()
+	return &testSvcs{
+		repos:      repos,
+		auth:       NewAuthSvc(cfg),
+		users:      NewUserSvc(cfg, repos),
+		properties: NewPropertySvc(cfg, repos),
+		visits:     NewVisitsSvc(cfg, repos),
+		notices:    NewNotificationSvc(cfg, repos),
+	}
+}
+
+// register creates a user and returns the generated username
+func (s *testSvcs) register(t *testing.T, first, email, phone string) string {
+	t.Helper()
+	req := &dto.CreateUser{FirstName: first, LastName: "Test", Email: email, Phone: phone, Password: "Secret#123"}
+	if err := s.auth.RegisterUser(req, s.users); err != nil {
+		t.Fatalf("register %s: %v", email, err)
+	}
+	user, err := s.users.GetUserWithEmailAndPhone(email, phone, true)
+	if err != nil {
+		t.Fatalf("lookup %s: %v", email, err)
+	}
+	return user.Username
+}
+
+// outboxEvents lists the events published so far
+func (s *testSvcs) outboxEvents() []*model.Outbox {
+	return s.repos.Outbox.(interface{ Events() []*model.Outbox }).Events()
+}
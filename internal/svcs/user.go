@@ -2,118 +2,123 @@ package svcs
 
 import (
 	"context"
+	"slices"
 
 	"booking.com/internal/config"
 	"booking.com/internal/db/postgresql/dao"
 	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/repo"
 	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
 )
 
 type UserSvc struct {
 	AppCfg *config.AppConfig
+	Repos  *repo.Repos
 }
 
-func NewUserSvc(cfg *config.AppConfig) *UserSvc {
-	return &UserSvc{AppCfg: cfg}
+func NewUserSvc(cfg *config.AppConfig, repos *repo.Repos) *UserSvc {
+	return &UserSvc{AppCfg: cfg, Repos: repos}
 }
 func (u *UserSvc) CreateUser(user *model.User) error {
-	usr := dao.User
-	return usr.Save(user)
+	return u.Repos.Users.Create(context.Background(), user)
 }
-func (u *UserSvc) UpdateUser(userName string, user *model.User) error {
-	usr := dao.User
-	_, err := u.FilterUsers(userName, "", "", true)
+
+// UpdateUser writes the non zero fields of an active user, a non zero version
+// must be the one the client read
+func (u *UserSvc) UpdateUser(userName string, version int32, user *model.User) error {
+	users, err := u.FilterUsers(userName, "", "", true)
 	if err != nil {
 		return err
 	}
-	_, err = usr.Where(usr.Username.Eq(userName)).
-		Updates(user)
-	if err != nil {
-		return err
+	if len(users) == 0 {
+		return utils.ErrUserNotFound
+	}
+	if version != 0 && version != users[0].Version {
+		return utils.ErrVersionMismatch
 	}
-	return nil
+	err = u.Repos.Users.Update(context.Background(), userName, version, user)
+	return dao.NotFoundAs(err, utils.ErrVersionMismatch)
 }
-func (u *UserSvc) GettAllUsers(page, limit int) ([]*model.User, error) {
-	offset := (page - 1) * 10
-	usr := dao.User
-	users, _, err := usr.Where(usr.Deleted.Is(false)).FindByPage(offset, limit)
+
+// PatchUser applies a JSON Merge Patch to an active user and returns the
+// stored result, only the present members are written
+func (u *UserSvc) PatchUser(userName string, version int32, patch dto.PatchUser) (*model.User, error) {
+	current, err := u.GetUserByUserName(userName, true)
 	if err != nil {
 		return nil, err
 	}
-	return users, nil
+	if version != 0 && version != current.Version {
+		return nil, utils.ErrVersionMismatch
+	}
+	user := &model.User{}
+	columns := patch.Apply(user)
+	if len(columns) == 0 {
+		return current, nil
+	}
+	ctx := context.Background()
+	if err := u.Repos.Users.Patch(ctx, userName, version, user, columns...); err != nil {
+		return nil, dao.NotFoundAs(err, utils.ErrVersionMismatch)
+	}
+	return u.GetUserByUserName(userName, true)
+}
+
+// SetRole changes the role of an active user to user, partner or admin, a non
+// zero version must be the one the client read
+func (u *UserSvc) SetRole(userName string, version int32, role string) error {
+	if !slices.Contains([]string{constants.UserRole, constants.PartnerRole, constants.AdminRole}, role) {
+		return utils.ErrInvalidRequest.WithMsg("role must be one of user, partner, admin")
+	}
+	return u.UpdateUser(userName, version, &model.User{Role: role})
+}
+func (u *UserSvc) GettAllUsers(page, limit int) ([]*model.User, int64, error) {
+	offset := (page - 1) * limit
+	return u.Repos.Users.List(context.Background(), offset, limit)
 }
 
 func (u *UserSvc) DelUser(userName string) error {
-	usr := dao.User
-	user, err := u.FilterUsers(userName, "", "", true)
+	users, err := u.FilterUsers(userName, "", "", true)
 	if err != nil {
 		return err
 	}
-	if user == nil {
+	if len(users) == 0 {
 		return utils.ErrUserAlreadyDeleted
 	}
-	_, err = usr.Where(usr.Username.Eq(userName)).
-		Updates(&model.User{Deleted: true})
-	if err != nil {
-		return err
-	}
-	return nil
+	return u.Repos.Users.SetDeleted(context.Background(), userName, true)
+}
+
+// Deactivate is the admin deactivation of a user, it signs the user out and
+// only Reactivate lifts it, the public activation refuses such users
+func (u *UserSvc) Deactivate(userName string) error {
+	user := &model.User{Deleted: true, DeactivatedByAdmin: true}
+	return u.Repos.Users.Patch(context.Background(), userName, 0, user, "deleted", "deactivated_by_admin", "refresh_token")
+}
+
+// Reactivate activates a user again, admin deactivations included
+func (u *UserSvc) Reactivate(userName string) error {
+	return u.Repos.Users.Patch(context.Background(), userName, 0, &model.User{}, "deleted", "deactivated_by_admin")
 }
 
 func (u *UserSvc) UpdateRefreshToken(userName, refreshToken string) error {
-	usr := dao.User
-	if _, err := usr.Where(usr.Deleted.Is(false), usr.Username.Eq(userName)).Select(usr.RefreshToken).Updates(&model.User{RefreshToken: refreshToken}); err != nil {
-		return err
-	}
-	return nil
+	return u.Repos.Users.UpdateRefreshToken(context.Background(), userName, refreshToken)
 }
 func (u *UserSvc) UpdateDelFlag(userName string, delFlag bool) error {
-	usr := dao.User
-	if _, err := usr.Where(usr.Deleted.Is(true), usr.Username.Eq(userName)).Select(usr.Deleted).Updates(&model.User{Deleted: delFlag}); err != nil {
-		return err
-	}
-	return nil
+	return u.Repos.Users.SetDeleted(context.Background(), userName, delFlag)
 }
 func (u *UserSvc) FilterUsers(userName, email, phone string, useDelFlag bool) ([]*model.User, error) {
-	q := dao.User.WithContext(context.Background())
-
-	if useDelFlag {
-		q = q.Where(dao.User.Deleted.Is(false))
-	}
-	if userName != "" {
-		q = q.Where(dao.User.Username.Eq(userName))
-	}
-	if email != "" {
-		q = q.Where(dao.User.Email.Eq(email))
-	}
-	if phone != "" {
-		q = q.Where(dao.User.Phone.Eq(phone))
-	}
-	return q.Find()
+	return u.Repos.Users.Filter(context.Background(), userName, email, phone, useDelFlag)
 }
 func (u *UserSvc) GetUserWithEmailOrPhone(email, phone string, useDelFlag bool) (*model.User, error) {
-	q := dao.User.WithContext(context.Background())
-	if useDelFlag {
-		q = q.Where(dao.User.Deleted.Is(false))
-	}
-	q = q.Where(dao.User.Email.Eq(email)).
-		Or(dao.User.Phone.Eq(phone))
-	return q.First()
+	user, err := u.Repos.Users.GetByEmailOrPhone(context.Background(), email, phone, useDelFlag)
+	return user, dao.NotFoundAs(err, utils.ErrUserNotFound)
 }
 func (u *UserSvc) GetUserWithEmailAndPhone(email, phone string, useDelFlag bool) (*model.User, error) {
-	q := dao.User.WithContext(context.Background())
-	if useDelFlag {
-		q = q.Where(dao.User.Deleted.Is(false))
-	}
-	q = q.Where(dao.User.Email.Eq(email), dao.User.Phone.Eq(phone))
-	return q.First()
+	user, err := u.Repos.Users.GetByEmailAndPhone(context.Background(), email, phone, useDelFlag)
+	return user, dao.NotFoundAs(err, utils.ErrUserNotFound)
 }
 
 func (u *UserSvc) GetUserByUserName(userName string, useDelFlag bool) (*model.User, error) {
-	q := dao.User.WithContext(context.Background())
-	if useDelFlag {
-		q = q.Where(dao.User.Deleted.Is(false))
-	}
-	q = q.Where(dao.User.Username.Eq(userName))
-	return q.First()
+	user, err := u.Repos.Users.GetByUserName(context.Background(), userName, useDelFlag)
+	return user, dao.NotFoundAs(err, utils.ErrUserNotFound)
 }
@@ -2,48 +2,202 @@ package svcs
 
 import (
 	"context"
+	"slices"
+	"time"
 
 	"booking.com/internal/config"
 	"booking.com/internal/db/postgresql/dao"
 	"booking.com/internal/db/postgresql/model"
 	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/repo"
+	"booking.com/internal/utils"
 	"booking.com/pkg/constants"
 )
 
+// partnerTransitions and buyerTransitions list the statuses each side may move a visit to
+var (
+	partnerTransitions = map[string][]string{
+		constants.Pending:     {constants.Accepted, constants.Rejected, constants.Rescheduled},
+		constants.Rescheduled: {constants.Accepted, constants.Rejected},
+		constants.Accepted:    {constants.Completed, constants.NoShow, constants.Rescheduled, constants.Cancelled},
+	}
+	buyerTransitions = map[string][]string{
+		constants.Pending:     {constants.Cancelled},
+		constants.Rescheduled: {constants.Accepted, constants.Cancelled},
+		constants.Accepted:    {constants.Cancelled},
+	}
+)
+
 type VisitsSvc struct {
 	AppCfg *config.AppConfig
+	Repos  *repo.Repos
+}
+
+func NewVisitsSvc(cfg *config.AppConfig, repos *repo.Repos) *VisitsSvc {
+	return &VisitsSvc{AppCfg: cfg, Repos: repos}
 }
 
 func (v *VisitsSvc) ScheduleVisit(visitReq *dto.ScheduleReq, propertySvc *PropertySvc) error {
-	_, err := propertySvc.GetPropertyByID(visitReq.PropertyID, true)
+	property, err := propertySvc.GetPropertyByID(visitReq.PropertyID, true)
 	if err != nil {
 		return err
 	}
-	return dao.Visit.Save(&model.Visit{
-		PropertyID:     visitReq.PropertyID,
-		BuyerUsername:  visitReq.BuyerUsername,
-		RescheduleTime: visitReq.ScheduledTime,
-		Status:         constants.Pending,
-		BuyerNote:      visitReq.BuyerNote,
+	if property.PartnerUsername == visitReq.BuyerUsername {
+		return utils.ErrVisitNotAllowed.WithMsg("partner can't schedule a visit to own property")
+	}
+	ctx := context.Background()
+	buyer, err := v.Repos.Users.GetByUserName(ctx, visitReq.BuyerUsername, true)
+	if err != nil {
+		return dao.NotFoundAs(err, utils.ErrUserNotFound)
+	}
+	if buyer.BookingRestrictedUntil.After(time.Now().UTC()) {
+		return utils.ErrBookingRestricted.WithMsg("booking new visits is restricted until " + buyer.BookingRestrictedUntil.Format(time.RFC3339))
+	}
+	visit := &model.Visit{
+		PropertyID:    visitReq.PropertyID,
+		BuyerUsername: visitReq.BuyerUsername,
+		ScheduledTime: visitReq.ScheduledTime,
+		Status:        constants.Pending,
+		BuyerNote:     visitReq.BuyerNote,
+	}
+	return v.Repos.Transaction(ctx, func(tx *repo.Repos) error {
+		if err := tx.Visits.Create(ctx, visit); err != nil {
+			return err
+		}
+		payload := events.VisitPayload{
+			ID:              visit.ID,
+			PropertyID:      visit.PropertyID,
+			PartnerUsername: property.PartnerUsername,
+			BuyerUsername:   visit.BuyerUsername,
+			Status:          visit.Status,
+			ScheduledTime:   visit.ScheduledTime,
+			ChangedBy:       visit.BuyerUsername,
+		}
+		return publish(ctx, tx, events.VisitScheduled, events.AggregateVisit, visit.ID, payload)
 	})
 }
-func (v *VisitsSvc) FilterVisits(filterReq *dto.VisitFilterReq, propertySvc *PropertySvc) {
 
-	vist := dao.Visit.WithContext(context.Background())
-	if filterReq.Status != "" {
-		vist = vist.Where(dao.Visit.Status.Eq(filterReq.Status))
+func (v *VisitsSvc) GetVisitByID(id int64) (*model.Visit, error) {
+	visit, err := v.Repos.Visits.GetByID(context.Background(), id)
+	if err != nil {
+		return nil, dao.NotFoundAs(err, utils.ErrVisitNotFound)
 	}
-	if filterReq.PropertyID != 0 {
-		vist = vist.Where(dao.Visit.ID.Eq(filterReq.PropertyID))
+	return visit, nil
+}
+
+// UpdateVisit moves a visit to a new status, the buyer and the partner owning
+// the property are allowed different transitions. A non zero version must be
+// the one the client read
+func (v *VisitsSvc) UpdateVisit(userName string, version int32, updateReq *dto.UpdateVisitReq, propertySvc *PropertySvc) error {
+	visit, err := v.GetVisitByID(updateReq.ID)
+	if err != nil {
+		return err
+	}
+	property, err := propertySvc.GetPropertyByID(visit.PropertyID, false)
+	if err != nil {
+		return err
 	}
-	if filterReq.BuyersUserName != "" {
-		vist = vist.Where(dao.Visit.BuyerUsername.Eq(filterReq.BuyersUserName))
+	var transitions map[string][]string
+	switch userName {
+	case property.PartnerUsername:
+		transitions = partnerTransitions
+	case visit.BuyerUsername:
+		transitions = buyerTransitions
+	default:
+		return utils.ErrVisitNotAllowed
 	}
-	if filterReq.PartnerUserName != "" {
-		propertySvc.GetPropertyByID(filterReq.PropertyID,true)
-		vist = vist.Where(dao.Visit.BuyerUsername.Eq(filterReq.BuyersUserName))
+	if version != 0 && version != visit.Version {
+		return utils.ErrVersionMismatch
 	}
-	// vist.Join()
-	// Where(vist.Deleted.Is(false)).
+	update := &model.Visit{PartnerNote: updateReq.PartnerNote, BuyerNote: updateReq.BuyerNote}
+	if updateReq.Status != "" && updateReq.Status != visit.Status {
+		if !slices.Contains(transitions[visit.Status], updateReq.Status) {
+			return utils.ErrInvalidVisitTransition.WithMsg("visit can't move from " + visit.Status + " to " + updateReq.Status)
+		}
+		if updateReq.Status == constants.NoShow && time.Now().Before(visit.ScheduledTime) {
+			return utils.ErrInvalidVisitTransition.WithMsg("visit can't be marked no_show before its scheduled time")
+		}
+		update.Status = updateReq.Status
+		// every status change updates the calendar invites of the visit
+		update.Sequence = visit.Sequence + 1
+	}
+	if update.Status == constants.Rescheduled {
+		if updateReq.RescheduleTime == nil {
+			return utils.ErrInvalidRequest.WithMsg("reschedule_time is required to reschedule a visit")
+		}
+		update.RescheduleTime = *updateReq.RescheduleTime
+	}
+	if visit.Status == constants.Rescheduled && update.Status == constants.Accepted {
+		update.ScheduledTime = visit.RescheduleTime
+	}
+	ctx := context.Background()
+	return v.Repos.Transaction(ctx, func(tx *repo.Repos) error {
+		if err := tx.Visits.Update(ctx, visit.ID, version, update); err != nil {
+			return dao.NotFoundAs(err, utils.ErrVersionMismatch)
+		}
+		if update.Status == "" {
+			return nil
+		}
+		if update.Status == constants.NoShow {
+			if err := v.recordNoShow(ctx, tx, visit.BuyerUsername); err != nil {
+				return err
+			}
+		}
+		payload := events.VisitPayload{
+			ID:              visit.ID,
+			PropertyID:      visit.PropertyID,
+			PartnerUsername: property.PartnerUsername,
+			BuyerUsername:   visit.BuyerUsername,
+			Status:          update.Status,
+			PreviousStatus:  visit.Status,
+			ScheduledTime:   visit.ScheduledTime,
+			ChangedBy:       userName,
+			Sequence:        update.Sequence,
+		}
+		if !update.ScheduledTime.IsZero() {
+			payload.ScheduledTime = update.ScheduledTime
+		}
+		if !update.RescheduleTime.IsZero() {
+			payload.RescheduleTime = &update.RescheduleTime
+		}
+		return publish(ctx, tx, events.VisitStatusChanged(update.Status), events.AggregateVisit, visit.ID, payload)
+	})
+}
+
+// recordNoShow counts a no-show of the buyer, reaching the limit restricts
+// booking for the configured time and every further no-show restarts it
+func (v *VisitsSvc) recordNoShow(ctx context.Context, tx *repo.Repos, buyer string) error {
+	count, err := tx.Users.AddNoShow(ctx, buyer)
+	if err != nil {
+		return err
+	}
+	limit := v.AppCfg.Visits.NoShowLimit
+	if limit <= 0 || count < limit {
+		return nil
+	}
+	until := time.Now().UTC().Add(v.AppCfg.Visits.NoShowRestriction)
+	return tx.Users.Update(ctx, buyer, 0, &model.User{BookingRestrictedUntil: until})
+}
+
+// FilterVisits lists visits, non admin users only see visits they booked or
+// visits to properties they own
+func (v *VisitsSvc) FilterVisits(userName string, filterReq *dto.VisitFilterReq, isAdmin bool) ([]*model.Visit, int64, error) {
+	participant := userName
+	if isAdmin {
+		participant = ""
+	}
+	return v.Repos.Visits.Filter(context.Background(), participant, *filterReq)
+}
 
+// DeleteVisit soft deletes a visit, only the buyer who booked it can delete it
+func (v *VisitsSvc) DeleteVisit(userName string, id int64, isAdmin bool) error {
+	visit, err := v.GetVisitByID(id)
+	if err != nil {
+		return err
+	}
+	if !isAdmin && visit.BuyerUsername != userName {
+		return utils.ErrVisitNotAllowed
+	}
+	return v.Repos.Visits.SetDeleted(context.Background(), id)
 }
@@ -0,0 +1,210 @@
+package svcs
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/utils"
+	"booking.com/pkg/constants"
+)
+
+func TestUpdateVisitTransitions(t *testing.T) {
+	later := time.Now().Add(48 * time.Hour)
+	tests := []struct {
+		name       string
+		from       string
+		byPartner  bool
+		byOther    bool
+		status     string
+		reschedule *time.Time
+		wantErr    error
+	}{
+		{name: "partner accepts", from: constants.Pending, byPartner: true, status: constants.Accepted},
+		{name: "partner rejects", from: constants.Pending, byPartner: true, status: constants.Rejected},
+		{name: "partner reschedules", from: constants.Pending, byPartner: true, status: constants.Rescheduled, reschedule: &later},
+		{name: "reschedule needs a time", from: constants.Pending, byPartner: true, status: constants.Rescheduled, wantErr: utils.ErrInvalidRequest},
+		{name: "partner completes", from: constants.Accepted, byPartner: true, status: constants.Completed},
+		{name: "partner can't complete pending", from: constants.Pending, byPartner: true, status: constants.Completed, wantErr: utils.ErrInvalidVisitTransition},
+		{name: "no-show before the visit", from: constants.Accepted, byPartner: true, status: constants.NoShow, wantErr: utils.ErrInvalidVisitTransition},
+		{name: "buyer cancels", from: constants.Pending, status: constants.Cancelled},
+		{name: "buyer accepts reschedule", from: constants.Rescheduled, status: constants.Accepted},
+		{name: "buyer can't accept pending", from: constants.Pending, status: constants.Accepted, wantErr: utils.ErrInvalidVisitTransition},
+		{name: "buyer can't complete", from: constants.Accepted, status: constants.Completed, wantErr: utils.ErrInvalidVisitTransition},
+		{name: "nothing leaves completed", from: constants.Completed, byPartner: true, status: constants.Cancelled, wantErr: utils.ErrInvalidVisitTransition},
+		{name: "stranger", from: constants.Pending, byOther: true, status: constants.Cancelled, wantErr: utils.ErrVisitNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSvcs()
+			partner := s.register(t, "Jane", "jane@example.com", "+910000000001")
+			buyer := s.register(t, "John", "john@example.com", "+910000000002")
+			other := s.register(t, "Max", "max@example.com", "+910000000003")
+			if err := s.properties.AddProperties(partner, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300}); err != nil {
+				t.Fatal(err)
+			}
+			properties, err := s.properties.GetPropertiesByUserName(partner, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scheduled := time.Now().Add(24 * time.Hour)
+			if err := s.visits.ScheduleVisit(&dto.ScheduleReq{PropertyID: properties[0].ID, BuyerUsername: buyer, ScheduledTime: scheduled}, s.properties); err != nil {
+				t.Fatal(err)
+			}
+			visits, _, err := s.visits.FilterVisits(buyer, &dto.VisitFilterReq{}, false)
+			if err != nil || len(visits) != 1 {
+				t.Fatalf("expected one visit, got %d: %v", len(visits), err)
+			}
+			visit := visits[0]
+			if tt.from == constants.Rescheduled {
+				if err := s.visits.UpdateVisit(partner, 0, &dto.UpdateVisitReq{ID: visit.ID, Status: constants.Rescheduled, RescheduleTime: &later}, s.properties); err != nil {
+					t.Fatal(err)
+				}
+			} else if tt.from != constants.Pending {
+				if err := s.visits.Repos.Visits.Update(t.Context(), visit.ID, 0, &model.Visit{Status: tt.from}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			actor := buyer
+			if tt.byPartner {
+				actor = partner
+			} else if tt.byOther {
+				actor = other
+			}
+			err = s.visits.UpdateVisit(actor, 0, &dto.UpdateVisitReq{ID: visit.ID, Status: tt.status, RescheduleTime: tt.reschedule}, s.properties)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			got, err := s.visits.GetVisitByID(visit.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr == nil && got.Status != tt.status {
+				t.Errorf("got status %s, want %s", got.Status, tt.status)
+			}
+			published := s.outboxEvents()
+			last := published[len(published)-1].EventType
+			if tt.wantErr == nil && last != events.VisitStatusChanged(tt.status) {
+				t.Errorf("got last event %s, want %s", last, events.VisitStatusChanged(tt.status))
+			}
+			if tt.wantErr != nil && tt.from == constants.Pending && last != events.VisitScheduled {
+				t.Errorf("rejected change published %s", last)
+			}
+			if tt.from == constants.Rescheduled && tt.status == constants.Accepted && !got.ScheduledTime.Equal(later) {
+				t.Errorf("accepted reschedule should move scheduled_time to %v, got %v", later, got.ScheduledTime)
+			}
+		})
+	}
+}
+
+func TestScheduleOwnProperty(t *testing.T) {
+	s := newTestSvcs()
+	partner := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	if err := s.properties.AddProperties(partner, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300}); err != nil {
+		t.Fatal(err)
+	}
+	properties, err := s.properties.GetPropertiesByUserName(partner, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.visits.ScheduleVisit(&dto.ScheduleReq{PropertyID: properties[0].ID, BuyerUsername: partner, ScheduledTime: time.Now().Add(time.Hour)}, s.properties)
+	if !errors.Is(err, utils.ErrVisitNotAllowed) {
+		t.Fatalf("got %v, want %v", err, utils.ErrVisitNotAllowed)
+	}
+}
+
+func TestNoShowRestrictsBooking(t *testing.T) {
+	s := newTestSvcs()
+	s.visits.AppCfg.Visits = config.Visits{NoShowLimit: 2, NoShowRestriction: 24 * time.Hour}
+	partner := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	buyer := s.register(t, "John", "john@example.com", "+910000000002")
+	if err := s.properties.AddProperties(partner, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300}); err != nil {
+		t.Fatal(err)
+	}
+	properties, _ := s.properties.GetPropertiesByUserName(partner, true)
+	schedule := func() error {
+		return s.visits.ScheduleVisit(&dto.ScheduleReq{PropertyID: properties[0].ID, BuyerUsername: buyer, ScheduledTime: time.Now().Add(time.Hour)}, s.properties)
+	}
+	for i := range 2 {
+		if err := schedule(); err != nil {
+			t.Fatalf("booking %d: %v", i, err)
+		}
+		visits, _, _ := s.visits.FilterVisits(buyer, &dto.VisitFilterReq{Status: constants.Pending}, false)
+		// the visit took place an hour ago
+		past := &model.Visit{Status: constants.Accepted, ScheduledTime: time.Now().Add(-time.Hour)}
+		if err := s.visits.Repos.Visits.Update(t.Context(), visits[0].ID, 0, past); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.visits.UpdateVisit(partner, 0, &dto.UpdateVisitReq{ID: visits[0].ID, Status: constants.NoShow}, s.properties); err != nil {
+			t.Fatalf("no-show %d: %v", i, err)
+		}
+	}
+	user, err := s.users.GetUserByUserName(buyer, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.NoShowCount != 2 || !user.BookingRestrictedUntil.After(time.Now().Add(23*time.Hour)) {
+		t.Errorf("got %d no-shows restricted until %v, want 2 restricted for a day", user.NoShowCount, user.BookingRestrictedUntil)
+	}
+	if err := schedule(); !errors.Is(err, utils.ErrBookingRestricted) {
+		t.Fatalf("got %v, want %v", err, utils.ErrBookingRestricted)
+	}
+}
+
+func TestVisitReminders(t *testing.T) {
+	s := newTestSvcs()
+	s.visits.AppCfg.Visits = config.Visits{ReminderOffsets: []time.Duration{24 * time.Hour, time.Hour}, FollowUpDelay: 2 * time.Hour}
+	partner := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	buyer := s.register(t, "John", "john@example.com", "+910000000002")
+	if err := s.properties.AddProperties(partner, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300}); err != nil {
+		t.Fatal(err)
+	}
+	properties, _ := s.properties.GetPropertiesByUserName(partner, true)
+	scheduled := time.Now().Add(3 * time.Hour)
+	if err := s.visits.ScheduleVisit(&dto.ScheduleReq{PropertyID: properties[0].ID, BuyerUsername: buyer, ScheduledTime: scheduled}, s.properties); err != nil {
+		t.Fatal(err)
+	}
+	visits, _, _ := s.visits.FilterVisits(buyer, &dto.VisitFilterReq{}, false)
+	id := visits[0].ID
+	if err := s.visits.UpdateVisit(partner, 0, &dto.UpdateVisitReq{ID: id, Status: constants.Accepted}, s.properties); err != nil {
+		t.Fatal(err)
+	}
+	payload := events.VisitPayload{ID: id, Status: constants.Accepted, ScheduledTime: scheduled, Sequence: 1}
+	// a redelivered acceptance schedules nothing new
+	for range 2 {
+		if err := s.visits.ScheduleReminders(t.Context(), payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	queued, _, err := s.repos.Jobs.Filter(t.Context(), dto.JobFilterReq{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, job := range queued {
+		kinds = append(kinds, job.Kind)
+	}
+	// the 24h reminder is already past
+	slices.Sort(kinds)
+	if want := []string{KindVisitFollowUp, KindVisitReminder}; !slices.Equal(kinds, want) {
+		t.Fatalf("got jobs %v, want %v", kinds, want)
+	}
+
+	reminder := VisitReminder{VisitID: id, Sequence: 1, Before: time.Hour}
+	if visit, err := s.visits.ReminderVisit(t.Context(), reminder); err != nil || visit == nil {
+		t.Fatalf("ReminderVisit() = %v, %v, want the accepted visit", visit, err)
+	}
+	later := scheduled.Add(24 * time.Hour)
+	if err := s.visits.UpdateVisit(partner, 0, &dto.UpdateVisitReq{ID: id, Status: constants.Rescheduled, RescheduleTime: &later}, s.properties); err != nil {
+		t.Fatal(err)
+	}
+	if visit, err := s.visits.ReminderVisit(t.Context(), reminder); err != nil || visit != nil {
+		t.Fatalf("ReminderVisit() = %v, %v, want nothing due after a reschedule", visit, err)
+	}
+}
@@ -0,0 +1,268 @@
+package svcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	httpclient "booking.com/internal/client/http-client"
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/jobs"
+	"booking.com/internal/repo"
+	"booking.com/internal/utils"
+	"booking.com/internal/webhooks"
+)
+
+type WebhookSvc struct {
+	AppCfg *config.AppConfig
+	Repos  *repo.Repos
+	// Client posts the deliveries, it gives up after the configured timeout and
+	// only reaches public addresses
+	Client *httpclient.CustomHttpClient
+}
+
+func NewWebhookSvc(cfg *config.AppConfig, repos *repo.Repos) *WebhookSvc {
+	client := httpclient.NewOutboundHttpClient(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivate)
+	return &WebhookSvc{AppCfg: cfg, Repos: repos, Client: client}
+}
+
+// CreateWebhook subscribes a partner endpoint, the returned webhook carries the
+// signing secret which is never shown again
+func (w *WebhookSvc) CreateWebhook(userName string, createReq *dto.CreateWebhookReq) (*model.Webhook, error) {
+	if err := w.validateURL(createReq.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := marshalEventTypes(createReq.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook := &model.Webhook{
+		Username:   userName,
+		URL:        createReq.URL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+	}
+	if err := w.Repos.Webhooks.Create(context.Background(), webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (w *WebhookSvc) ListWebhooks(userName string) ([]*model.Webhook, error) {
+	return w.Repos.Webhooks.ListByUser(context.Background(), userName)
+}
+
+// GetWebhook returns a webhook of the user, the webhooks of other partners are not found
+func (w *WebhookSvc) GetWebhook(userName string, id int64) (*model.Webhook, error) {
+	webhook, err := w.Repos.Webhooks.GetByID(context.Background(), id)
+	if err != nil {
+		return nil, dao.NotFoundAs(err, utils.ErrWebhookNotFound)
+	}
+	if webhook.Username != userName {
+		return nil, utils.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (w *WebhookSvc) UpdateWebhook(userName string, id int64, updateReq *dto.UpdateWebhookReq) (*model.Webhook, error) {
+	webhook, err := w.GetWebhook(userName, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.validateURL(updateReq.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := marshalEventTypes(updateReq.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	webhook.URL = updateReq.URL
+	webhook.EventTypes = eventTypes
+	webhook.Active = *updateReq.Active
+	if err := w.Repos.Webhooks.Update(context.Background(), webhook); err != nil {
+		return nil, dao.NotFoundAs(err, utils.ErrWebhookNotFound)
+	}
+	return w.GetWebhook(userName, id)
+}
+
+func (w *WebhookSvc) DeleteWebhook(userName string, id int64) error {
+	if _, err := w.GetWebhook(userName, id); err != nil {
+		return err
+	}
+	err := w.Repos.Webhooks.Delete(context.Background(), id)
+	return dao.NotFoundAs(err, utils.ErrWebhookNotFound)
+}
+
+func (w *WebhookSvc) Deliveries(userName string, id int64, pageReq dto.PageReq) ([]*model.WebhookDelivery, int64, error) {
+	if _, err := w.GetWebhook(userName, id); err != nil {
+		return nil, 0, err
+	}
+	return w.Repos.Webhooks.Deliveries(context.Background(), id, pageReq)
+}
+
+// SendTest delivers a test event right away, the delivery is logged and
+// returned whether the endpoint accepted it or not
+func (w *WebhookSvc) SendTest(userName string, id int64) (*model.WebhookDelivery, error) {
+	webhook, err := w.GetWebhook(userName, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(map[string]any{"webhook_id": webhook.ID, "message": "test event from BookMyLab"})
+	if err != nil {
+		return nil, err
+	}
+	event := webhooks.Payload{Type: webhooks.EventTest, CreatedAt: time.Now().UTC(), Data: data}
+	return w.deliver(context.Background(), webhook, event, 1)
+}
+
+// Dispatch queues a delivery of event to every active webhook of the partner
+// it concerns, events without a partner are skipped
+func (w *WebhookSvc) Dispatch(ctx context.Context, event events.Event) error {
+	var target struct {
+		PartnerUsername string `json:"partner_username"`
+	}
+	if !slices.Contains(webhooks.EventTypes, event.Type) {
+		return nil
+	}
+	if err := event.Decode(&target); err != nil {
+		return err
+	}
+	if target.PartnerUsername == "" {
+		return nil
+	}
+	subscribed, err := w.Repos.Webhooks.ListByUser(ctx, target.PartnerUsername)
+	if err != nil {
+		return err
+	}
+	for _, webhook := range subscribed {
+		if !webhook.Active || !subscribes(webhook, event.Type) {
+			continue
+		}
+		delivery := webhooks.Delivery{
+			WebhookID: webhook.ID,
+			Event:     webhooks.Payload{ID: event.ID, Type: event.Type, CreatedAt: event.OccurredAt, Data: event.Payload},
+		}
+		err := jobs.Enqueue(ctx, w.Repos.Jobs, webhooks.KindDeliver, delivery,
+			jobs.UniqueKey(fmt.Sprintf("webhook:%d:%d", webhook.ID, event.ID)),
+			jobs.MaxAttempts(w.AppCfg.Webhooks.MaxAttempts))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliver runs a delivery job, a failed attempt fails the job so the queue
+// retries it with backoff. Deliveries to deleted or disabled webhooks are dropped
+func (w *WebhookSvc) Deliver(ctx context.Context, job jobs.Job) error {
+	var delivery webhooks.Delivery
+	if err := job.Decode(&delivery); err != nil {
+		return fmt.Errorf("decode %s payload: %w", job.Kind, err)
+	}
+	webhook, err := w.Repos.Webhooks.GetByID(ctx, delivery.WebhookID)
+	if errors.Is(err, dao.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !webhook.Active {
+		return nil
+	}
+	logged, err := w.deliver(ctx, webhook, delivery.Event, job.Attempt)
+	if err != nil {
+		return err
+	}
+	if !logged.Success {
+		return errors.New(logged.Error)
+	}
+	return nil
+}
+
+// deliver posts the signed event and logs the attempt, the error is only about
+// logging. Only the status is kept, the response body never reaches the partner
+func (w *WebhookSvc) deliver(ctx context.Context, webhook *model.Webhook, event webhooks.Payload, attempt int32) (*model.WebhookDelivery, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	rsp, err := w.Client.PostRaw(ctx, webhook.URL, webhooks.Headers(webhook.Secret, event, start, body), body)
+	logged := &model.WebhookDelivery{
+		WebhookID:  webhook.ID,
+		EventID:    event.ID,
+		EventType:  event.Type,
+		Attempt:    attempt,
+		DurationMs: int32(time.Since(start).Milliseconds()),
+	}
+	switch {
+	case errors.Is(err, httpclient.ErrNonPublicAddress):
+		logged.Error = "webhook url resolves to a non-public address"
+	case err != nil:
+		logged.Error = err.Error()
+	case rsp.StatusCode < 200 || rsp.StatusCode > 299:
+		logged.StatusCode = int32(rsp.StatusCode)
+		logged.Error = fmt.Sprintf("unexpected status %d", rsp.StatusCode)
+	default:
+		logged.StatusCode = int32(rsp.StatusCode)
+		logged.Success = true
+	}
+	if err := w.Repos.Webhooks.AddDelivery(context.WithoutCancel(ctx), logged); err != nil {
+		return nil, err
+	}
+	return logged, nil
+}
+
+// validateURL accepts https urls of public hosts, deliveries check the
+// address again when they connect
+func (w *WebhookSvc) validateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return utils.ErrInvalidRequest.WithMsg("invalid webhook url")
+	}
+	if parsed.Scheme != "https" && !(w.AppCfg.Webhooks.AllowInsecure && parsed.Scheme == "http") {
+		return utils.ErrInvalidRequest.WithMsg("webhook url must use https")
+	}
+	if w.AppCfg.Webhooks.AllowPrivate {
+		return nil
+	}
+	err = httpclient.CheckPublicHost(context.Background(), parsed.Hostname())
+	if errors.Is(err, httpclient.ErrNonPublicAddress) {
+		return utils.ErrInvalidRequest.WithMsg("webhook url must resolve to a public address")
+	}
+	if err != nil {
+		return utils.ErrInvalidRequest.WithMsg("webhook url host can't be resolved")
+	}
+	return nil
+}
+
+func marshalEventTypes(eventTypes []string) (string, error) {
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	slices.Sort(eventTypes)
+	data, err := json.Marshal(slices.Compact(eventTypes))
+	return string(data), err
+}
+
+// subscribes reports whether the webhook wants eventType, no filter means every event
+func subscribes(webhook *model.Webhook, eventType string) bool {
+	var eventTypes []string
+	if err := json.Unmarshal([]byte(webhook.EventTypes), &eventTypes); err != nil {
+		return false
+	}
+	return len(eventTypes) == 0 || slices.Contains(eventTypes, eventType)
+}
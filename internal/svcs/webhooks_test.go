@@ -0,0 +1,159 @@
+package svcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	httpclient "booking.com/internal/client/http-client"
+	"booking.com/internal/config"
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/jobs"
+	"booking.com/internal/utils"
+	"booking.com/internal/webhooks"
+)
+
+func TestWebhookDeliveryIsSignedRetriedAndLogged(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	john := s.register(t, "John", "john@example.com", "+910000000002")
+
+	var secret string
+	var calls atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhooks.Verify(secret, r.Header, body, time.Minute, time.Now()); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+		// the first attempt fails so the job has to retry
+		if calls.Add(1) == 1 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	// the test server listens on loopback
+	hooks := NewWebhookSvc(&config.AppConfig{Webhooks: config.Webhooks{AllowPrivate: true}}, s.repos)
+	hooks.Client = &httpclient.CustomHttpClient{Client: srv.Client()}
+	if _, err := hooks.CreateWebhook(jane, &dto.CreateWebhookReq{URL: "http://example.com"}); err == nil {
+		t.Fatal("expected an error for a plain http url")
+	}
+	webhook, err := hooks.CreateWebhook(jane, &dto.CreateWebhookReq{URL: srv.URL, EventTypes: []string{events.PropertyListed}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret = webhook.Secret
+	if _, err := hooks.CreateWebhook(john, &dto.CreateWebhookReq{URL: srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.properties.AddProperties(jane, dto.AddPropertyReq{Title: "Hill house", PropertyType: "House", Price: 300}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, row := range s.outboxEvents() {
+		event := events.Event{ID: row.ID, Type: row.EventType, Payload: json.RawMessage(row.Payload), OccurredAt: row.CreatedAt}
+		// a redelivered event must not queue a second delivery
+		for range 2 {
+			if err := hooks.Dispatch(ctx, event); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	runner := jobs.NewRunner(s.repos.Jobs, config.Jobs{})
+	runner.Register(webhooks.KindDeliver, hooks.Deliver)
+	for range 3 {
+		if _, err := runner.RunOnce(ctx, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("endpoint called %d times, want a failed and a successful attempt", calls.Load())
+	}
+	deliveries, total, err := hooks.Deliveries(jane, webhook.ID, dto.PageReq{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || !deliveries[0].Success || deliveries[0].Attempt != 2 ||
+		deliveries[1].Success || deliveries[1].StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("deliveries = %+v, want a 503 followed by a success", deliveries)
+	}
+	if _, _, err := hooks.Deliveries(john, webhook.ID, dto.PageReq{}); err == nil {
+		t.Fatal("expected another partner's webhook not to be found")
+	}
+
+	test, err := hooks.SendTest(jane, webhook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !test.Success || test.EventType != webhooks.EventTest {
+		t.Errorf("test delivery = %+v", test)
+	}
+}
+
+func TestWebhookURLMustBePublic(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	hooks := NewWebhookSvc(&config.AppConfig{}, s.repos)
+	for _, rawURL := range []string{
+		"https://127.0.0.1/hook",
+		"https://localhost:8443/hook",
+		"https://10.0.0.5/hook",
+		"https://192.168.1.10/hook",
+		"https://100.64.0.1/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/hook",
+		"https://[fe80::1]/hook",
+		"https://0.0.0.0/hook",
+	} {
+		_, err := hooks.CreateWebhook(jane, &dto.CreateWebhookReq{URL: rawURL})
+		if !errors.Is(err, utils.ErrInvalidRequest) {
+			t.Errorf("CreateWebhook(%s) error = %v, want an invalid request", rawURL, err)
+		}
+	}
+}
+
+func TestWebhookDeliveryStaysOnPublicAddresses(t *testing.T) {
+	s := newTestSvcs()
+	jane := s.register(t, "Jane", "jane@example.com", "+910000000001")
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	// the url was accepted but now resolves to loopback, as after a dns rebinding
+	cfg := &config.AppConfig{Webhooks: config.Webhooks{AllowInsecure: true, AllowPrivate: true, Timeout: time.Second}}
+	webhook, err := NewWebhookSvc(cfg, s.repos).CreateWebhook(jane, &dto.CreateWebhookReq{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks := NewWebhookSvc(&config.AppConfig{Webhooks: config.Webhooks{Timeout: time.Second}}, s.repos)
+	test, err := hooks.SendTest(jane, webhook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.Success || calls.Load() != 0 || strings.Contains(test.Error, "127.0.0.1") {
+		t.Errorf("test delivery = %+v after %d calls, want it refused before connecting", test, calls.Load())
+	}
+
+	// redirects are not followed and only the status is logged
+	hooks = NewWebhookSvc(cfg, s.repos)
+	test, err = hooks.SendTest(jane, webhook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.Success || calls.Load() != 1 || test.StatusCode != http.StatusFound || test.Error != "unexpected status 302" {
+		t.Errorf("test delivery = %+v, want the redirect logged by status", test)
+	}
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"booking.com/internal/validation"
+	customerrors "booking.com/pkg/custom_errors"
+	"github.com/go-playground/validator/v10"
+)
+
+// BindError converts request binding errors into a validation AppError with per field details
+func BindError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var sliceErrs validation.SliceErrors
+	if errors.As(err, &sliceErrs) {
+		var details []customerrors.FieldError
+		for _, sliceErr := range sliceErrs {
+			for _, detail := range fieldErrors(sliceErr.Err) {
+				detail.Field = fmt.Sprintf("[%d].%s", sliceErr.Index, detail.Field)
+				details = append(details, detail)
+			}
+		}
+		return customerrors.Validation(customerrors.CodeValidation, "request validation failed", details...)
+	}
+	var patchErrs validation.FieldErrors
+	if errors.As(err, &patchErrs) {
+		details := make([]customerrors.FieldError, 0, len(patchErrs))
+		for _, patchErr := range patchErrs {
+			details = append(details, customerrors.FieldError{Field: patchErr.Field, Message: patchErr.Message})
+		}
+		return customerrors.Validation(customerrors.CodeValidation, "request validation failed", details...)
+	}
+	if details := fieldErrors(err); details != nil {
+		return customerrors.Validation(customerrors.CodeValidation, "request validation failed", details...)
+	}
+	if errors.Is(err, io.EOF) {
+		return customerrors.Validation(customerrors.CodeInvalidRequest, "request body is empty")
+	}
+	return customerrors.Validation(customerrors.CodeInvalidRequest, "malformed request").Wrap(err)
+}
+
+func fieldErrors(err error) []customerrors.FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make([]customerrors.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			details = append(details, customerrors.FieldError{
+				Field:   fe.Field(),
+				Message: validation.Message(fe),
+			})
+		}
+		return details
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []customerrors.FieldError{{Field: typeErr.Field, Message: "must be of type " + typeErr.Type.String()}}
+	}
+	return nil
+}
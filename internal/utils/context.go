@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// AbortWithError records err on the context and stops the chain,
+// the error middleware renders it with the matching status code
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// CurrentUserName returns the username set by the auth middleware
+func CurrentUserName(c *gin.Context) (string, error) {
+	userName := c.GetString(constants.CurrentUserName)
+	if userName == "" {
+		return "", ErrInvalidRequest
+	}
+	return userName, nil
+}
+
+// IsAdmin reports whether the current user has the admin role
+func IsAdmin(c *gin.Context) bool {
+	return c.GetString(constants.Role) == constants.AdminRole
+}
+
+// IsPartner reports whether the current user has the partner role
+func IsPartner(c *gin.Context) bool {
+	return c.GetString(constants.Role) == constants.PartnerRole
+}
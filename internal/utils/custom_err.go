@@ -1,15 +1,52 @@
 package utils
 
-import "errors"
+import customerrors "booking.com/pkg/custom_errors"
 
 var (
-	ErrUserAlreadyLoggedOut               = errors.New("user already logged Out")
-	ErrUserNotFound                       = errors.New("user not found")
-	ErrUserAlreadyDeleted                 = errors.New("user already deleted")
-	ErrInvalidUserOrPass                  = errors.New("invalid username or password")
-	ErrUserAlreadyExistsWithEmail         = errors.New("user already exists with email id")
-	ErrUserAlreadyExistsWithPhone         = errors.New("user already exists with phone number")
-	ErrUserAlreadyExistsWithEmailAndPhone = errors.New("user already exists with email id and phone number")
-	ErrUserAlreadyExistsWithEmailOrPhone  = errors.New("user already exists with email id or phone number")
-	ErrUserAlreadyActivated               = errors.New("user already activated")
+	ErrUserAlreadyLoggedOut               = customerrors.Conflict("user_already_logged_out", "user already logged Out")
+	ErrUserNotFound                       = customerrors.NotFound("user_not_found", "user not found")
+	ErrUserAlreadyDeleted                 = customerrors.Conflict("user_already_deleted", "user already deleted")
+	ErrInvalidUserOrPass                  = customerrors.Unauthorized("invalid_credentials", "invalid username or password")
+	ErrUserAlreadyExistsWithEmail         = customerrors.Conflict("user_email_exists", "user already exists with email id")
+	ErrUserAlreadyExistsWithPhone         = customerrors.Conflict("user_phone_exists", "user already exists with phone number")
+	ErrUserAlreadyExistsWithEmailAndPhone = customerrors.Conflict("user_email_phone_exists", "user already exists with email id and phone number")
+	ErrUserAlreadyExistsWithEmailOrPhone  = customerrors.Conflict("user_email_or_phone_exists", "user already exists with email id or phone number")
+	ErrUserAlreadyActivated               = customerrors.Conflict("user_already_activated", "user already activated")
+	ErrUserDeactivatedByAdmin             = customerrors.Forbidden("user_deactivated_by_admin", "user was deactivated by an admin, contact support")
+
+	ErrInvalidToken        = customerrors.Unauthorized("invalid_token", "invalid token")
+	ErrMissingToken        = customerrors.Unauthorized("missing_token", "bearer token not provided")
+	ErrSessionExpired      = customerrors.Unauthorized("session_expired", "session expired")
+	ErrRefreshTokenRevoked = customerrors.Unauthorized("refresh_token_revoked", "refresh_token revoked")
+	ErrCsrfTokenInvalid    = customerrors.Forbidden("csrf_token_invalid", "missing or invalid csrf token")
+	ErrAdminOnly           = customerrors.Forbidden("admin_only", "user don't have access to perform this action")
+	ErrInvalidRequest      = customerrors.Validation(customerrors.CodeInvalidRequest, "invalid request")
+	ErrRateLimited         = customerrors.TooManyRequests("rate_limited", "too many requests, retry later")
+
+	ErrIdempotencyKeyInUse  = customerrors.Conflict("idempotency_key_in_use", "a request with this idempotency key is still in progress")
+	ErrIdempotencyKeyReused = customerrors.Unprocessable("idempotency_key_reused", "idempotency key was already used with a different request")
+
+	ErrVersionMismatch = customerrors.PreconditionFailed("version_mismatch", "the resource changed since it was read, fetch it again")
+	ErrIfMatchRequired = customerrors.PreconditionRequired("if_match_required", "If-Match header with the ETag of the resource is required")
+
+	ErrPropertyNotFound = customerrors.NotFound("property_not_found", "property not found")
+	ErrNotPropertyOwner = customerrors.Forbidden("not_property_owner", "property belongs to another partner")
+
+	ErrVisitNotFound          = customerrors.NotFound("visit_not_found", "visit not found")
+	ErrInvalidVisitTransition = customerrors.Conflict("invalid_visit_transition", "visit status change not allowed")
+	ErrVisitNotAllowed        = customerrors.Forbidden("visit_not_allowed", "user don't have access to this visit")
+	ErrBookingRestricted      = customerrors.Forbidden("booking_restricted", "booking new visits is restricted after repeated no-shows")
+
+	ErrReviewNotAllowed = customerrors.Forbidden("review_not_allowed", "only buyers with a completed visit can review the property")
+	ErrAlreadyReviewed  = customerrors.Conflict("already_reviewed", "property was already reviewed by the user")
+
+	ErrNotificationNotFound = customerrors.NotFound("notification_not_found", "notification not found")
+
+	ErrJobNotFound          = customerrors.NotFound("job_not_found", "job not found")
+	ErrInvalidJobTransition = customerrors.Conflict("invalid_job_transition", "job status change not allowed")
+
+	ErrWebhookNotFound = customerrors.NotFound("webhook_not_found", "webhook not found")
+	ErrPartnerOnly     = customerrors.Forbidden("partner_only", "only partners can manage webhooks")
+
+	ErrCalendarFeedNotFound = customerrors.NotFound("calendar_feed_not_found", "calendar feed not found")
 )
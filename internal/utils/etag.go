@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// ETag formats the version of a resource as a strong entity tag
+func ETag(version int32) string {
+	return strconv.Quote(strconv.Itoa(int(version)))
+}
+
+// SetETag tags the response with the version of the resource it returns
+func SetETag(c *gin.Context, version int32) {
+	c.Header(constants.ETag, ETag(version))
+}
+
+// IfMatch returns the version the client read from the required If-Match
+// header, 0 for * which matches any version. A weak or malformed tag never
+// matches, If-Match compares strongly
+func IfMatch(c *gin.Context) (int32, error) {
+	header := strings.TrimSpace(c.GetHeader(constants.IfMatch))
+	switch header {
+	case "":
+		return 0, ErrIfMatchRequired
+	case "*":
+		return 0, nil
+	}
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, ErrVersionMismatch
+	}
+	version, err := strconv.ParseInt(tag, 10, 32)
+	if err != nil || version <= 0 {
+		return 0, ErrVersionMismatch
+	}
+	return int32(version), nil
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"net/http"
+
+	"booking.com/internal/dto"
+	"booking.com/pkg/constants"
+	customerrors "booking.com/pkg/custom_errors"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	problemTypeBase  = "https://bookmylab.com/problems/"
+	defaultPageLimit = 20
+)
+
+// IsV2 reports whether the request was routed through the /v2 api group
+func IsV2(c *gin.Context) bool {
+	return c.GetString(constants.APIVersion) == constants.APIv2
+}
+
+// DefaultPageLimit returns the page size used when the client doesn't send one,
+// /v1 lists stay unpaginated unless asked to
+func DefaultPageLimit(c *gin.Context) int {
+	if IsV2(c) {
+		return defaultPageLimit
+	}
+	return 0
+}
+
+// Respond writes a success response in the format of the api version of the request
+func Respond(c *gin.Context, status int, msg string, data any) {
+	RespondPage(c, status, msg, data, nil)
+}
+
+// RespondPage writes a success response with pagination metadata,
+// /v1 responses keep the legacy envelope and 302 for reads
+func RespondPage(c *gin.Context, status int, msg string, data any, meta *dto.PageMeta) {
+	if !IsV2(c) {
+		if c.Request.Method == http.MethodGet && status == http.StatusOK {
+			status = http.StatusFound
+		}
+		c.JSON(status, WriteAppResponse(msg, nil, data))
+		return
+	}
+	c.JSON(status, &dto.Envelope{
+		Status:  constants.Success,
+		Message: msg,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
+// RespondError writes err in the format of the api version of the request
+func RespondError(c *gin.Context, err error) {
+	appErr := customerrors.From(err)
+	if !IsV2(c) {
+		c.JSON(appErr.StatusCode(), WriteAppResponse("", appErr, nil))
+		return
+	}
+	status := appErr.StatusCode()
+	// gin keeps an already set content type, so the problem type wins over application/json
+	c.Header(constants.ContentType, constants.ContentTypeProblem)
+	c.JSON(status, &dto.Problem{
+		Type:     problemTypeBase + appErr.Code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   appErr.Msg,
+		Instance: c.Request.URL.Path,
+		Code:     appErr.Code,
+		Errors:   appErr.Details,
+	})
+}
@@ -15,6 +15,7 @@ import (
 
 	"booking.com/internal/dto"
 	"booking.com/pkg/constants"
+	customerrors "booking.com/pkg/custom_errors"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -30,7 +31,16 @@ func WriteAppResponse(msg string, appErr error, obj any) *dto.APIResponse {
 			apiRsp.Data = string(bytesData)
 		}
 	} else {
+		apiRsp.Status = constants.Failed
 		apiRsp.Error = appErr.Error()
+		var typedErr *customerrors.AppError
+		if errors.As(appErr, &typedErr) {
+			apiRsp.Error = typedErr.Msg
+			apiRsp.Code = typedErr.Code
+			if len(typedErr.Details) > 0 {
+				apiRsp.Details = typedErr.Details
+			}
+		}
 	}
 	return apiRsp
 }
@@ -0,0 +1,319 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"booking.com/pkg/constants"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// custom validation tags usable in binding tags
+const (
+	TagEmail        = "email_addr"
+	TagPhone        = "phone_e164"
+	TagPassword     = "strong_password"
+	TagPropertyType = "property_type"
+	TagMoney        = "money"
+	TagFuture       = "future"
+)
+
+// TagPatch holds the rules of a JSON Merge Patch member, the binding tag
+// can't carry them because the member is a struct
+const TagPatch = "patch"
+
+const (
+	MinPasswordLength = 8
+	E164Pattern       = `^\+[1-9]\d{7,14}$`
+)
+
+var (
+	e164Regex     = regexp.MustCompile(E164Pattern)
+	propertyTypes = []string{constants.House, constants.Apartment, constants.Condo}
+	registerOnce  sync.Once
+)
+
+// SliceError keeps the position of every invalid element of a slice request body
+type SliceError struct {
+	Index int
+	Err   error
+}
+
+type SliceErrors []SliceError
+
+func (e SliceErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, sliceErr := range e {
+		msgs = append(msgs, fmt.Sprintf("[%d]: %v", sliceErr.Index, sliceErr.Err))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Member is a member of a JSON Merge Patch, it tells a member the client left
+// out from one set to null. Valid is false when the value had the wrong type
+type Member interface {
+	Present() bool
+	IsNull() bool
+	Valid() bool
+	Get() any
+}
+
+// FieldError is a merge patch member that failed its rules
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		msgs = append(msgs, fieldErr.Field+": "+fieldErr.Message)
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// structValidator replaces gin's default validator so slice bodies report the
+// index of the failing element and field names follow the request tags
+type structValidator struct {
+	validate *validator.Validate
+}
+
+func (v *structValidator) ValidateStruct(obj any) error {
+	if obj == nil {
+		return nil
+	}
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.Elem().Kind() != reflect.Struct {
+			return v.ValidateStruct(value.Elem().Interface())
+		}
+		return v.validateStruct(obj, value.Elem())
+	case reflect.Struct:
+		return v.validateStruct(obj, value)
+	case reflect.Slice, reflect.Array:
+		var errs SliceErrors
+		for i := 0; i < value.Len(); i++ {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				errs = append(errs, SliceError{Index: i, Err: err})
+			}
+		}
+		if len(errs) == 0 {
+			return nil
+		}
+		return errs
+	}
+	return nil
+}
+
+func (v *structValidator) validateStruct(obj any, value reflect.Value) error {
+	if err := v.validate.Struct(obj); err != nil {
+		return err
+	}
+	return v.validatePatch(value)
+}
+
+// validatePatch checks the members of a merge patch against their patch rules,
+// members left out are skipped and null only fails a required rule
+func (v *structValidator) validatePatch(value reflect.Value) error {
+	var errs FieldErrors
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		rules, ok := field.Tag.Lookup(TagPatch)
+		if !ok || !field.IsExported() {
+			continue
+		}
+		member, ok := value.Field(i).Interface().(Member)
+		if !ok || !member.Present() {
+			continue
+		}
+		if !member.Valid() {
+			errs = append(errs, FieldError{Field: fieldName(field), Message: "must be of type " + reflect.TypeOf(member.Get()).String()})
+			continue
+		}
+		if member.IsNull() {
+			if slices.Contains(strings.Split(rules, ","), "required") {
+				errs = append(errs, FieldError{Field: fieldName(field), Message: "can't be null"})
+			}
+			continue
+		}
+		err := v.validate.Var(member.Get(), rules)
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, fe := range validationErrs {
+			errs = append(errs, FieldError{Field: fieldName(field), Message: Message(fe)})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (v *structValidator) Engine() any {
+	return v.validate
+}
+
+// Register installs the validator with the custom rules as gin's binding validator
+func Register() {
+	registerOnce.Do(func() {
+		validate := validator.New(validator.WithRequiredStructEnabled())
+		validate.SetTagName("binding")
+		validate.RegisterTagNameFunc(fieldName)
+		validate.RegisterValidation(TagEmail, isEmail)
+		validate.RegisterValidation(TagPhone, isE164Phone)
+		validate.RegisterValidation(TagPassword, isStrongPassword)
+		validate.RegisterValidation(TagPropertyType, isPropertyType)
+		validate.RegisterValidation(TagMoney, isMoney)
+		validate.RegisterValidation(TagFuture, isFuture)
+		binding.Validator = &structValidator{validate: validate}
+	})
+}
+
+// fieldName reports fields by the name the client sent them with
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func isEmail(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return false
+	}
+	_, domain, _ := strings.Cut(value, "@")
+	return strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+func isE164Phone(fl validator.FieldLevel) bool {
+	return e164Regex.MatchString(fl.Field().String())
+}
+
+func isStrongPassword(fl validator.FieldLevel) bool {
+	return PasswordStrengthError(fl.Field().String()) == ""
+}
+
+// PasswordStrengthError describes why a password is too weak, empty when it is strong enough
+func PasswordStrengthError(password string) string {
+	if len(password) < MinPasswordLength {
+		return fmt.Sprintf("must be at least %d characters long", MinPasswordLength)
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	if !upper || !lower || !digit || !symbol {
+		return "must contain an upper case letter, a lower case letter, a digit and a symbol"
+	}
+	return ""
+}
+
+// PropertyTypes lists the accepted property types
+func PropertyTypes() []string {
+	return slices.Clone(propertyTypes)
+}
+
+func isPropertyType(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	for _, propertyType := range propertyTypes {
+		if strings.EqualFold(value, propertyType) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMoney(fl validator.FieldLevel) bool {
+	switch fl.Field().Kind() {
+	case reflect.Float32, reflect.Float64:
+		return fl.Field().Float() >= 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fl.Field().Int() >= 0
+	}
+	return false
+}
+
+func isFuture(fl validator.FieldLevel) bool {
+	t, ok := fl.Field().Interface().(time.Time)
+	return ok && t.After(time.Now())
+}
+
+// Message returns a human readable message for a failed rule
+func Message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max", "lte":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", fe.Param())
+	case "unique":
+		if fe.Param() != "" {
+			return fmt.Sprintf("must not repeat a %s", strings.ToLower(fe.Param()))
+		}
+		return "must not repeat a value"
+	case TagEmail:
+		return "must be a valid email address"
+	case TagPhone:
+		return "must be an E.164 phone number, e.g. +919876543210"
+	case TagPassword:
+		if msg := PasswordStrengthError(fmt.Sprint(fe.Value())); msg != "" {
+			return msg
+		}
+		return "is too weak"
+	case TagPropertyType:
+		return fmt.Sprintf("must be one of [%s]", strings.Join(propertyTypes, " "))
+	case TagMoney:
+		return "must not be negative"
+	case TagFuture:
+		return "must be in the future"
+	case "bcp47_language_tag":
+		return "must be a BCP 47 language tag, e.g. en-GB"
+	default:
+		return fmt.Sprintf("failed on '%s' validation", fe.Tag())
+	}
+}
@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+type sample struct {
+	Email    string    `json:"email" binding:"omitempty,email_addr"`
+	Phone    string    `json:"phone" binding:"omitempty,phone_e164"`
+	Password string    `json:"password" binding:"omitempty,strong_password"`
+	Type     string    `json:"property_type" binding:"omitempty,property_type"`
+	Price    float64   `json:"price" binding:"money"`
+	When     time.Time `json:"scheduled_time" binding:"omitempty,future"`
+}
+
+func TestCustomValidators(t *testing.T) {
+	Register()
+	tests := []struct {
+		name      string
+		req       sample
+		wantField string
+		wantTag   string
+	}{
+		{name: "valid", req: sample{Email: "a@b.com", Phone: "+919876543210", Password: "Secr3t!pass", Type: "House", Price: 10, When: time.Now().Add(time.Hour)}},
+		{name: "email without domain", req: sample{Email: "a@b"}, wantField: "email", wantTag: TagEmail},
+		{name: "email with display name", req: sample{Email: "Bob <a@b.com>"}, wantField: "email", wantTag: TagEmail},
+		{name: "phone without plus", req: sample{Phone: "9876543210"}, wantField: "phone", wantTag: TagPhone},
+		{name: "weak password", req: sample{Password: "password1"}, wantField: "password", wantTag: TagPassword},
+		{name: "unknown property type", req: sample{Type: "castle"}, wantField: "property_type", wantTag: TagPropertyType},
+		{name: "negative price", req: sample{Price: -1}, wantField: "price", wantTag: TagMoney},
+		{name: "past visit", req: sample{When: time.Now().Add(-time.Minute)}, wantField: "scheduled_time", wantTag: TagFuture},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(&tt.req)
+			if tt.wantTag == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var errs validator.ValidationErrors
+			if !errors.As(err, &errs) || len(errs) != 1 {
+				t.Fatalf("want one validation error, got %v", err)
+			}
+			if errs[0].Field() != tt.wantField || errs[0].Tag() != tt.wantTag {
+				t.Errorf("got %s/%s, want %s/%s", errs[0].Field(), errs[0].Tag(), tt.wantField, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestSliceErrorsKeepIndex(t *testing.T) {
+	Register()
+	err := binding.Validator.ValidateStruct([]sample{{Price: 1}, {Price: -1}})
+	var sliceErrs SliceErrors
+	if !errors.As(err, &sliceErrs) || len(sliceErrs) != 1 || sliceErrs[0].Index != 1 {
+		t.Fatalf("want error for element 1, got %v", err)
+	}
+}
+
+func TestUniqueByField(t *testing.T) {
+	Register()
+	req := struct {
+		Samples []sample `json:"samples" binding:"required,unique=Type,dive"`
+	}{Samples: []sample{{Type: "House"}, {Type: "Villa"}, {Type: "House"}}}
+	err := binding.Validator.ValidateStruct(&req)
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Tag() != "unique" {
+		t.Fatalf("want one unique error, got %v", err)
+	}
+	if msg := Message(errs[0]); msg != "must not repeat a type" {
+		t.Errorf("message = %q", msg)
+	}
+}
+
+// member is a merge patch member as the request types implement it
+type member[T any] struct {
+	present, null, invalid bool
+	value                  T
+}
+
+func (m member[T]) Present() bool { return m.present }
+func (m member[T]) IsNull() bool  { return m.null }
+func (m member[T]) Valid() bool   { return !m.invalid }
+func (m member[T]) Get() any      { return m.value }
+
+type samplePatch struct {
+	Title    member[string]  `json:"title" patch:"required,max=5"`
+	Bedrooms member[int32]   `json:"bedrooms" patch:"min=0,max=100"`
+	Price    member[float64] `json:"price" patch:"required,money"`
+}
+
+func TestPatchMembers(t *testing.T) {
+	Register()
+	tests := []struct {
+		name      string
+		req       samplePatch
+		wantField string
+		wantMsg   string
+	}{
+		{name: "members left out", req: samplePatch{}},
+		{name: "zero value", req: samplePatch{Bedrooms: member[int32]{present: true}}},
+		{name: "null clears an optional member", req: samplePatch{Bedrooms: member[int32]{present: true, null: true}}},
+		{name: "null on a required member", req: samplePatch{Title: member[string]{present: true, null: true}}, wantField: "title", wantMsg: "can't be null"},
+		{name: "empty required member", req: samplePatch{Title: member[string]{present: true}}, wantField: "title", wantMsg: "is required"},
+		{name: "too long", req: samplePatch{Title: member[string]{present: true, value: "Hill house"}}, wantField: "title", wantMsg: "must be at most 5 characters long"},
+		{name: "negative price", req: samplePatch{Price: member[float64]{present: true, value: -1}}, wantField: "price", wantMsg: "must not be negative"},
+		{name: "wrong type", req: samplePatch{Bedrooms: member[int32]{present: true, invalid: true}}, wantField: "bedrooms", wantMsg: "must be of type int32"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(&tt.req)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var errs FieldErrors
+			if !errors.As(err, &errs) || len(errs) != 1 {
+				t.Fatalf("want one field error, got %v", err)
+			}
+			if errs[0].Field != tt.wantField || errs[0].Message != tt.wantMsg {
+				t.Errorf("got %s %q, want %s %q", errs[0].Field, errs[0].Message, tt.wantField, tt.wantMsg)
+			}
+		})
+	}
+}
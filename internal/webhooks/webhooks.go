@@ -0,0 +1,132 @@
+// Package webhooks signs the events delivered to partner endpoints. A delivery
+// is a POST of a Payload with the headers below, the signature covers the
+// timestamp and the raw body so a captured request can't be replayed later
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"booking.com/internal/events"
+	"booking.com/pkg/constants"
+)
+
+// Headers of a delivery
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	// KindDeliver is the job delivering one event to one webhook
+	KindDeliver = "webhook.deliver"
+	// EventTest is sent by the test endpoint, it is never published on the bus
+	EventTest = "webhook.test"
+
+	signatureVersion = "v1"
+	secretPrefix     = "whsec_"
+)
+
+// EventTypes are the events a partner can subscribe to, all of them concern
+// the listings of the partner and the visits to them
+var EventTypes = []string{
+	events.PropertyListed,
+	events.PropertyPriceDropped,
+	events.VisitScheduled,
+	events.VisitStatusChanged(constants.Accepted),
+	events.VisitStatusChanged(constants.Rejected),
+	events.VisitStatusChanged(constants.Rescheduled),
+	events.VisitStatusChanged(constants.Cancelled),
+	events.VisitStatusChanged(constants.Completed),
+	events.VisitStatusChanged(constants.NoShow),
+}
+
+var (
+	ErrMissingSignature = errors.New("webhook signature or timestamp missing")
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrExpired          = errors.New("webhook timestamp outside the tolerance")
+)
+
+// Payload is the body of a delivery, ID is the outbox event so receivers can
+// drop the duplicates of a retried delivery
+type Payload struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Delivery is the payload of a KindDeliver job
+type Delivery struct {
+	WebhookID int64   `json:"webhook_id"`
+	Event     Payload `json:"event"`
+}
+
+// NewSecret returns a random signing secret
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header of body sent at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return signatureVersion + "=" + hex.EncodeToString(mac(secret, timestamp.Unix(), body))
+}
+
+// Headers returns the headers of a delivery of event with body
+func Headers(secret string, event Payload, timestamp time.Time, body []byte) http.Header {
+	header := http.Header{}
+	header.Set(constants.ContentType, constants.ContentTypeJson)
+	header.Set(HeaderID, strconv.FormatInt(event.ID, 10))
+	header.Set(HeaderEvent, event.Type)
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	header.Set(HeaderSignature, Sign(secret, timestamp, body))
+	return header
+}
+
+// Verify checks the signature of a received delivery, it is what receivers are
+// expected to do and rejects timestamps further than tolerance from now
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	rawTimestamp, signature := header.Get(HeaderTimestamp), header.Get(HeaderSignature)
+	if rawTimestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+	timestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		return ErrMissingSignature
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return ErrExpired
+	}
+	expected := mac(secret, timestamp, body)
+	for _, candidate := range strings.Split(signature, ",") {
+		version, sig, _ := strings.Cut(strings.TrimSpace(candidate), "=")
+		decoded, err := hex.DecodeString(sig)
+		if err == nil && version == signatureVersion && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// mac signs "timestamp.body"
+func mac(secret string, timestamp int64, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
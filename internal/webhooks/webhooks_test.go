@@ -0,0 +1,34 @@
+package webhooks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":1,"type":"visit.accepted"}`)
+	header := Headers("whsec_test", Payload{ID: 1, Type: "visit.accepted"}, now, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		body   []byte
+		now    time.Time
+		want   error
+	}{
+		{name: "valid", secret: "whsec_test", body: body, now: now.Add(time.Minute)},
+		{name: "tampered body", secret: "whsec_test", body: []byte(`{"id":2}`), now: now, want: ErrInvalidSignature},
+		{name: "wrong secret", secret: "whsec_other", body: body, now: now, want: ErrInvalidSignature},
+		{name: "replayed later", secret: "whsec_test", body: body, now: now.Add(10 * time.Minute), want: ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, header, tt.body, 5*time.Minute, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"booking.com/internal/calendar"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/events"
+	"booking.com/internal/jobs"
+	"booking.com/internal/notify"
+	"booking.com/pkg/constants"
+)
+
+// registerCalendar mails an .ics invite to both sides of a visit when it is
+// accepted, rescheduled, cancelled or rejected
+func (w *Worker) registerCalendar() {
+	for _, status := range []string{constants.Accepted, constants.Rescheduled, constants.Cancelled, constants.Rejected} {
+		w.Bus.Subscribe(events.VisitStatusChanged(status), "calendar.invite", w.mailInvites)
+	}
+}
+
+// mailInvites follows the email preference of the visit's notification type,
+// the invite of both sides carries the same UID and sequence
+func (w *Worker) mailInvites(ctx context.Context, event events.Event) error {
+	var visit events.VisitPayload
+	if err := event.Decode(&visit); err != nil {
+		return err
+	}
+	invite, err := w.calendar.Invite(ctx, visit)
+	if err != nil || invite == nil {
+		return err
+	}
+	ics := notify.EmailAttachment{
+		Filename:    "invite.ics",
+		ContentType: invite.Calendar.MediaType(),
+		Content:     invite.Calendar.Bytes(),
+	}
+	data := notify.VisitInviteData{
+		PropertyTitle: invite.Visit.Property.Title,
+		Location:      invite.Calendar.Events[0].Location,
+		Start:         invite.Visit.Start,
+		Updated:       invite.Visit.Sequence > 1,
+		Cancelled:     invite.Calendar.Method == calendar.MethodCancel,
+	}
+	for _, user := range []*model.User{invite.Visit.Partner, invite.Visit.Buyer} {
+		if user.Email == "" {
+			continue
+		}
+		enabled, err := w.notifications.EmailEnabled(user.Username, visitNotifications[visit.Status])
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			continue
+		}
+		data.FirstName = user.FirstName
+		email, err := notify.NewEmail([]string{user.Email}, notify.TemplateVisitInvite, user.Locale, data)
+		if err != nil {
+			return err
+		}
+		email.Attachments = []notify.EmailAttachment{ics}
+		err = jobs.Enqueue(ctx, w.repos.Jobs, notify.KindSendMail, email,
+			jobs.UniqueKey(fmt.Sprintf("invite:%d:%s", event.ID, user.Username)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
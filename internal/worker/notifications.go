@@ -0,0 +1,188 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"booking.com/internal/db/postgresql/dao"
+	"booking.com/internal/events"
+	"booking.com/internal/jobs"
+	"booking.com/internal/notify"
+	"booking.com/internal/svcs"
+	"booking.com/pkg/constants"
+)
+
+// visitNotifications maps the status a visit moved to onto the notification type
+var visitNotifications = map[string]string{
+	constants.Pending:     constants.NotifyVisitRequested,
+	constants.Accepted:    constants.NotifyVisitAccepted,
+	constants.Rejected:    constants.NotifyVisitRejected,
+	constants.Rescheduled: constants.NotifyVisitRescheduled,
+	constants.Cancelled:   constants.NotifyVisitCancelled,
+	constants.Completed:   constants.NotifyVisitCompleted,
+	constants.NoShow:      constants.NotifyVisitNoShow,
+}
+
+// registerNotifications turns events into notifications, the mail and sms
+// themselves are sent by jobs so a failing provider never makes the bus
+// redeliver the event to the other subscribers
+func (w *Worker) registerNotifications(notifier *notify.Notifier, sms notify.SMSSender) {
+	jobs.Handle(w.Runner, notify.KindSendMail, notifier.SendEmail)
+	jobs.Handle(w.Runner, notify.KindSendSMS, sms.Send)
+
+	w.Bus.Subscribe(events.UserRegistered, "mail.registration", w.mailRegistration)
+	w.Bus.Subscribe(events.VisitScheduled, "notify.visit", w.notifyVisit)
+	for status := range visitNotifications {
+		if status != constants.Pending {
+			w.Bus.Subscribe(events.VisitStatusChanged(status), "notify.visit", w.notifyVisit)
+		}
+	}
+	w.Bus.Subscribe(events.PropertyPriceDropped, "notify.price_drop", w.notifyPriceDrop)
+	w.Bus.Subscribe(events.ReviewReceived, "notify.review", w.notifyReview)
+}
+
+// mailRegistration welcomes every new user, it is not subject to preferences
+func (w *Worker) mailRegistration(ctx context.Context, event events.Event) error {
+	var user events.UserPayload
+	if err := event.Decode(&user); err != nil {
+		return err
+	}
+	data := notify.RegistrationData{FirstName: user.FirstName, Username: user.Username}
+	email, err := notify.NewEmail([]string{user.Email}, notify.TemplateRegistration, user.Locale, data)
+	if err != nil {
+		return err
+	}
+	// the key makes a redelivered event enqueue the mail only once
+	return jobs.Enqueue(ctx, w.repos.Jobs, notify.KindSendMail, email,
+		jobs.UniqueKey(fmt.Sprintf("mail:%d:%s", event.ID, user.Username)))
+}
+
+// notifyVisit tells the other side of the visit about the change, the partner
+// hears about new visit requests and the buyer about the partner's answer.
+// Recipients that were deleted or deactivated are skipped
+func (w *Worker) notifyVisit(ctx context.Context, event events.Event) error {
+	var visit events.VisitPayload
+	if err := event.Decode(&visit); err != nil {
+		return err
+	}
+	recipient := visit.PartnerUsername
+	if visit.ChangedBy == visit.PartnerUsername {
+		recipient = visit.BuyerUsername
+	}
+	user, err := w.repos.Users.GetByUserName(ctx, recipient, true)
+	if errors.Is(err, dao.ErrNotFound) {
+		// a deleted or deactivated user is not notified, retrying won't change that
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	property, err := w.repos.Properties.GetByID(ctx, visit.PropertyID, false)
+	if err != nil {
+		return err
+	}
+	notice := svcs.Notice{
+		User:    user,
+		Type:    visitNotifications[visit.Status],
+		Title:   "Visit " + visit.Status,
+		Body:    fmt.Sprintf("%s %s the visit to %s on %s", visit.ChangedBy, visit.Status, property.Title, visit.ScheduledTime.Format("02 Jan 2006 15:04")),
+		Data:    visit,
+		EventID: event.ID,
+
+		Template: notify.TemplateVisitStatus,
+		TemplateData: notify.VisitStatusData{
+			FirstName:      user.FirstName,
+			PropertyTitle:  property.Title,
+			Status:         visit.Status,
+			ChangedBy:      visit.ChangedBy,
+			ScheduledTime:  visit.ScheduledTime,
+			RescheduleTime: visit.RescheduleTime,
+		},
+	}
+	if visit.Status == constants.Pending {
+		notice.Title = "New visit request"
+		notice.Body = fmt.Sprintf("%s requested a visit to %s on %s", visit.BuyerUsername, property.Title, visit.ScheduledTime.Format("02 Jan 2006 15:04"))
+	}
+	return w.notifications.Deliver(ctx, notice)
+}
+
+// notifyPriceDrop tells the active users who saved the property about the new price
+func (w *Worker) notifyPriceDrop(ctx context.Context, event events.Event) error {
+	var property events.PropertyPayload
+	if err := event.Decode(&property); err != nil {
+		return err
+	}
+	userNames, err := w.repos.Properties.FavoritedBy(ctx, property.ID)
+	if err != nil {
+		return err
+	}
+	for _, userName := range userNames {
+		user, err := w.repos.Users.GetByUserName(ctx, userName, true)
+		if errors.Is(err, dao.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		notice := svcs.Notice{
+			User:    user,
+			Type:    constants.NotifyPriceDrop,
+			Title:   "Price drop",
+			Body:    fmt.Sprintf("%s is now listed at %.2f, down from %.2f", property.Title, property.Price, property.PreviousPrice),
+			Data:    property,
+			EventID: event.ID,
+
+			Template: notify.TemplatePriceDrop,
+			TemplateData: notify.PriceDropData{
+				FirstName:     user.FirstName,
+				PropertyTitle: property.Title,
+				City:          property.City,
+				Price:         property.Price,
+				PreviousPrice: property.PreviousPrice,
+			},
+		}
+		if err := w.notifications.Deliver(ctx, notice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyReview tells the partner about a new review of their property unless
+// the partner was deleted or deactivated
+func (w *Worker) notifyReview(ctx context.Context, event events.Event) error {
+	var review events.ReviewPayload
+	if err := event.Decode(&review); err != nil {
+		return err
+	}
+	user, err := w.repos.Users.GetByUserName(ctx, review.PartnerUsername, true)
+	if errors.Is(err, dao.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	property, err := w.repos.Properties.GetByID(ctx, review.PropertyID, false)
+	if err != nil {
+		return err
+	}
+	notice := svcs.Notice{
+		User:    user,
+		Type:    constants.NotifyReviewReceived,
+		Title:   "New review",
+		Body:    fmt.Sprintf("%s rated %s %d out of 5", review.BuyerUsername, property.Title, review.Rating),
+		Data:    review,
+		EventID: event.ID,
+
+		Template: notify.TemplateReviewReceived,
+		TemplateData: notify.ReviewReceivedData{
+			FirstName:     user.FirstName,
+			PropertyTitle: property.Title,
+			Buyer:         review.BuyerUsername,
+			Rating:        review.Rating,
+			ReviewText:    review.ReviewText,
+		},
+	}
+	return w.notifications.Deliver(ctx, notice)
+}
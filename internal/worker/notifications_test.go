@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/repo/memrepo"
+	"booking.com/internal/svcs"
+	"booking.com/pkg/constants"
+)
+
+func TestNotifyVisitSkipsInactiveRecipients(t *testing.T) {
+	tests := []struct {
+		name        string
+		deactivated bool
+		want        int64
+	}{
+		{name: "active buyer", want: 1},
+		{name: "deactivated buyer", deactivated: true, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			cfg := &config.AppConfig{}
+			repos := memrepo.New()
+			w := &Worker{repos: repos, cfg: cfg, notifications: svcs.NewNotificationSvc(cfg, repos)}
+
+			partner := &model.User{FirstName: "Jane", LastName: "Test", Email: "jane@example.com"}
+			buyer := &model.User{FirstName: "John", LastName: "Test", Email: "john@example.com"}
+			for _, user := range []*model.User{partner, buyer} {
+				if err := repos.Users.Create(ctx, user); err != nil {
+					t.Fatal(err)
+				}
+			}
+			property := &model.Property{PartnerUsername: partner.Username, Title: "Hill house", PropertyType: "House", Price: 300}
+			if err := repos.Properties.Create(ctx, property); err != nil {
+				t.Fatal(err)
+			}
+			if tt.deactivated {
+				if err := repos.Users.SetDeleted(ctx, buyer.Username, true); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			payload, err := json.Marshal(events.VisitPayload{
+				ID:              1,
+				PropertyID:      property.ID,
+				PartnerUsername: partner.Username,
+				BuyerUsername:   buyer.Username,
+				Status:          constants.Accepted,
+				ScheduledTime:   time.Now().Add(time.Hour),
+				ChangedBy:       partner.Username,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			event := events.Event{ID: 7, Type: events.VisitStatusChanged(constants.Accepted), Payload: payload}
+			if err := w.notifyVisit(ctx, event); err != nil {
+				t.Fatalf("notifyVisit() error = %v", err)
+			}
+			_, got, err := repos.Notifications.Filter(ctx, buyer.Username, dto.NotificationFilterReq{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("buyer got %d notifications, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"booking.com/internal/events"
+	"booking.com/internal/realtime"
+	"booking.com/pkg/constants"
+)
+
+// registerRealtime pushes visit changes to the streams of both sides of the
+// visit and new reviews to the partner of the reviewed property
+func (w *Worker) registerRealtime() {
+	w.Bus.Subscribe(events.VisitScheduled, "realtime.visit", w.pushVisit)
+	for status := range visitNotifications {
+		if status != constants.Pending {
+			w.Bus.Subscribe(events.VisitStatusChanged(status), "realtime.visit", w.pushVisit)
+		}
+	}
+	w.Bus.Subscribe(events.ReviewReceived, "realtime.review", w.pushReview)
+}
+
+func (w *Worker) pushVisit(ctx context.Context, event events.Event) error {
+	var visit events.VisitPayload
+	if err := event.Decode(&visit); err != nil {
+		return err
+	}
+	for _, user := range []string{visit.PartnerUsername, visit.BuyerUsername} {
+		msg, err := realtime.NewMessage(user, event.Type, visit)
+		if err != nil {
+			return err
+		}
+		// pushes are best effort, a redelivered event would push to both sides again
+		if err := w.publisher.Publish(ctx, msg); err != nil {
+			log.Printf("realtime push of %s to %s failed, error: %v", event.Type, user, err)
+		}
+	}
+	return nil
+}
+
+func (w *Worker) pushReview(ctx context.Context, event events.Event) error {
+	var review events.ReviewPayload
+	if err := event.Decode(&review); err != nil {
+		return err
+	}
+	msg, err := realtime.NewMessage(review.PartnerUsername, event.Type, review)
+	if err != nil {
+		return err
+	}
+	if err := w.publisher.Publish(ctx, msg); err != nil {
+		log.Printf("realtime push of %s to %s failed, error: %v", event.Type, review.PartnerUsername, err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"booking.com/internal/events"
+	"booking.com/internal/realtime"
+)
+
+type recordingPublisher []realtime.Message
+
+func (r *recordingPublisher) Publish(_ context.Context, msg realtime.Message) error {
+	*r = append(*r, msg)
+	return nil
+}
+
+func TestPushReviewReachesPartner(t *testing.T) {
+	published := &recordingPublisher{}
+	w := &Worker{Bus: events.NewBus(), publisher: published}
+	w.registerRealtime()
+
+	payload, err := json.Marshal(events.ReviewPayload{ID: 1, PropertyID: 2, PartnerUsername: "jane", BuyerUsername: "john", Rating: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Bus.Publish(t.Context(), events.Event{ID: 3, Type: events.ReviewReceived, Payload: payload}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if len(*published) != 1 {
+		t.Fatalf("got %d pushes, want 1", len(*published))
+	}
+	if msg := (*published)[0]; msg.User != "jane" || msg.Type != events.ReviewReceived {
+		t.Errorf("pushed %s to %s, want %s to jane", msg.Type, msg.User, events.ReviewReceived)
+	}
+}
@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"booking.com/internal/db/postgresql/model"
+	"booking.com/internal/dto"
+	"booking.com/internal/events"
+	"booking.com/internal/jobs"
+	"booking.com/internal/notify"
+	"booking.com/internal/svcs"
+	"booking.com/pkg/constants"
+)
+
+// registerReminders schedules the reminders of every accepted visit, a
+// visit that changes afterwards leaves its old reminders with nothing to do
+func (w *Worker) registerReminders() {
+	w.Bus.Subscribe(events.VisitStatusChanged(constants.Accepted), "visit.reminders", w.scheduleReminders)
+	w.Runner.Register(svcs.KindVisitReminder, w.remindVisit)
+	w.Runner.Register(svcs.KindVisitFollowUp, w.followUpVisit)
+}
+
+func (w *Worker) scheduleReminders(ctx context.Context, event events.Event) error {
+	var visit events.VisitPayload
+	if err := event.Decode(&visit); err != nil {
+		return err
+	}
+	return w.visits.ScheduleReminders(ctx, visit)
+}
+
+// remindVisit reminds the buyer and the partner, the job id keys the notices
+// so a retried job notifies once
+func (w *Worker) remindVisit(ctx context.Context, job jobs.Job) error {
+	visit, property, partner, buyer, err := w.reminderRows(ctx, job)
+	if err != nil || visit == nil {
+		return err
+	}
+	for _, pair := range [][2]*model.User{{buyer, partner}, {partner, buyer}} {
+		user, counterpart := pair[0], pair[1]
+		notice := svcs.Notice{
+			User:    user,
+			Type:    constants.NotifyVisitReminder,
+			Title:   "Visit reminder",
+			Body:    fmt.Sprintf("Your visit to %s with %s is on %s", property.Title, displayName(counterpart), visit.ScheduledTime.Format("02 Jan 2006 15:04")),
+			Data:    dto.NewVisitRsp(visit),
+			EventID: job.ID,
+
+			Template: notify.TemplateVisitReminder,
+			TemplateData: notify.VisitReminderData{
+				FirstName:     user.FirstName,
+				PropertyTitle: property.Title,
+				Location:      joinNonEmpty(property.Address, property.City, property.State),
+				Counterpart:   displayName(counterpart),
+				Start:         visit.ScheduledTime,
+			},
+		}
+		if err := w.notifications.Deliver(ctx, notice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followUpVisit asks the partner to mark a visit that took place completed or no-show
+func (w *Worker) followUpVisit(ctx context.Context, job jobs.Job) error {
+	visit, property, partner, buyer, err := w.reminderRows(ctx, job)
+	if err != nil || visit == nil {
+		return err
+	}
+	notice := svcs.Notice{
+		User:    partner,
+		Type:    constants.NotifyVisitFollowUp,
+		Title:   "How did the visit go?",
+		Body:    fmt.Sprintf("Mark the visit of %s to %s on %s completed or no-show", displayName(buyer), property.Title, visit.ScheduledTime.Format("02 Jan 2006 15:04")),
+		Data:    dto.NewVisitRsp(visit),
+		EventID: job.ID,
+
+		Template: notify.TemplateVisitReminder,
+		TemplateData: notify.VisitReminderData{
+			FirstName:     partner.FirstName,
+			PropertyTitle: property.Title,
+			Counterpart:   displayName(buyer),
+			Start:         visit.ScheduledTime,
+			FollowUp:      true,
+		},
+	}
+	return w.notifications.Deliver(ctx, notice)
+}
+
+// reminderRows loads what a reminder job needs, visit is nil when the job is stale
+func (w *Worker) reminderRows(ctx context.Context, job jobs.Job) (visit *model.Visit, property *model.Property, partner, buyer *model.User, err error) {
+	var reminder svcs.VisitReminder
+	if err = job.Decode(&reminder); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("decode %s payload: %w", job.Kind, err)
+	}
+	visit, err = w.visits.ReminderVisit(ctx, reminder)
+	if err != nil || visit == nil {
+		return nil, nil, nil, nil, err
+	}
+	if property, err = w.repos.Properties.GetByID(ctx, visit.PropertyID, false); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if partner, err = w.repos.Users.GetByUserName(ctx, property.PartnerUsername, true); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if buyer, err = w.repos.Users.GetByUserName(ctx, visit.BuyerUsername, true); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return visit, property, partner, buyer, nil
+}
+
+func displayName(user *model.User) string {
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// joinNonEmpty joins the parts of an address, skipping blank ones
+func joinNonEmpty(parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
@@ -0,0 +1,13 @@
+package worker
+
+import (
+	"booking.com/internal/events"
+	"booking.com/internal/webhooks"
+)
+
+// registerWebhooks queues a delivery per subscribed webhook for the events of
+// partners, each delivery is a job so a slow endpoint only retries itself
+func (w *Worker) registerWebhooks() {
+	w.Runner.Register(webhooks.KindDeliver, w.webhooks.Deliver)
+	w.Bus.Subscribe(events.All, "webhooks.dispatch", w.webhooks.Dispatch)
+}
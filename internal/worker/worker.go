@@ -0,0 +1,105 @@
+// Package worker wires the outbox dispatcher, the job runner and the cron
+// scheduler, it runs inside the api server or as the standalone worker command
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"booking.com/internal/config"
+	"booking.com/internal/events"
+	"booking.com/internal/jobs"
+	"booking.com/internal/notify"
+	"booking.com/internal/realtime"
+	"booking.com/internal/repo"
+	"booking.com/internal/svcs"
+)
+
+// Job kinds run by every worker
+const (
+	KindPrune = "maintenance.prune"
+)
+
+type Worker struct {
+	Bus           *events.Bus
+	Runner        *jobs.Runner
+	Scheduler     *jobs.Scheduler
+	repos         *repo.Repos
+	cfg           *config.AppConfig
+	notifications *svcs.NotificationSvc
+	webhooks      *svcs.WebhookSvc
+	calendar      *svcs.CalendarSvc
+	visits        *svcs.VisitsSvc
+	publisher     realtime.Publisher
+}
+
+// New builds a worker, publisher carries the realtime pushes to the streams of the api servers
+func New(cfg *config.AppConfig, repos *repo.Repos, publisher realtime.Publisher) (*Worker, error) {
+	mailer, err := notify.NewMailer(context.Background(), cfg.Mail)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := notify.LoadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	w := &Worker{
+		Bus:           events.NewBus(),
+		Runner:        jobs.NewRunner(repos.Jobs, cfg.Jobs),
+		Scheduler:     jobs.NewScheduler(repos.Jobs),
+		repos:         repos,
+		cfg:           cfg,
+		notifications: svcs.NewNotificationSvc(cfg, repos),
+		webhooks:      svcs.NewWebhookSvc(cfg, repos),
+		calendar:      svcs.NewCalendarSvc(cfg, repos),
+		visits:        svcs.NewVisitsSvc(cfg, repos),
+		publisher:     publisher,
+	}
+	w.notifications.Realtime = publisher
+	jobs.Handle(w.Runner, KindPrune, w.prune)
+	w.registerNotifications(notify.NewNotifier(mailer, templates, cfg.Mail.From), notify.NewLocalSMS())
+	w.registerRealtime()
+	w.registerWebhooks()
+	w.registerCalendar()
+	w.registerReminders()
+	if err := w.Scheduler.Add("prune", "0 3 * * *", KindPrune, struct{}{}); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Run blocks until ctx is cancelled and the running jobs finished
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Go(func() { events.NewDispatcher(w.repos.Outbox, w.Bus, w.cfg.Outbox).Run(ctx) })
+	wg.Go(func() { w.Runner.Run(ctx) })
+	wg.Go(func() { w.Scheduler.Run(ctx) })
+	wg.Wait()
+}
+
+// prune deletes finished jobs, delivered events and webhook deliveries older
+// than the retention and the expired idempotency keys
+func (w *Worker) prune(ctx context.Context, _ struct{}) error {
+	before := time.Now().UTC().Add(-w.cfg.Jobs.Retention)
+	nJobs, err := w.repos.Jobs.Prune(ctx, before)
+	if err != nil {
+		return err
+	}
+	nEvents, err := w.repos.Outbox.Prune(ctx, before)
+	if err != nil {
+		return err
+	}
+	nDeliveries, err := w.repos.Webhooks.PruneDeliveries(ctx, before)
+	if err != nil {
+		return err
+	}
+	nKeys, err := w.repos.Idempotency.Prune(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	log.Printf("pruned %d jobs, %d outbox events and %d webhook deliveries finished before %v and %d expired idempotency keys",
+		nJobs, nEvents, nDeliveries, before, nKeys)
+	return nil
+}
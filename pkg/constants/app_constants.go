@@ -4,9 +4,15 @@ const (
 	Https = "https"
 	Http  = "http"
 
-	ContentType          = "Content-Type"
-	ContentTypeJson      = "application/json"
-	ContentTypeTextPlain = "text/plain"
+	ContentType           = "Content-Type"
+	ContentTypeJson       = "application/json"
+	ContentTypeTextPlain  = "text/plain"
+	ContentTypeProblem    = "application/problem+json"
+	ContentTypeMergePatch = "application/merge-patch+json"
+
+	APIv1      = "v1"
+	APIv2      = "v2"
+	APIVersion = "api_version"
 
 	StatusAvailable = "available"
 	StatusBooked    = "booked"
@@ -18,6 +24,15 @@ const (
 	TokenType     = "token_type"
 	Authorization = "Authorization"
 	RefreshToken  = "refresh_token"
+	CsrfToken     = "csrf_token"
+	CsrfHeader    = "X-CSRF-Token"
+
+	IdempotencyKey     = "Idempotency-Key"
+	IdempotentReplayed = "Idempotent-Replayed"
+
+	ETag        = "ETag"
+	IfMatch     = "If-Match"
+	IfNoneMatch = "If-None-Match"
 
 	Success = "success"
 	Failed  = "failed"
@@ -45,4 +60,37 @@ const (
 	Rescheduled = "rescheduled"
 	Completed   = "completed"
 	Cancelled   = "cancelled"
+	NoShow      = "no_show"
+
+	//Outbox event status
+	EventPending   = "pending"
+	EventDelivered = "delivered"
+	EventDead      = "dead"
+
+	//Job status
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobDead      = "dead"
+	JobCancelled = "cancelled"
+
+	//Notification type
+	NotifyVisitRequested   = "visit_requested"
+	NotifyVisitAccepted    = "visit_accepted"
+	NotifyVisitRejected    = "visit_rejected"
+	NotifyVisitRescheduled = "visit_rescheduled"
+	NotifyVisitCancelled   = "visit_cancelled"
+	NotifyVisitCompleted   = "visit_completed"
+	NotifyVisitNoShow      = "visit_no_show"
+	NotifyVisitReminder    = "visit_reminder"
+	NotifyVisitFollowUp    = "visit_follow_up"
+	NotifyReviewReceived   = "review_received"
+	NotifyPriceDrop        = "price_drop"
 )
+
+// NotificationTypes lists every notification type a user can set preferences for
+var NotificationTypes = []string{
+	NotifyVisitRequested, NotifyVisitAccepted, NotifyVisitRejected, NotifyVisitRescheduled,
+	NotifyVisitCancelled, NotifyVisitCompleted, NotifyVisitNoShow, NotifyVisitReminder, NotifyVisitFollowUp,
+	NotifyReviewReceived, NotifyPriceDrop,
+}
@@ -1,14 +1,179 @@
 package customerrors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
-// AppError is a custom error type that includes an HTTP status code
+// Kind classifies an AppError; every kind maps to exactly one HTTP status
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooManyRequests
+	KindUnprocessable
+	KindPreconditionFailed
+	KindPreconditionRequired
+)
+
+// Generic machine-readable codes, domain specific codes live next to the domain errors
+const (
+	CodeInternal       = "internal_error"
+	CodeValidation     = "validation_failed"
+	CodeInvalidRequest = "invalid_request"
+	CodeUnauthorized   = "unauthorized"
+	CodeForbidden      = "forbidden"
+	CodeNotFound       = "not_found"
+	CodeConflict       = "conflict"
+)
+
+// FieldError describes a single invalid field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// AppError is a typed application error carrying a stable machine-readable code
 type AppError struct {
-	Code int
-	Msg  string
+	Kind    Kind
+	Code    string
+	Msg     string
+	Details []FieldError
+	Err     error
 }
 
 // Error implements the built-in error interface
 func (e *AppError) Error() string {
-	return fmt.Sprintf("code %d: %s", e.Code, e.Msg)
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Msg, e.Err)
+	}
+	return e.Msg
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors by code so copies made by WithMsg/Wrap still match their sentinel
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	if !ok {
+		return false
+	}
+	return t.Kind == e.Kind && t.Code == e.Code
+}
+
+// StatusCode returns the HTTP status code for the error kind
+func (e *AppError) StatusCode() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindTooManyRequests:
+		return http.StatusTooManyRequests
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case KindPreconditionRequired:
+		return http.StatusPreconditionRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WithMsg returns a copy of the error with a different message
+func (e *AppError) WithMsg(msg string) *AppError {
+	cp := *e
+	cp.Msg = msg
+	return &cp
+}
+
+// WithDetails returns a copy of the error with field level details
+func (e *AppError) WithDetails(details ...FieldError) *AppError {
+	cp := *e
+	cp.Details = append(append([]FieldError{}, e.Details...), details...)
+	return &cp
+}
+
+// Wrap returns a copy of the error carrying the underlying cause
+func (e *AppError) Wrap(err error) *AppError {
+	cp := *e
+	cp.Err = err
+	return &cp
+}
+
+func New(kind Kind, code, msg string) *AppError {
+	return &AppError{Kind: kind, Code: code, Msg: msg}
+}
+
+func NotFound(code, msg string) *AppError {
+	return New(KindNotFound, code, msg)
+}
+
+func Conflict(code, msg string) *AppError {
+	return New(KindConflict, code, msg)
+}
+
+func Validation(code, msg string, details ...FieldError) *AppError {
+	return &AppError{Kind: KindValidation, Code: code, Msg: msg, Details: details}
+}
+
+func Forbidden(code, msg string) *AppError {
+	return New(KindForbidden, code, msg)
+}
+
+func Unauthorized(code, msg string) *AppError {
+	return New(KindUnauthorized, code, msg)
+}
+
+func TooManyRequests(code, msg string) *AppError {
+	return New(KindTooManyRequests, code, msg)
+}
+
+func Unprocessable(code, msg string) *AppError {
+	return New(KindUnprocessable, code, msg)
+}
+
+func PreconditionFailed(code, msg string) *AppError {
+	return New(KindPreconditionFailed, code, msg)
+}
+
+func PreconditionRequired(code, msg string) *AppError {
+	return New(KindPreconditionRequired, code, msg)
+}
+
+func Internal(err error) *AppError {
+	return &AppError{Kind: KindInternal, Code: CodeInternal, Msg: "internal server error", Err: err}
+}
+
+// From converts any error into an AppError, unknown errors become internal errors
+func From(err error) *AppError {
+	if err == nil {
+		return nil
+	}
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// IsKind reports whether err is an AppError of the given kind
+func IsKind(err error, kind Kind) bool {
+	var appErr *AppError
+	return errors.As(err, &appErr) && appErr.Kind == kind
 }
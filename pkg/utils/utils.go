@@ -10,3 +10,12 @@ func GetExpTime(exp int64) int64 {
 func IsExpired(exp int64) bool {
 	return exp < time.Now().Unix()
 }
+
+// Backoff doubles base for every attempt after the first, capped at max
+func Backoff(base, max time.Duration, attempt int32) time.Duration {
+	delay := base
+	for i := int32(1); i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}